@@ -0,0 +1,303 @@
+// Package ashtakavarga implements the Parashari ashtakavarga: for each planet
+// (and the lagna), the seven planets and the lagna each give a bindu (a
+// benefic point) to some of the signs, counted from where they are in the
+// natal chart. A planet transiting a sign with many bindus in its own
+// ashtakavarga gives good results, and one with few gives bad ones.
+package ashtakavarga
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+)
+
+// Contributors are the points that give bindus, in the order of the tables.
+// They're also the points that get a Bhinnashtakavarga.
+var Contributors = []pointid.PointID{
+	pointid.Sun,
+	pointid.Moon,
+	pointid.Mars,
+	pointid.Mercury,
+	pointid.Jupiter,
+	pointid.Venus,
+	pointid.Saturn,
+	pointid.ASC,
+}
+
+// Planets are the points that have a Bhinnashtakavarga and make up the
+// Sarvashtakavarga. Its capacity is capped so that appending to it can't
+// overwrite the ascendant in Contributors.
+var Planets = Contributors[:7:7]
+
+// binduHouses[p][c] are the houses, counted from contributor c, where c gives
+// a bindu in the ashtakavarga of p (Brihat Parashara Hora Shastra, ch. 66)
+var binduHouses = map[pointid.PointID]map[pointid.PointID][]int{
+	pointid.Sun: {
+		pointid.Sun:     {1, 2, 4, 7, 8, 9, 10, 11},
+		pointid.Moon:    {3, 6, 10, 11},
+		pointid.Mars:    {1, 2, 4, 7, 8, 9, 10, 11},
+		pointid.Mercury: {3, 5, 6, 9, 10, 11, 12},
+		pointid.Jupiter: {5, 6, 9, 11},
+		pointid.Venus:   {6, 7, 12},
+		pointid.Saturn:  {1, 2, 4, 7, 8, 9, 10, 11},
+		pointid.ASC:     {3, 4, 6, 10, 11, 12},
+	},
+	pointid.Moon: {
+		pointid.Sun:     {3, 6, 7, 8, 10, 11},
+		pointid.Moon:    {1, 3, 6, 7, 10, 11},
+		pointid.Mars:    {2, 3, 5, 6, 9, 10, 11},
+		pointid.Mercury: {1, 3, 4, 5, 7, 8, 10, 11},
+		pointid.Jupiter: {1, 4, 7, 8, 10, 11, 12},
+		pointid.Venus:   {3, 4, 5, 7, 9, 10, 11},
+		pointid.Saturn:  {3, 5, 6, 11},
+		pointid.ASC:     {3, 6, 10, 11},
+	},
+	pointid.Mars: {
+		pointid.Sun:     {3, 5, 6, 10, 11},
+		pointid.Moon:    {3, 6, 11},
+		pointid.Mars:    {1, 2, 4, 7, 8, 10, 11},
+		pointid.Mercury: {3, 5, 6, 11},
+		pointid.Jupiter: {6, 10, 11, 12},
+		pointid.Venus:   {6, 8, 11, 12},
+		pointid.Saturn:  {1, 4, 7, 8, 9, 10, 11},
+		pointid.ASC:     {1, 3, 6, 10, 11},
+	},
+	pointid.Mercury: {
+		pointid.Sun:     {5, 6, 9, 11, 12},
+		pointid.Moon:    {2, 4, 6, 8, 10, 11},
+		pointid.Mars:    {1, 2, 4, 7, 8, 9, 10, 11},
+		pointid.Mercury: {1, 3, 5, 6, 9, 10, 11, 12},
+		pointid.Jupiter: {6, 8, 11, 12},
+		pointid.Venus:   {1, 2, 3, 4, 5, 8, 9, 11},
+		pointid.Saturn:  {1, 2, 4, 7, 8, 9, 10, 11},
+		pointid.ASC:     {1, 2, 4, 6, 8, 10, 11},
+	},
+	pointid.Jupiter: {
+		pointid.Sun:     {1, 2, 3, 4, 7, 8, 9, 10, 11},
+		pointid.Moon:    {2, 5, 7, 9, 11},
+		pointid.Mars:    {1, 2, 4, 7, 8, 10, 11},
+		pointid.Mercury: {1, 2, 4, 5, 6, 9, 10, 11},
+		pointid.Jupiter: {1, 2, 3, 4, 7, 8, 10, 11},
+		pointid.Venus:   {2, 5, 6, 9, 10, 11},
+		pointid.Saturn:  {3, 5, 6, 12},
+		pointid.ASC:     {1, 2, 4, 5, 6, 7, 9, 10, 11},
+	},
+	pointid.Venus: {
+		pointid.Sun:     {8, 11, 12},
+		pointid.Moon:    {1, 2, 3, 4, 5, 8, 9, 11, 12},
+		pointid.Mars:    {3, 5, 6, 9, 11, 12},
+		pointid.Mercury: {3, 5, 6, 9, 11},
+		pointid.Jupiter: {5, 8, 9, 10, 11},
+		pointid.Venus:   {1, 2, 3, 4, 5, 8, 9, 10, 11},
+		pointid.Saturn:  {3, 4, 5, 8, 9, 10, 11},
+		pointid.ASC:     {1, 2, 3, 4, 5, 8, 9, 11},
+	},
+	pointid.Saturn: {
+		pointid.Sun:     {1, 2, 4, 7, 8, 10, 11},
+		pointid.Moon:    {3, 6, 11},
+		pointid.Mars:    {3, 5, 6, 10, 11, 12},
+		pointid.Mercury: {6, 8, 9, 10, 11, 12},
+		pointid.Jupiter: {5, 6, 11, 12},
+		pointid.Venus:   {6, 11, 12},
+		pointid.Saturn:  {3, 5, 6, 11},
+		pointid.ASC:     {1, 3, 4, 6, 10, 11},
+	},
+	pointid.ASC: {
+		pointid.Sun:     {3, 4, 6, 10, 11, 12},
+		pointid.Moon:    {3, 6, 10, 11, 12},
+		pointid.Mars:    {1, 3, 6, 10, 11},
+		pointid.Mercury: {1, 2, 4, 6, 8, 10, 11},
+		pointid.Jupiter: {1, 2, 4, 5, 6, 7, 9, 10, 11},
+		pointid.Venus:   {1, 2, 3, 4, 5, 8, 9},
+		pointid.Saturn:  {1, 3, 4, 6, 10, 11},
+		pointid.ASC:     {3, 6, 10, 11},
+	},
+}
+
+// Bindus holds a number of bindus per sign, indexed by sign.Sign.Int()-1
+// (i.e., Aries first)
+type Bindus [12]int
+
+// In returns the bindus in s
+func (b Bindus) In(s sign.Sign) int {
+	return b[s.Int()-1]
+}
+
+func (b Bindus) Total() int {
+	ret := 0
+	for _, n := range b {
+		ret += n
+	}
+	return ret
+}
+
+// Bhinnashtakavarga is the ashtakavarga of a single planet (or the lagna)
+type Bhinnashtakavarga struct {
+	Planet pointid.PointID `json:"planet"`
+	Bindus Bindus          `json:"bindus"`
+	// Contributions are, for each contributor, the signs it gave a bindu
+	// to
+	Contributions map[pointid.PointID][]sign.Sign `json:"contributions"`
+	// TrikonaShodhita and EkadhipatyaShodhita are Bindus after the trikona
+	// reduction, and after both the trikona and the ekadhipatya reductions
+	TrikonaShodhita     Bindus `json:"trikonaShodhita"`
+	EkadhipatyaShodhita Bindus `json:"ekadhipatyaShodhita"`
+}
+
+func (bav *Bhinnashtakavarga) String() string {
+	return fmt.Sprintf(
+		"Bhinnashtakavarga{Planet: %s, Bindus: %v, Total: %d}",
+		bav.Planet,
+		bav.Bindus,
+		bav.Bindus.Total(),
+	)
+}
+
+// HasBindu reports whether contributor gave a bindu to s
+func (bav *Bhinnashtakavarga) HasBindu(contributor pointid.PointID, s sign.Sign) bool {
+	for _, cs := range bav.Contributions[contributor] {
+		if cs == s {
+			return true
+		}
+	}
+	return false
+}
+
+type Ashtakavarga struct {
+	// Bhinnashtakavargas are in the order of Contributors
+	Bhinnashtakavargas []*Bhinnashtakavarga `json:"bhinnashtakavargas"`
+	// Sarva is the Sarvashtakavarga: the sum of the Bhinnashtakavargas of
+	// the seven planets (the lagna's is left out). It always adds up to 337.
+	Sarva Bindus `json:"sarva"`
+	// Ayanamsa is the ayanamsa of the natal chart. Transits are scored in
+	// it.
+	Ayanamsa wrapper.Ayanamsa `json:"ayanamsa"`
+}
+
+func (av *Ashtakavarga) String() string {
+	return fmt.Sprintf("Ashtakavarga{Sarva: %v}", av.Sarva)
+}
+
+// Get returns the Bhinnashtakavarga of pid, or nil if there's none
+func (av *Ashtakavarga) Get(pid pointid.PointID) *Bhinnashtakavarga {
+	for _, bav := range av.Bhinnashtakavargas {
+		if bav.Planet == pid {
+			return bav
+		}
+	}
+	return nil
+}
+
+// New returns the ashtakavarga of d1, which must be a D1 chart with the seven
+// planets
+func New(d1 *chart.Chart) (*Ashtakavarga, error) {
+	if d1.ChartType != chart.D1ChartType {
+		return nil, fmt.Errorf("ashtakavarga needs a D1 chart, got %s", d1.ChartType)
+	}
+	signs := map[pointid.PointID]sign.Sign{}
+	occupied := [12]bool{}
+	for _, pid := range Contributors {
+		p := d1.GetPoint(pid)
+		if p == nil {
+			return nil, fmt.Errorf("%s not found in chart", pid)
+		}
+		signs[pid] = p.ZodiacalPos.Sign
+		if pid != pointid.ASC {
+			occupied[p.ZodiacalPos.Sign.Int()-1] = true
+		}
+	}
+
+	ret := &Ashtakavarga{Ayanamsa: d1.Ayanamsa}
+	for _, pid := range Contributors {
+		bav := &Bhinnashtakavarga{
+			Planet:        pid,
+			Contributions: map[pointid.PointID][]sign.Sign{},
+		}
+		for _, c := range Contributors {
+			bav.Contributions[c] = []sign.Sign{}
+			for _, h := range binduHouses[pid][c] {
+				s, err := sign.NewSignFromInt(signs[c].Int() + h - 1)
+				if err != nil {
+					return nil, fmt.Errorf("while counting %d houses from %s: %v", h, c, err)
+				}
+				bav.Contributions[c] = append(bav.Contributions[c], s)
+				bav.Bindus[s.Int()-1]++
+			}
+		}
+		bav.TrikonaShodhita = trikonaShodhana(bav.Bindus)
+		bav.EkadhipatyaShodhita = ekadhipatyaShodhana(bav.TrikonaShodhita, occupied)
+		ret.Bhinnashtakavargas = append(ret.Bhinnashtakavargas, bav)
+		if pid != pointid.ASC {
+			for i, n := range bav.Bindus {
+				ret.Sarva[i] += n
+			}
+		}
+	}
+	return ret, nil
+}
+
+// trikonaShodhana is the trikona reduction: in each group of signs in trine
+// (e.g., Aries, Leo and Sagittarius), the smallest number of bindus is taken
+// away from all three. If the three are equal, they all go to zero. If one of
+// them is already zero, the group is left alone.
+func trikonaShodhana(b Bindus) Bindus {
+	ret := b
+	for i := 0; i < 4; i++ {
+		group := []int{i, i + 4, i + 8}
+		smallest := math.MaxInt
+		for _, idx := range group {
+			smallest = min(smallest, b[idx])
+		}
+		for _, idx := range group {
+			ret[idx] -= smallest
+		}
+	}
+	return ret
+}
+
+// ekadhipatyaShodhana is the reduction between the two signs ruled by the same
+// planet. The Sun and the Moon rule a single sign, so Leo and Cancer are left
+// alone. occupied tells which signs have planets (the lagna doesn't count).
+func ekadhipatyaShodhana(b Bindus, occupied [12]bool) Bindus {
+	ret := b
+	for _, pair := range [][2]sign.Sign{
+		{sign.Aries, sign.Scorpio},
+		{sign.Taurus, sign.Libra},
+		{sign.Gemini, sign.Virgo},
+		{sign.Sagittarius, sign.Pisces},
+		{sign.Capricorn, sign.Aquarius},
+	} {
+		a, z := pair[0].Int()-1, pair[1].Int()-1
+		switch {
+		case b[a] == 0 || b[z] == 0:
+			// Nothing to reduce
+		case occupied[a] && occupied[z]:
+			// Both occupied: no reduction
+		case !occupied[a] && !occupied[z]:
+			// Neither occupied: both go down to the smaller one, or to
+			// zero if they're equal
+			if b[a] == b[z] {
+				ret[a], ret[z] = 0, 0
+			} else {
+				ret[a], ret[z] = min(b[a], b[z]), min(b[a], b[z])
+			}
+		default:
+			// One occupied: the other one is removed if it has no more
+			// bindus, and brought down to it otherwise
+			occ, free := a, z
+			if occupied[z] {
+				occ, free = z, a
+			}
+			if b[free] <= b[occ] {
+				ret[free] = 0
+			} else {
+				ret[free] = b[occ]
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,160 @@
+package ashtakavarga
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAshtakavarga(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+	d1, err := chart.NewChartFromUTC(
+		swe,
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		// London coordinates
+		-0.1278, 51.5074,
+		chart.D1ChartType,
+		pointid.VedicPlanets,
+	)
+	require.NoError(t, err)
+	av, err := New(d1)
+	require.NoError(t, err)
+	require.Equal(t, d1.Ayanamsa, av.Ayanamsa)
+
+	// The totals don't depend on the chart
+	for pid, total := range map[pointid.PointID]int{
+		pointid.Sun:     48,
+		pointid.Moon:    49,
+		pointid.Mars:    39,
+		pointid.Mercury: 54,
+		pointid.Jupiter: 56,
+		pointid.Venus:   52,
+		pointid.Saturn:  39,
+		pointid.ASC:     49,
+	} {
+		require.Equal(t, total, av.Get(pid).Bindus.Total(), pid)
+	}
+	require.Equal(t, 337, av.Sarva.Total())
+
+	// Aries is the 6th from Mercury and Venus in Scorpio, which are the only
+	// ones to give it a bindu in the Sun's ashtakavarga
+	sun := av.Get(pointid.Sun)
+	require.Equal(t, 2, sun.Bindus.In(sign.Aries))
+	require.True(t, sun.HasBindu(pointid.Mercury, sign.Aries))
+	require.True(t, sun.HasBindu(pointid.Venus, sign.Aries))
+	require.False(t, sun.HasBindu(pointid.Jupiter, sign.Aries))
+	require.Equal(t,
+		Bindus{2, 3, 4, 4, 6, 5, 6, 2, 5, 4, 3, 4},
+		sun.Bindus,
+	)
+	require.Equal(t,
+		Bindus{0, 0, 1, 2, 4, 2, 3, 0, 3, 1, 0, 2},
+		sun.TrikonaShodhita,
+	)
+	// Gemini and Virgo are both empty so they go down to the smaller one,
+	// and Pisces has fewer bindus than the occupied Sagittarius so it goes
+	// to zero
+	require.Equal(t,
+		Bindus{0, 0, 1, 2, 4, 1, 3, 0, 3, 1, 0, 0},
+		sun.EkadhipatyaShodhita,
+	)
+
+	// Only D1 charts
+	d9, err := chart.NewChartFromUTC(
+		swe,
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		-0.1278, 51.5074,
+		chart.D9ChartType,
+		pointid.VedicPlanets,
+	)
+	require.NoError(t, err)
+	_, err = New(d9)
+	require.Error(t, err)
+}
+
+func TestShodhana(t *testing.T) {
+	// Aries/Leo/Sagittarius lose 2, Taurus/Virgo/Capricorn are equal, and
+	// Gemini/Libra/Aquarius and Cancer/Scorpio/Pisces have a zero
+	require.Equal(t,
+		Bindus{1, 0, 0, 4, 2, 0, 5, 0, 0, 0, 3, 3},
+		trikonaShodhana(Bindus{3, 3, 0, 4, 4, 3, 5, 0, 2, 3, 3, 3}),
+	)
+
+	occupied := [12]bool{}
+	occupied[sign.Aries.Int()-1] = true
+	occupied[sign.Libra.Int()-1] = true
+	occupied[sign.Capricorn.Int()-1] = true
+	occupied[sign.Aquarius.Int()-1] = true
+	require.Equal(t,
+		Bindus{
+			// Aries is occupied and has fewer bindus: Scorpio comes down
+			// to it
+			2,
+			// Libra is occupied and has more: Taurus is removed
+			0,
+			// Neither Gemini nor Virgo is occupied and they're equal
+			0,
+			0, 0,
+			0,
+			4,
+			2,
+			// Neither is occupied: they both go down to the smaller one
+			1,
+			// Both are occupied
+			3, 1,
+			1,
+		},
+		ekadhipatyaShodhana(
+			Bindus{2, 3, 2, 0, 0, 2, 4, 5, 1, 3, 1, 4},
+			occupied,
+		),
+	)
+}
+
+func TestScoreTransit(t *testing.T) {
+	av := &Ashtakavarga{
+		Bhinnashtakavargas: []*Bhinnashtakavarga{
+			{
+				Planet: pointid.Saturn,
+				Bindus: Bindus{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 5, 0},
+				Contributions: map[pointid.PointID][]sign.Sign{
+					pointid.Jupiter: {sign.Aquarius},
+				},
+			},
+		},
+		Sarva: Bindus{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 30, 0},
+	}
+	// Aquarius 5°: the second kakshya, Jupiter's
+	score, err := av.ScoreTransit(pointid.Saturn, 305)
+	require.NoError(t, err)
+	require.Equal(t, sign.Aquarius, score.Sign)
+	require.Equal(t, 5, score.Bindus)
+	require.Equal(t, 30, score.SarvaBindus)
+	require.True(t, score.IsFavorable())
+	require.Equal(t, 2, score.Kakshya)
+	require.Equal(t, pointid.Jupiter, score.KakshyaLord)
+	require.True(t, score.KakshyaHasBindu)
+
+	// Aquarius 29°: the lagna's kakshya
+	score, err = av.ScoreTransit(pointid.Saturn, 329)
+	require.NoError(t, err)
+	require.Equal(t, 8, score.Kakshya)
+	require.Equal(t, pointid.ASC, score.KakshyaLord)
+	require.False(t, score.KakshyaHasBindu)
+
+	_, err = av.ScoreTransit(pointid.Moon, 0)
+	require.Error(t, err)
+}
+
+func TestPlanets(t *testing.T) {
+	// Appending to Planets doesn't touch Contributors
+	_ = append(Planets, pointid.Rahu)
+	require.Equal(t, pointid.ASC, Contributors[7])
+	require.NotContains(t, Planets, pointid.ASC)
+}
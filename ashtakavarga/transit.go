@@ -0,0 +1,92 @@
+package ashtakavarga
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+)
+
+// KakshyaSpan is the size of a kakshya (an eighth of a sign), in degrees
+const KakshyaSpan = 30.0 / 8
+
+// kakshyaLords rule the eight kakshyas of every sign, in order
+var kakshyaLords = []pointid.PointID{
+	pointid.Saturn,
+	pointid.Jupiter,
+	pointid.Mars,
+	pointid.Sun,
+	pointid.Venus,
+	pointid.Mercury,
+	pointid.Moon,
+	pointid.ASC,
+}
+
+// FavorableBindus is the number of bindus from which a transit through a sign
+// gives good results
+const FavorableBindus = 4
+
+// TransitScore is how a planet transiting a sign fares against the natal
+// ashtakavarga
+type TransitScore struct {
+	Planet pointid.PointID `json:"planet"`
+	// SiderealLongitude is where the planet is transiting
+	SiderealLongitude float64   `json:"siderealLongitude"`
+	Sign              sign.Sign `json:"sign"`
+	// Bindus are the bindus of the sign in the planet's own
+	// Bhinnashtakavarga, from 0 to 8
+	Bindus int `json:"bindus"`
+	// SarvaBindus are the bindus of the sign in the Sarvashtakavarga
+	SarvaBindus int `json:"sarvaBindus"`
+	// Kakshya is the eighth of the sign the planet is in, from 1 to 8.
+	// The planet gives results while in the kakshya of a contributor that
+	// gave the sign a bindu.
+	Kakshya         int             `json:"kakshya"`
+	KakshyaLord     pointid.PointID `json:"kakshyaLord"`
+	KakshyaHasBindu bool            `json:"kakshyaHasBindu"`
+}
+
+func (ts *TransitScore) String() string {
+	return fmt.Sprintf(
+		"TransitScore{Planet: %s, Sign: %s, Bindus: %d, SarvaBindus: %d, Kakshya: %d (%s)}",
+		ts.Planet,
+		ts.Sign,
+		ts.Bindus,
+		ts.SarvaBindus,
+		ts.Kakshya,
+		ts.KakshyaLord,
+	)
+}
+
+// IsFavorable reports whether the sign has at least FavorableBindus in the
+// planet's Bhinnashtakavarga
+func (ts *TransitScore) IsFavorable() bool {
+	return ts.Bindus >= FavorableBindus
+}
+
+// ScoreTransit scores pid transiting at the sidereal longitude lon, in
+// av.Ayanamsa. pid must be one of Planets.
+func (av *Ashtakavarga) ScoreTransit(
+	pid pointid.PointID,
+	lon float64,
+) (*TransitScore, error) {
+	bav := av.Get(pid)
+	if bav == nil || pid == pointid.ASC {
+		return nil, fmt.Errorf("no ashtakavarga for %s", pid)
+	}
+	lon = math.Mod(lon+360, 360)
+	s := sign.DegreeToSign(lon)
+	kakshya := int(math.Mod(lon, 30) / KakshyaSpan)
+	lord := kakshyaLords[kakshya]
+	return &TransitScore{
+		Planet:            pid,
+		SiderealLongitude: lon,
+		Sign:              s,
+		Bindus:            bav.Bindus.In(s),
+		SarvaBindus:       av.Sarva.In(s),
+		Kakshya:           kakshya + 1,
+		KakshyaLord:       lord,
+		KakshyaHasBindu:   bav.HasBindu(lord, s),
+	}, nil
+}
@@ -0,0 +1,216 @@
+// Package ashtakoota matches two charts with Ashtakoota (Guna Milan), the
+// eight "kootas" of compatibility scored from the Moon of the bride and the
+// groom, for a total of 36 points.
+package ashtakoota
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+)
+
+type KootaType string
+
+const (
+	KootaType_Varna       KootaType = "varna"
+	KootaType_Vashya      KootaType = "vashya"
+	KootaType_Tara        KootaType = "tara"
+	KootaType_Yoni        KootaType = "yoni"
+	KootaType_GrahaMaitri KootaType = "graha-maitri"
+	KootaType_Gana        KootaType = "gana"
+	KootaType_Bhakoot     KootaType = "bhakoot"
+	KootaType_Nadi        KootaType = "nadi"
+)
+
+func (kt KootaType) String() string {
+	return string(kt)
+}
+
+// MaxPoints is the total of the eight kootas
+const MaxPoints = 36.0
+
+// MinimumPoints is the traditional threshold for a match to be acceptable
+const MinimumPoints = 18.0
+
+// Moon is what Ashtakoota is computed from: the sidereal position of the Moon
+type Moon struct {
+	SiderealLongitude float64             `json:"siderealLongitude"`
+	Sign              sign.Sign           `json:"sign"`
+	Nakshatra         chart.NakshatraType `json:"nakshatra"`
+	// Pada is from 1 to 4
+	Pada int `json:"pada"`
+}
+
+func (m *Moon) String() string {
+	return fmt.Sprintf(
+		"Moon{Sign: %s, Nakshatra: %s, Pada: %d}",
+		m.Sign,
+		m.Nakshatra,
+		m.Pada,
+	)
+}
+
+// NewMoonFromSiderealLongitude returns the Moon at the sidereal longitude lon
+func NewMoonFromSiderealLongitude(lon float64) (*Moon, error) {
+	nd, err := chart.NewNakshatraDetails(pointid.Moon, lon)
+	if err != nil {
+		return nil, err
+	}
+	return newMoon(nd), nil
+}
+
+// NewMoon returns the Moon of c, which can be of any chart type
+func NewMoon(swe *wrapper.SwissEph, c *chart.Chart) (*Moon, error) {
+	nd, err := c.GetNakshatraDetails(swe, pointid.Moon)
+	if err != nil {
+		return nil, fmt.Errorf("while getting nakshatra of the moon: %v", err)
+	}
+	return newMoon(nd), nil
+}
+
+func newMoon(nd *chart.NakshatraDetails) *Moon {
+	lon := math.Mod(nd.SiderealLongitude+360, 360)
+	return &Moon{
+		SiderealLongitude: lon,
+		Sign:              sign.DegreeToSign(lon),
+		Nakshatra:         nd.Nakshatra,
+		Pada:              nd.Pada,
+	}
+}
+
+// Koota is the score of one koota
+type Koota struct {
+	Type KootaType `json:"type"`
+	// Points are the points given to the koota, after cancellations
+	Points float64 `json:"points"`
+	// TablePoints are the points of the koota before cancellations
+	TablePoints float64 `json:"tablePoints"`
+	MaxPoints   float64 `json:"maxPoints"`
+	// Bride and Groom are the attributes the koota compares (e.g., their
+	// varna)
+	Bride string `json:"bride"`
+	Groom string `json:"groom"`
+	// Explanation is how the points were given
+	Explanation string `json:"explanation"`
+	// IsDosha is true for the kootas whose lack of points is a dosha
+	// (Gana, Bhakoot and Nadi), when they got none
+	IsDosha bool `json:"isDosha"`
+	// Cancellation is the rule that cancelled the dosha, if any. A cancelled
+	// dosha gets MaxPoints.
+	Cancellation string `json:"cancellation"`
+}
+
+func (k *Koota) String() string {
+	return fmt.Sprintf(
+		"Koota{Type: %s, Points: %g/%g, Bride: %s, Groom: %s, Explanation: %s}",
+		k.Type,
+		k.Points,
+		k.MaxPoints,
+		k.Bride,
+		k.Groom,
+		k.Explanation,
+	)
+}
+
+func (k *Koota) IsCancelled() bool {
+	return k.Cancellation != ""
+}
+
+// cancel cancels the dosha of k because of rule
+func (k *Koota) cancel(rule string) {
+	k.Cancellation = rule
+	k.Points = k.MaxPoints
+}
+
+// Result is the Ashtakoota match of a bride and a groom
+type Result struct {
+	Bride *Moon `json:"bride"`
+	Groom *Moon `json:"groom"`
+	// Kootas are in the traditional order, from Varna to Nadi
+	Kootas []*Koota `json:"kootas"`
+}
+
+func (r *Result) String() string {
+	kootas := []string{}
+	for _, k := range r.Kootas {
+		kootas = append(kootas, fmt.Sprintf("%s: %g", k.Type, k.Points))
+	}
+	return fmt.Sprintf(
+		"Result{Total: %g/%g, Kootas: [%s]}",
+		r.Total(),
+		MaxPoints,
+		strings.Join(kootas, ", "),
+	)
+}
+
+// Total returns the points of all kootas, out of MaxPoints
+func (r *Result) Total() float64 {
+	ret := 0.0
+	for _, k := range r.Kootas {
+		ret += k.Points
+	}
+	return ret
+}
+
+// Get returns the koota of type kt
+func (r *Result) Get(kt KootaType) *Koota {
+	for _, k := range r.Kootas {
+		if k.Type == kt {
+			return k
+		}
+	}
+	return nil
+}
+
+// Doshas returns the kootas with a dosha that wasn't cancelled
+func (r *Result) Doshas() []*Koota {
+	ret := []*Koota{}
+	for _, k := range r.Kootas {
+		if k.IsDosha && !k.IsCancelled() {
+			ret = append(ret, k)
+		}
+	}
+	return ret
+}
+
+// IsAcceptable reports whether the match has at least MinimumPoints
+func (r *Result) IsAcceptable() bool {
+	return r.Total() >= MinimumPoints
+}
+
+// Match matches the Moons of the charts of bride and groom
+func Match(swe *wrapper.SwissEph, bride, groom *chart.Chart) (*Result, error) {
+	b, err := NewMoon(swe, bride)
+	if err != nil {
+		return nil, fmt.Errorf("while getting moon of the bride: %v", err)
+	}
+	g, err := NewMoon(swe, groom)
+	if err != nil {
+		return nil, fmt.Errorf("while getting moon of the groom: %v", err)
+	}
+	return MatchMoons(b, g), nil
+}
+
+// MatchMoons scores the eight kootas of bride and groom
+func MatchMoons(bride, groom *Moon) *Result {
+	bhakoot := bhakootKoota(bride, groom)
+	return &Result{
+		Bride: bride,
+		Groom: groom,
+		Kootas: []*Koota{
+			varnaKoota(bride, groom),
+			vashyaKoota(bride, groom),
+			taraKoota(bride, groom),
+			yoniKoota(bride, groom),
+			grahaMaitriKoota(bride, groom),
+			ganaKoota(bride, groom, bhakoot),
+			bhakoot,
+			nadiKoota(bride, groom),
+		},
+	}
+}
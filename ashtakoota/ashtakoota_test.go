@@ -0,0 +1,176 @@
+package ashtakoota
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func newMoonAt(t *testing.T, lon float64) *Moon {
+	m, err := NewMoonFromSiderealLongitude(lon)
+	require.NoError(t, err)
+	return m
+}
+
+func kootaPoints(r *Result) map[KootaType]float64 {
+	ret := map[KootaType]float64{}
+	for _, k := range r.Kootas {
+		ret[k.Type] = k.Points
+	}
+	return ret
+}
+
+func TestMatch(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+
+	// Moon in Leo, in Magha
+	leo, err := chart.NewChartFromUTC(
+		swe,
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		-0.1278, 51.5074,
+		chart.D1ChartType,
+		pointid.VedicPlanets,
+	)
+	require.NoError(t, err)
+	// Moon in Scorpio, in Anuradha
+	scorpio, err := chart.NewChartFromUTC(
+		swe,
+		time.Date(1992, 6, 13, 4, 40, 0, 0, time.UTC),
+		36.3, 33.5,
+		chart.D1ChartType,
+		pointid.VedicPlanets,
+	)
+	require.NoError(t, err)
+
+	r, err := Match(swe, leo, scorpio)
+	require.NoError(t, err)
+	require.Equal(t, sign.Leo, r.Bride.Sign)
+	require.Equal(t, chart.Magha, r.Bride.Nakshatra)
+	require.Equal(t, 4, r.Bride.Pada)
+	require.Equal(t, sign.Scorpio, r.Groom.Sign)
+	require.Equal(t, chart.Anuradha, r.Groom.Nakshatra)
+	require.Equal(t, 1, r.Groom.Pada)
+	require.Len(t, r.Kootas, 8)
+	require.Equal(t, map[KootaType]float64{
+		KootaType_Varna:       1,
+		KootaType_Vashya:      0,
+		KootaType_Tara:        1.5,
+		KootaType_Yoni:        2,
+		KootaType_GrahaMaitri: 5,
+		KootaType_Gana:        6,
+		KootaType_Bhakoot:     7,
+		KootaType_Nadi:        8,
+	}, kootaPoints(r))
+	require.Equal(t, 30.5, r.Total())
+	require.True(t, r.IsAcceptable())
+	// A rakshasa bride with a deva groom is a gana dosha, but the Sun and
+	// Mars are friends
+	gana := r.Get(KootaType_Gana)
+	require.True(t, gana.IsDosha)
+	require.True(t, gana.IsCancelled())
+	require.Equal(t, 0.0, gana.TablePoints)
+	require.Empty(t, r.Doshas())
+
+	// Swapping the bride and the groom changes the varna and the gana
+	r, err = Match(swe, scorpio, leo)
+	require.NoError(t, err)
+	require.Equal(t, 0.0, r.Get(KootaType_Varna).Points)
+	gana = r.Get(KootaType_Gana)
+	require.Equal(t, 1.0, gana.Points)
+	require.False(t, gana.IsDosha)
+	require.Equal(t, 24.5, r.Total())
+}
+
+func TestMatchMoons(t *testing.T) {
+	t.Run("same moon", func(t *testing.T) {
+		// Aswini, pada 1
+		m := newMoonAt(t, 0.5)
+		r := MatchMoons(m, m)
+		require.Equal(t, 28.0, r.Total())
+		nadi := r.Get(KootaType_Nadi)
+		require.True(t, nadi.IsDosha)
+		require.False(t, nadi.IsCancelled())
+		require.Equal(t, 0.0, nadi.Points)
+		require.Equal(t, []*Koota{nadi}, r.Doshas())
+	})
+
+	t.Run("nadi dosha cancelled by padas", func(t *testing.T) {
+		// Aswini, padas 1 and 3
+		r := MatchMoons(newMoonAt(t, 0.5), newMoonAt(t, 7.5))
+		nadi := r.Get(KootaType_Nadi)
+		require.True(t, nadi.IsDosha)
+		require.Equal(t, "same nakshatra but different padas", nadi.Cancellation)
+		require.Equal(t, 8.0, nadi.Points)
+	})
+
+	t.Run("nadi dosha cancelled by nakshatras", func(t *testing.T) {
+		// Aswini and Ardra are both adi, but only Aswini is in Aries
+		r := MatchMoons(newMoonAt(t, 0.5), newMoonAt(t, 70))
+		require.False(t, r.Get(KootaType_Nadi).IsCancelled())
+		// Bharani and Krittika (pada 1) in Aries: madhya and antya
+		r = MatchMoons(newMoonAt(t, 15), newMoonAt(t, 27))
+		require.False(t, r.Get(KootaType_Nadi).IsDosha)
+		// Rohini and Krittika (pada 2) in Taurus: both antya
+		r = MatchMoons(newMoonAt(t, 45), newMoonAt(t, 32))
+		require.Equal(t, "same moon sign but different nakshatras", r.Get(KootaType_Nadi).Cancellation)
+	})
+
+	t.Run("bhakoot", func(t *testing.T) {
+		// Aries and Virgo are 6/8: Mars and Mercury are not friends
+		r := MatchMoons(newMoonAt(t, 0.5), newMoonAt(t, 155))
+		bhakoot := r.Get(KootaType_Bhakoot)
+		require.True(t, bhakoot.IsDosha)
+		require.False(t, bhakoot.IsCancelled())
+		require.Equal(t, "the signs are 6/8 from each other", bhakoot.Explanation)
+		require.Equal(t, 0.0, bhakoot.Points)
+
+		// Aries and Sagittarius are 5/9, but Mars and Jupiter are friends
+		r = MatchMoons(newMoonAt(t, 0.5), newMoonAt(t, 245))
+		bhakoot = r.Get(KootaType_Bhakoot)
+		require.True(t, bhakoot.IsDosha)
+		require.True(t, bhakoot.IsCancelled())
+		require.Equal(t, 7.0, bhakoot.Points)
+	})
+}
+
+func TestTables(t *testing.T) {
+	for i := range yoniPoints {
+		require.Len(t, yoniPoints[i], len(yoniOrder))
+		require.Equal(t, 4.0, yoniPoints[i][i])
+		for j := range yoniPoints[i] {
+			require.Equal(t, yoniPoints[i][j], yoniPoints[j][i], "%s/%s", yoniOrder[i], yoniOrder[j])
+		}
+	}
+	require.Len(t, yonis, 27)
+
+	nadis := map[Nadi][]chart.NakshatraType{}
+	for i := 0; i < 27; i++ {
+		nak, err := chart.NewNakshatraTypeFromInt(i)
+		require.NoError(t, err)
+		nadis[NadiOf(nak)] = append(nadis[NadiOf(nak)], nak)
+	}
+	require.Equal(t, []chart.NakshatraType{
+		chart.Aswini,
+		chart.Ardra,
+		chart.Punarvasu,
+		chart.UttaraPhalguni,
+		chart.Hasta,
+		chart.Jyeshtha,
+		chart.Mula,
+		chart.Shatabhisha,
+		chart.PurvaBhadrapada,
+	}, nadis[Nadi_Adi])
+	require.Len(t, nadis[Nadi_Madhya], 9)
+	require.Len(t, nadis[Nadi_Antya], 9)
+
+	require.Equal(t, Vashya_Manava, VashyaOf(245))
+	require.Equal(t, Vashya_Chatushpada, VashyaOf(255))
+	require.Equal(t, Vashya_Chatushpada, VashyaOf(275))
+	require.Equal(t, Vashya_Jalachara, VashyaOf(285))
+}
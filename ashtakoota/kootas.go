@@ -0,0 +1,499 @@
+package ashtakoota
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/sign"
+)
+
+// Varna is the caste of a Moon sign, from the lowest to the highest
+type Varna int
+
+const (
+	Varna_Shudra Varna = iota + 1
+	Varna_Vaishya
+	Varna_Kshatriya
+	Varna_Brahmin
+)
+
+func (v Varna) String() string {
+	switch v {
+	case Varna_Shudra:
+		return "shudra"
+	case Varna_Vaishya:
+		return "vaishya"
+	case Varna_Kshatriya:
+		return "kshatriya"
+	case Varna_Brahmin:
+		return "brahmin"
+	}
+	return "unknown"
+}
+
+// VarnaOf returns the varna of s: the watery signs are Brahmin, the fiery ones
+// Kshatriya, the earthy ones Vaishya and the airy ones Shudra
+func VarnaOf(s sign.Sign) Varna {
+	switch s {
+	case sign.Cancer, sign.Scorpio, sign.Pisces:
+		return Varna_Brahmin
+	case sign.Aries, sign.Leo, sign.Sagittarius:
+		return Varna_Kshatriya
+	case sign.Taurus, sign.Virgo, sign.Capricorn:
+		return Varna_Vaishya
+	}
+	return Varna_Shudra
+}
+
+func varnaKoota(bride, groom *Moon) *Koota {
+	b, g := VarnaOf(bride.Sign), VarnaOf(groom.Sign)
+	ret := &Koota{
+		Type:      KootaType_Varna,
+		MaxPoints: 1,
+		Bride:     b.String(),
+		Groom:     g.String(),
+	}
+	if g >= b {
+		ret.TablePoints = 1
+		ret.Explanation = "the varna of the groom is not lower than the bride's"
+	} else {
+		ret.Explanation = "the varna of the groom is lower than the bride's"
+	}
+	ret.Points = ret.TablePoints
+	return ret
+}
+
+type Vashya string
+
+const (
+	Vashya_Chatushpada Vashya = "chatushpada" // quadrupeds
+	Vashya_Manava      Vashya = "manava"      // humans
+	Vashya_Jalachara   Vashya = "jalachara"   // water creatures
+	Vashya_Vanachara   Vashya = "vanachara"   // wild animals
+	Vashya_Keeta       Vashya = "keeta"       // insects
+)
+
+func (v Vashya) String() string {
+	return string(v)
+}
+
+// VashyaOf returns the vashya of the sidereal longitude lon. Sagittarius and
+// Capricorn change vashya halfway through.
+func VashyaOf(lon float64) Vashya {
+	lon = math.Mod(lon+360, 360)
+	firstHalf := math.Mod(lon, 30) < 15
+	switch sign.DegreeToSign(lon) {
+	case sign.Aries, sign.Taurus:
+		return Vashya_Chatushpada
+	case sign.Gemini, sign.Virgo, sign.Libra, sign.Aquarius:
+		return Vashya_Manava
+	case sign.Cancer, sign.Pisces:
+		return Vashya_Jalachara
+	case sign.Leo:
+		return Vashya_Vanachara
+	case sign.Scorpio:
+		return Vashya_Keeta
+	case sign.Sagittarius:
+		if firstHalf {
+			return Vashya_Manava
+		}
+		return Vashya_Chatushpada
+	case sign.Capricorn:
+		if firstHalf {
+			return Vashya_Chatushpada
+		}
+		return Vashya_Jalachara
+	}
+	panic(fmt.Sprintf("invalid longitude %f", lon))
+}
+
+// vashyaPoints are the points of every pair of vashyas
+var vashyaPoints = map[Vashya]map[Vashya]float64{
+	Vashya_Chatushpada: {Vashya_Chatushpada: 2, Vashya_Manava: 1, Vashya_Jalachara: 1, Vashya_Vanachara: 0.5, Vashya_Keeta: 1},
+	Vashya_Manava:      {Vashya_Chatushpada: 1, Vashya_Manava: 2, Vashya_Jalachara: 0.5, Vashya_Vanachara: 0, Vashya_Keeta: 1},
+	Vashya_Jalachara:   {Vashya_Chatushpada: 1, Vashya_Manava: 0.5, Vashya_Jalachara: 2, Vashya_Vanachara: 1, Vashya_Keeta: 1},
+	Vashya_Vanachara:   {Vashya_Chatushpada: 0.5, Vashya_Manava: 0, Vashya_Jalachara: 1, Vashya_Vanachara: 2, Vashya_Keeta: 0},
+	Vashya_Keeta:       {Vashya_Chatushpada: 1, Vashya_Manava: 1, Vashya_Jalachara: 1, Vashya_Vanachara: 0, Vashya_Keeta: 2},
+}
+
+func vashyaKoota(bride, groom *Moon) *Koota {
+	b, g := VashyaOf(bride.SiderealLongitude), VashyaOf(groom.SiderealLongitude)
+	points := vashyaPoints[b][g]
+	explanation := fmt.Sprintf("%s and %s", b, g)
+	switch {
+	case b == g:
+		explanation = "same vashya"
+	case points == 0:
+		explanation += " are hostile"
+	case points < 1:
+		explanation += " are unfriendly"
+	default:
+		explanation += " are friendly"
+	}
+	return &Koota{
+		Type:        KootaType_Vashya,
+		Points:      points,
+		TablePoints: points,
+		MaxPoints:   2,
+		Bride:       b.String(),
+		Groom:       g.String(),
+		Explanation: explanation,
+	}
+}
+
+// isTaraFavorable reports whether the tara counted in one direction gives
+// points. Only Vipat, Pratyak and Naidhana (the 3rd, 5th and 7th) don't.
+func isTaraFavorable(t chart.Tara) bool {
+	switch t {
+	case chart.Tara_Vipat, chart.Tara_Pratyak, chart.Tara_Naidhana:
+		return false
+	}
+	return true
+}
+
+func taraKoota(bride, groom *Moon) *Koota {
+	// The tara of the groom's nakshatra counted from the bride's, and the
+	// other way around
+	fromBride := chart.TaraBala(bride.Nakshatra, groom.Nakshatra)
+	fromGroom := chart.TaraBala(groom.Nakshatra, bride.Nakshatra)
+	points := 0.0
+	for _, t := range []chart.Tara{fromBride, fromGroom} {
+		if isTaraFavorable(t) {
+			points += 1.5
+		}
+	}
+	return &Koota{
+		Type:        KootaType_Tara,
+		Points:      points,
+		TablePoints: points,
+		MaxPoints:   3,
+		Bride:       bride.Nakshatra.String(),
+		Groom:       groom.Nakshatra.String(),
+		Explanation: fmt.Sprintf(
+			"%s tara from the bride, %s tara from the groom",
+			fromBride,
+			fromGroom,
+		),
+	}
+}
+
+type Yoni string
+
+const (
+	Yoni_Horse    Yoni = "horse"
+	Yoni_Elephant Yoni = "elephant"
+	Yoni_Sheep    Yoni = "sheep"
+	Yoni_Serpent  Yoni = "serpent"
+	Yoni_Dog      Yoni = "dog"
+	Yoni_Cat      Yoni = "cat"
+	Yoni_Rat      Yoni = "rat"
+	Yoni_Cow      Yoni = "cow"
+	Yoni_Buffalo  Yoni = "buffalo"
+	Yoni_Tiger    Yoni = "tiger"
+	Yoni_Deer     Yoni = "deer"
+	Yoni_Monkey   Yoni = "monkey"
+	Yoni_Mongoose Yoni = "mongoose"
+	Yoni_Lion     Yoni = "lion"
+)
+
+func (y Yoni) String() string {
+	return string(y)
+}
+
+// yonis are indexed by chart.NakshatraType
+var yonis = []Yoni{
+	Yoni_Horse,    // Aswini
+	Yoni_Elephant, // Bharani
+	Yoni_Sheep,    // Krittika
+	Yoni_Serpent,  // Rohini
+	Yoni_Serpent,  // Mrigashirsha
+	Yoni_Dog,      // Ardra
+	Yoni_Cat,      // Punarvasu
+	Yoni_Sheep,    // Pushya
+	Yoni_Cat,      // Ashlesha
+	Yoni_Rat,      // Magha
+	Yoni_Rat,      // PurvaPhalguni
+	Yoni_Cow,      // UttaraPhalguni
+	Yoni_Buffalo,  // Hasta
+	Yoni_Tiger,    // Chitra
+	Yoni_Buffalo,  // Swati
+	Yoni_Tiger,    // Vishakha
+	Yoni_Deer,     // Anuradha
+	Yoni_Deer,     // Jyeshtha
+	Yoni_Dog,      // Mula
+	Yoni_Monkey,   // PurvaAshadha
+	Yoni_Mongoose, // UttaraAshadha
+	Yoni_Monkey,   // Shravana
+	Yoni_Lion,     // Dhanishta
+	Yoni_Horse,    // Shatabhisha
+	Yoni_Lion,     // PurvaBhadrapada
+	Yoni_Cow,      // UttaraBhadrapada
+	Yoni_Elephant, // Revati
+}
+
+func YoniOf(nak chart.NakshatraType) Yoni {
+	return yonis[nak]
+}
+
+// yoniOrder is the order of the rows and columns of yoniPoints
+var yoniOrder = []Yoni{
+	Yoni_Horse,
+	Yoni_Elephant,
+	Yoni_Sheep,
+	Yoni_Serpent,
+	Yoni_Dog,
+	Yoni_Cat,
+	Yoni_Rat,
+	Yoni_Cow,
+	Yoni_Buffalo,
+	Yoni_Tiger,
+	Yoni_Deer,
+	Yoni_Monkey,
+	Yoni_Mongoose,
+	Yoni_Lion,
+}
+
+// yoniPoints are the points of every pair of yonis, in the order of
+// yoniOrder. Sworn enemies (horse and buffalo, elephant and lion, sheep and
+// monkey, serpent and mongoose, dog and deer, cat and rat, cow and tiger) get
+// none.
+var yoniPoints = [][]float64{
+	{4, 2, 2, 3, 2, 2, 2, 1, 0, 1, 3, 3, 2, 1},
+	{2, 4, 3, 3, 2, 2, 2, 2, 3, 1, 2, 3, 2, 0},
+	{2, 3, 4, 2, 1, 2, 1, 3, 3, 1, 2, 0, 3, 1},
+	{3, 3, 2, 4, 2, 1, 1, 1, 1, 2, 2, 2, 0, 2},
+	{2, 2, 1, 2, 4, 2, 1, 2, 2, 1, 0, 2, 1, 1},
+	{2, 2, 2, 1, 2, 4, 0, 2, 2, 1, 3, 3, 2, 1},
+	{2, 2, 1, 1, 1, 0, 4, 2, 2, 2, 2, 2, 1, 2},
+	{1, 2, 3, 1, 2, 2, 2, 4, 3, 0, 3, 2, 2, 1},
+	{0, 3, 3, 1, 2, 2, 2, 3, 4, 1, 2, 2, 2, 1},
+	{1, 1, 1, 2, 1, 1, 2, 0, 1, 4, 1, 1, 2, 1},
+	{3, 2, 2, 2, 0, 3, 2, 3, 2, 1, 4, 2, 2, 1},
+	{3, 3, 0, 2, 2, 3, 2, 2, 2, 1, 2, 4, 3, 2},
+	{2, 2, 3, 0, 1, 2, 1, 2, 2, 2, 2, 3, 4, 2},
+	{1, 0, 1, 2, 1, 1, 2, 1, 1, 1, 1, 2, 2, 4},
+}
+
+func yoniKoota(bride, groom *Moon) *Koota {
+	b, g := YoniOf(bride.Nakshatra), YoniOf(groom.Nakshatra)
+	points := yoniPoints[slices.Index(yoniOrder, b)][slices.Index(yoniOrder, g)]
+	var explanation string
+	switch points {
+	case 4:
+		explanation = "same yoni"
+	case 3:
+		explanation = "friendly yonis"
+	case 2:
+		explanation = "neutral yonis"
+	case 1:
+		explanation = "unfriendly yonis"
+	default:
+		explanation = "enemy yonis"
+	}
+	return &Koota{
+		Type:        KootaType_Yoni,
+		Points:      points,
+		TablePoints: points,
+		MaxPoints:   4,
+		Bride:       b.String(),
+		Groom:       g.String(),
+		Explanation: explanation,
+	}
+}
+
+// areLordsFriendly reports whether the lords of the Moon signs of bride and
+// groom are the same planet or friends of each other
+func areLordsFriendly(bride, groom *Moon) bool {
+	b, g := bride.Sign.TraditionalRuler(), groom.Sign.TraditionalRuler()
+	return b == g || (chart.NaturalRelationship(b, g).IsFriendly() &&
+		chart.NaturalRelationship(g, b).IsFriendly())
+}
+
+func grahaMaitriKoota(bride, groom *Moon) *Koota {
+	b, g := bride.Sign.TraditionalRuler(), groom.Sign.TraditionalRuler()
+	ret := &Koota{
+		Type:      KootaType_GrahaMaitri,
+		MaxPoints: 5,
+		Bride:     b.String(),
+		Groom:     g.String(),
+	}
+	bg, gb := chart.NaturalRelationship(b, g), chart.NaturalRelationship(g, b)
+	if b == g {
+		ret.TablePoints = 5
+		ret.Explanation = "same lord"
+	} else {
+		// The natural relationships both ways add up to 2 for two friends,
+		// 1 for a friend and a neutral, 0 for two neutrals or a friend and
+		// an enemy, etc.
+		switch bg + gb {
+		case 2:
+			ret.TablePoints = 5
+		case 1:
+			ret.TablePoints = 4
+		case 0:
+			if bg == chart.Relationship_Neutral {
+				ret.TablePoints = 3
+			} else {
+				ret.TablePoints = 1
+			}
+		case -1:
+			ret.TablePoints = 0.5
+		}
+		ret.Explanation = fmt.Sprintf(
+			"%s sees %s as a %s, %s sees %s as a %s",
+			b, g, bg,
+			g, b, gb,
+		)
+	}
+	ret.Points = ret.TablePoints
+	return ret
+}
+
+type Gana string
+
+const (
+	Gana_Deva     Gana = "deva"
+	Gana_Manushya Gana = "manushya"
+	Gana_Rakshasa Gana = "rakshasa"
+)
+
+func (g Gana) String() string {
+	return string(g)
+}
+
+func GanaOf(nak chart.NakshatraType) Gana {
+	switch nak {
+	case chart.Aswini, chart.Mrigashirsha, chart.Punarvasu, chart.Pushya,
+		chart.Hasta, chart.Swati, chart.Anuradha, chart.Shravana, chart.Revati:
+		return Gana_Deva
+	case chart.Bharani, chart.Rohini, chart.Ardra, chart.PurvaPhalguni,
+		chart.UttaraPhalguni, chart.PurvaAshadha, chart.UttaraAshadha,
+		chart.PurvaBhadrapada, chart.UttaraBhadrapada:
+		return Gana_Manushya
+	}
+	return Gana_Rakshasa
+}
+
+// ganaPoints are the points of the gana of the bride (first key) with the
+// gana of the groom
+var ganaPoints = map[Gana]map[Gana]float64{
+	Gana_Deva:     {Gana_Deva: 6, Gana_Manushya: 5, Gana_Rakshasa: 1},
+	Gana_Manushya: {Gana_Deva: 6, Gana_Manushya: 6, Gana_Rakshasa: 0},
+	Gana_Rakshasa: {Gana_Deva: 0, Gana_Manushya: 0, Gana_Rakshasa: 6},
+}
+
+func ganaKoota(bride, groom *Moon, bhakoot *Koota) *Koota {
+	b, g := GanaOf(bride.Nakshatra), GanaOf(groom.Nakshatra)
+	points := ganaPoints[b][g]
+	ret := &Koota{
+		Type:        KootaType_Gana,
+		Points:      points,
+		TablePoints: points,
+		MaxPoints:   6,
+		Bride:       b.String(),
+		Groom:       g.String(),
+		IsDosha:     points == 0,
+	}
+	switch {
+	case b == g:
+		ret.Explanation = "same gana"
+	case points == 0:
+		ret.Explanation = fmt.Sprintf("a %s bride and a %s groom are incompatible", b, g)
+	default:
+		ret.Explanation = fmt.Sprintf("a %s bride and a %s groom", b, g)
+	}
+	if !ret.IsDosha {
+		return ret
+	}
+	switch {
+	case areLordsFriendly(bride, groom):
+		ret.cancel("the lords of the moon signs are the same or friends")
+	case !bhakoot.IsDosha:
+		ret.cancel("the bhakoot is favorable")
+	}
+	return ret
+}
+
+func bhakootKoota(bride, groom *Moon) *Koota {
+	// The sign of the groom counted from the bride's, and the other way
+	// around
+	fromBride := house.NewHouseFromSign(groom.Sign, bride.Sign).Int()
+	fromGroom := house.NewHouseFromSign(bride.Sign, groom.Sign).Int()
+	ret := &Koota{
+		Type:        KootaType_Bhakoot,
+		MaxPoints:   7,
+		Bride:       bride.Sign.String(),
+		Groom:       groom.Sign.String(),
+		Explanation: fmt.Sprintf("the signs are %d/%d from each other", fromBride, fromGroom),
+	}
+	// 2/12 (dvirdvadasha), 5/9 (navapanchama) and 6/8 (shadashtaka) are
+	// doshas
+	switch fromBride {
+	case 2, 12, 5, 9, 6, 8:
+		ret.IsDosha = true
+	default:
+		ret.TablePoints = 7
+	}
+	ret.Points = ret.TablePoints
+	if ret.IsDosha && areLordsFriendly(bride, groom) {
+		ret.cancel("the lords of the moon signs are the same or friends")
+	}
+	return ret
+}
+
+type Nadi string
+
+const (
+	Nadi_Adi    Nadi = "adi"
+	Nadi_Madhya Nadi = "madhya"
+	Nadi_Antya  Nadi = "antya"
+)
+
+func (n Nadi) String() string {
+	return string(n)
+}
+
+// NadiOf returns the nadi of nak: they go back and forth over the nakshatras
+// (adi, madhya, antya, antya, madhya, adi, adi, ...)
+func NadiOf(nak chart.NakshatraType) Nadi {
+	return []Nadi{
+		Nadi_Adi,
+		Nadi_Madhya,
+		Nadi_Antya,
+		Nadi_Antya,
+		Nadi_Madhya,
+		Nadi_Adi,
+	}[int(nak)%6]
+}
+
+func nadiKoota(bride, groom *Moon) *Koota {
+	b, g := NadiOf(bride.Nakshatra), NadiOf(groom.Nakshatra)
+	ret := &Koota{
+		Type:      KootaType_Nadi,
+		MaxPoints: 8,
+		Bride:     b.String(),
+		Groom:     g.String(),
+	}
+	if b != g {
+		ret.TablePoints = 8
+		ret.Points = 8
+		ret.Explanation = "different nadis"
+		return ret
+	}
+	ret.IsDosha = true
+	ret.Explanation = "same nadi"
+	sameSign := bride.Sign == groom.Sign
+	sameNakshatra := bride.Nakshatra == groom.Nakshatra
+	switch {
+	case sameSign && !sameNakshatra:
+		ret.cancel("same moon sign but different nakshatras")
+	case sameNakshatra && !sameSign:
+		ret.cancel("same nakshatra but different moon signs")
+	case sameNakshatra && bride.Pada != groom.Pada:
+		ret.cancel("same nakshatra but different padas")
+	}
+	return ret
+}
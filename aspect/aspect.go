@@ -6,17 +6,8 @@ import (
 
 	"github.com/afjoseph/sacredstar/pointid"
 	"github.com/afjoseph/sacredstar/zodiacalpos"
-	"github.com/tidwall/btree"
 )
 
-var orbTable = map[AspectType]float64{
-	AspectType_Conjunction: 5,
-	AspectType_Opposition:  5,
-	AspectType_Trine:       5,
-	AspectType_Square:      5,
-	AspectType_Sextile:     3,
-}
-
 type AspectType int
 
 const (
@@ -25,12 +16,45 @@ const (
 	AspectType_Trine
 	AspectType_Square
 	AspectType_Sextile
-	// AspectType_SemiSquare
-	// AspectType_Sesquiquadrate
-	// AspectType_SemiSextile
 	AspectType_None
+	// The aspect types are serialized as numbers, so new ones go after the
+	// existing ones
+	AspectType_SemiSquare
+	AspectType_Sesquiquadrate
+	AspectType_SemiSextile
+	AspectType_Quintile
+	AspectType_Biquintile
+	AspectType_Septile
+	AspectType_Novile
+	AspectType_Quincunx
 )
 
+// MajorAspectTypes are the Ptolemaic aspects
+var MajorAspectTypes = []AspectType{
+	AspectType_Conjunction,
+	AspectType_Opposition,
+	AspectType_Trine,
+	AspectType_Square,
+	AspectType_Sextile,
+}
+
+// MinorAspectTypes are the minor aspects derived from the 8th and 12th
+// harmonics
+var MinorAspectTypes = []AspectType{
+	AspectType_SemiSquare,
+	AspectType_Sesquiquadrate,
+	AspectType_SemiSextile,
+	AspectType_Quincunx,
+}
+
+// HarmonicAspectTypes are the aspects of the 5th, 7th and 9th harmonics
+var HarmonicAspectTypes = []AspectType{
+	AspectType_Quintile,
+	AspectType_Biquintile,
+	AspectType_Septile,
+	AspectType_Novile,
+}
+
 func (at AspectType) String() string {
 	switch at {
 	case AspectType_Conjunction:
@@ -43,6 +67,22 @@ func (at AspectType) String() string {
 		return "Square"
 	case AspectType_Sextile:
 		return "Sextile"
+	case AspectType_SemiSquare:
+		return "SemiSquare"
+	case AspectType_Sesquiquadrate:
+		return "Sesquiquadrate"
+	case AspectType_SemiSextile:
+		return "SemiSextile"
+	case AspectType_Quincunx:
+		return "Quincunx"
+	case AspectType_Quintile:
+		return "Quintile"
+	case AspectType_Biquintile:
+		return "Biquintile"
+	case AspectType_Septile:
+		return "Septile"
+	case AspectType_Novile:
+		return "Novile"
 	}
 	return "None"
 }
@@ -59,65 +99,109 @@ func (a AspectType) Degree() float64 {
 		return 90
 	case AspectType_Sextile:
 		return 60
+	case AspectType_SemiSquare:
+		return 45
+	case AspectType_Sesquiquadrate:
+		return 135
+	case AspectType_SemiSextile:
+		return 30
+	case AspectType_Quincunx:
+		return 150
+	case AspectType_Quintile:
+		return 72
+	case AspectType_Biquintile:
+		return 144
+	case AspectType_Septile:
+		return 360.0 / 7.0
+	case AspectType_Novile:
+		return 40
 	}
 	return 0
 }
 
+// IsMajor reports whether this is one of the Ptolemaic aspects
+func (a AspectType) IsMajor() bool {
+	for _, at := range MajorAspectTypes {
+		if at == a {
+			return true
+		}
+	}
+	return false
+}
+
 type Aspect struct {
 	P1     pointid.PointID `json:"p1"`
 	P2     pointid.PointID `json:"p2"`
 	Degree float64         `json:"degree"`
 	Type   AspectType      `json:"type"`
+	// AllowedOrb is the orb the aspect config allowed for this pair of points
+	// and this aspect type
+	AllowedOrb float64 `json:"allowedOrb"`
+	// Deviation is how far (in degrees) the aspect is from exact
+	Deviation float64 `json:"deviation"`
+	// Motion and DaysToPerfection are only known if the aspect was created
+	// with the speeds of its points (see NewAspectWithSpeeds).
+	// DaysToPerfection is negative if the aspect already perfected.
+	Motion           AspectMotion `json:"motion"`
+	DaysToPerfection float64      `json:"daysToPerfection"`
 }
 
-type degreeInterval struct {
-	Type AspectType
-	Deg  float64
-}
-
+// NewAspect finds the aspect between two zodiacal positions. If cfg is nil,
+// the default config (see NewDefaultConfig) is used.
+//
+// If the distance between the two positions falls within the orb of more
+// than one aspect, the aspect closest to exact wins.
 func NewAspect(
 	lhsID pointid.PointID,
 	lhsZP *zodiacalpos.ZodiacalPos,
 	rhsID pointid.PointID,
 	rhsZP *zodiacalpos.ZodiacalPos,
+	cfg *Config,
 ) *Aspect {
+	if cfg == nil {
+		cfg = NewDefaultConfig()
+	}
 	diff := lhsZP.DiffInAbsDegrees(rhsZP)
 
-	tree := *btree.NewBTreeG[degreeInterval](func(a, b degreeInterval) bool {
-		orbA := orbTable[a.Type]
-		orbB := orbTable[b.Type]
-		return (a.Deg - orbA) < (b.Deg - orbB)
-	})
-	tree.Set(degreeInterval{Type: AspectType_Conjunction, Deg: 0})
-	tree.Set(degreeInterval{Type: AspectType_Opposition, Deg: 180})
-	tree.Set(degreeInterval{Type: AspectType_Trine, Deg: 120})
-	tree.Set(degreeInterval{Type: AspectType_Square, Deg: 90})
-	tree.Set(degreeInterval{Type: AspectType_Sextile, Deg: 60})
-
-	didFind := false
 	aspectType := AspectType_None
-	tree.Scan(func(di degreeInterval) bool {
-		orb := orbTable[di.Type]
-		if (di.Deg-orb) <= diff && diff <= (di.Deg+orb) {
-			didFind = true
-			aspectType = di.Type
-			return false
+	allowedOrb := 0.0
+	bestDistance := math.MaxFloat64
+	for _, at := range cfg.AspectTypes {
+		orb := cfg.Orb(at, lhsID, rhsID)
+		distance := math.Abs(diff - at.Degree())
+		if distance <= orb && distance < bestDistance {
+			aspectType = at
+			allowedOrb = orb
+			bestDistance = distance
 		}
-		return true
-	})
-	if !didFind {
-		aspectType = AspectType_None
+	}
+	deviation := 0.0
+	if aspectType != AspectType_None {
+		deviation = bestDistance
 	}
 	return &Aspect{
-		P1:     lhsID,
-		P2:     rhsID,
-		Degree: diff,
-		Type:   aspectType,
+		P1:         lhsID,
+		P2:         rhsID,
+		Degree:     diff,
+		Type:       aspectType,
+		AllowedOrb: allowedOrb,
+		Deviation:  deviation,
+		Motion:     AspectMotion_Unknown,
 	}
 }
 
+// Reversed returns a copy of the aspect with P1 and P2 swapped
+func (a *Aspect) Reversed() *Aspect {
+	ret := *a
+	ret.P1, ret.P2 = a.P2, a.P1
+	return &ret
+}
+
+// Orb returns the allowed orb of a, rounded down to a whole degree.
+//
+// Deprecated: orbs can be fractional: use AllowedOrb instead.
 func (a *Aspect) Orb() int {
-	return int(orbTable[a.Type])
+	return int(a.AllowedOrb)
 }
 
 func (a *Aspect) String() string {
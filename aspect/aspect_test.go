@@ -122,6 +122,7 @@ func TestNewAspect(t *testing.T) {
 				tc.inputLHS,
 				pointid.Sun,
 				tc.inputRHS,
+				nil,
 			)
 			assert.NotNil(t, aspect)
 
@@ -130,3 +131,223 @@ func TestNewAspect(t *testing.T) {
 		})
 	}
 }
+
+func TestNewAspectWithConfig(t *testing.T) {
+	type testCase struct {
+		title              string
+		cfg                *Config
+		lhsID              pointid.PointID
+		rhsID              pointid.PointID
+		inputLHS           *zodiacalpos.ZodiacalPos
+		inputRHS           *zodiacalpos.ZodiacalPos
+		expectedAspectType AspectType
+		expectedOrb        float64
+	}
+
+	for _, tc := range []testCase{
+		testCase{
+			title:              "default config ignores minor aspects",
+			cfg:                NewDefaultConfig(),
+			lhsID:              pointid.Mars,
+			rhsID:              pointid.Venus,
+			inputLHS:           zodiacalpos.NewZodiacalPosFromLongitude(0),
+			inputRHS:           zodiacalpos.NewZodiacalPosFromLongitude(45),
+			expectedAspectType: AspectType_None,
+			expectedOrb:        0,
+		},
+		testCase{
+			title:              "modern config finds semi-square",
+			cfg:                NewModernConfig(),
+			lhsID:              pointid.Mars,
+			rhsID:              pointid.Venus,
+			inputLHS:           zodiacalpos.NewZodiacalPosFromLongitude(0),
+			inputRHS:           zodiacalpos.NewZodiacalPosFromLongitude(46),
+			expectedAspectType: AspectType_SemiSquare,
+			expectedOrb:        2,
+		},
+		testCase{
+			title:              "modern config uses luminary orbs",
+			cfg:                NewModernConfig(),
+			lhsID:              pointid.Sun,
+			rhsID:              pointid.Venus,
+			inputLHS:           zodiacalpos.NewZodiacalPosFromLongitude(0),
+			inputRHS:           zodiacalpos.NewZodiacalPosFromLongitude(9),
+			expectedAspectType: AspectType_Conjunction,
+			expectedOrb:        10,
+		},
+		testCase{
+			title:              "modern config without luminaries",
+			cfg:                NewModernConfig(),
+			lhsID:              pointid.Mars,
+			rhsID:              pointid.Venus,
+			inputLHS:           zodiacalpos.NewZodiacalPosFromLongitude(0),
+			inputRHS:           zodiacalpos.NewZodiacalPosFromLongitude(9),
+			expectedAspectType: AspectType_None,
+			expectedOrb:        0,
+		},
+		testCase{
+			title:              "traditional config adds moieties",
+			cfg:                NewTraditionalConfig(),
+			lhsID:              pointid.Sun,
+			rhsID:              pointid.Moon,
+			inputLHS:           zodiacalpos.NewZodiacalPosFromLongitude(0),
+			inputRHS:           zodiacalpos.NewZodiacalPosFromLongitude(133),
+			expectedAspectType: AspectType_Trine,
+			expectedOrb:        13.5,
+		},
+		testCase{
+			title:              "harmonic config finds quintile",
+			cfg:                NewHarmonicConfig(),
+			lhsID:              pointid.Mars,
+			rhsID:              pointid.Venus,
+			inputLHS:           zodiacalpos.NewZodiacalPosFromLongitude(10),
+			inputRHS:           zodiacalpos.NewZodiacalPosFromLongitude(83),
+			expectedAspectType: AspectType_Quintile,
+			expectedOrb:        2,
+		},
+		testCase{
+			title: "overlapping orbs pick the tightest aspect",
+			cfg: &Config{
+				AspectTypes: []AspectType{
+					AspectType_Square,
+					AspectType_Quintile,
+				},
+				AspectOrbs: map[AspectType]float64{
+					AspectType_Square:   20,
+					AspectType_Quintile: 5,
+				},
+			},
+			lhsID:              pointid.Mars,
+			rhsID:              pointid.Venus,
+			inputLHS:           zodiacalpos.NewZodiacalPosFromLongitude(0),
+			inputRHS:           zodiacalpos.NewZodiacalPosFromLongitude(75),
+			expectedAspectType: AspectType_Quintile,
+			expectedOrb:        5,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			asp := NewAspect(
+				tc.lhsID,
+				tc.inputLHS,
+				tc.rhsID,
+				tc.inputRHS,
+				tc.cfg,
+			)
+			assert.NotNil(t, asp)
+			assert.Equal(t, tc.expectedAspectType, asp.Type)
+			assert.Equal(t, tc.expectedOrb, asp.AllowedOrb)
+		})
+	}
+}
+
+func TestConfigForChartType(t *testing.T) {
+	d9Config := NewTraditionalConfig()
+	cfg := NewModernConfig()
+	cfg.ChartTypeConfigs = map[string]*Config{"d9": d9Config}
+
+	assert.Equal(t, d9Config, cfg.ForChartType("d9"))
+	assert.Equal(t, cfg, cfg.ForChartType("tropical"))
+}
+
+func TestNewAspectWithSpeeds(t *testing.T) {
+	type testCase struct {
+		title                    string
+		lhsLongitude             float64
+		lhsSpeed                 float64
+		rhsLongitude             float64
+		rhsSpeed                 float64
+		expectedAspectType       AspectType
+		expectedMotion           AspectMotion
+		expectedDaysToPerfection float64
+	}
+
+	for _, tc := range []testCase{
+		testCase{
+			title:                    "faster planet behind slower planet is applying",
+			lhsLongitude:             10,
+			lhsSpeed:                 13,
+			rhsLongitude:             12,
+			rhsSpeed:                 1,
+			expectedAspectType:       AspectType_Conjunction,
+			expectedMotion:           AspectMotion_Applying,
+			expectedDaysToPerfection: 2.0 / 12.0,
+		},
+		testCase{
+			title:                    "faster planet ahead of slower planet is separating",
+			lhsLongitude:             12,
+			lhsSpeed:                 13,
+			rhsLongitude:             10,
+			rhsSpeed:                 1,
+			expectedAspectType:       AspectType_Conjunction,
+			expectedMotion:           AspectMotion_Separating,
+			expectedDaysToPerfection: -2.0 / 12.0,
+		},
+		testCase{
+			title:                    "retrograde planet moving back into a trine is applying",
+			lhsLongitude:             0,
+			lhsSpeed:                 0.1,
+			rhsLongitude:             123,
+			rhsSpeed:                 -0.5,
+			expectedAspectType:       AspectType_Trine,
+			expectedMotion:           AspectMotion_Applying,
+			expectedDaysToPerfection: 5,
+		},
+		testCase{
+			title:                    "square across the zodiac edge",
+			lhsLongitude:             358,
+			lhsSpeed:                 1,
+			rhsLongitude:             90,
+			rhsSpeed:                 0,
+			expectedAspectType:       AspectType_Square,
+			expectedMotion:           AspectMotion_Applying,
+			expectedDaysToPerfection: 2,
+		},
+		testCase{
+			title:                    "equal speeds never perfect",
+			lhsLongitude:             0,
+			lhsSpeed:                 1,
+			rhsLongitude:             62,
+			rhsSpeed:                 1,
+			expectedAspectType:       AspectType_Sextile,
+			expectedMotion:           AspectMotion_Unknown,
+			expectedDaysToPerfection: 0,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			asp := NewAspectWithSpeeds(
+				pointid.Moon,
+				zodiacalpos.NewZodiacalPosFromLongitude(tc.lhsLongitude),
+				tc.lhsSpeed,
+				pointid.Saturn,
+				zodiacalpos.NewZodiacalPosFromLongitude(tc.rhsLongitude),
+				tc.rhsSpeed,
+				nil,
+			)
+			assert.Equal(t, tc.expectedAspectType, asp.Type)
+			assert.Equal(t, tc.expectedMotion, asp.Motion)
+			assert.InDelta(t, tc.expectedDaysToPerfection, asp.DaysToPerfection, 0.001)
+		})
+	}
+}
+
+func TestAspectTypeValues(t *testing.T) {
+	// Aspect types are serialized as numbers, so their values can't change
+	for expected, at := range []AspectType{
+		AspectType_Conjunction,
+		AspectType_Opposition,
+		AspectType_Trine,
+		AspectType_Square,
+		AspectType_Sextile,
+		AspectType_None,
+		AspectType_SemiSquare,
+		AspectType_Sesquiquadrate,
+		AspectType_SemiSextile,
+		AspectType_Quintile,
+		AspectType_Biquintile,
+		AspectType_Septile,
+		AspectType_Novile,
+		AspectType_Quincunx,
+	} {
+		assert.Equal(t, expected, int(at), at.String())
+	}
+}
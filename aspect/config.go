@@ -0,0 +1,194 @@
+package aspect
+
+import (
+	"math"
+
+	"github.com/afjoseph/sacredstar/pointid"
+)
+
+// OrbPolicy decides how the orb of an aspect is computed
+type OrbPolicy int
+
+const (
+	// OrbPolicy_Aspect uses a fixed orb per aspect type (e.g., 8 degrees for
+	// all conjunctions). This is what most modern software does.
+	OrbPolicy_Aspect OrbPolicy = iota
+	// OrbPolicy_Planet uses the orb of each planet and takes the larger of
+	// the two
+	OrbPolicy_Planet
+	// OrbPolicy_Moiety uses half the orb of each planet (its "moiety") and
+	// adds them together. This is the traditional convention (e.g., William
+	// Lilly)
+	OrbPolicy_Moiety
+)
+
+func (op OrbPolicy) String() string {
+	switch op {
+	case OrbPolicy_Aspect:
+		return "aspect"
+	case OrbPolicy_Planet:
+		return "planet"
+	case OrbPolicy_Moiety:
+		return "moiety"
+	}
+	return "unknown"
+}
+
+// Config decides which aspects are considered and how wide their orbs are.
+//
+// Planet-based policies (OrbPolicy_Planet and OrbPolicy_Moiety) only apply to
+// the major aspects: minor and harmonic aspects always use AspectOrbs. Points
+// that don't have an entry in PlanetOrbs (e.g., the ascendant or the nodes)
+// fall back to AspectOrbs as well.
+type Config struct {
+	// AspectTypes are the aspects to look for
+	AspectTypes []AspectType `json:"aspectTypes"`
+	OrbPolicy   OrbPolicy    `json:"orbPolicy"`
+	// AspectOrbs are the orbs per aspect type
+	AspectOrbs map[AspectType]float64 `json:"aspectOrbs"`
+	// LuminaryOrbs override AspectOrbs when one of the points is the Sun or
+	// the Moon. Only used with OrbPolicy_Aspect.
+	LuminaryOrbs map[AspectType]float64 `json:"luminaryOrbs"`
+	// PlanetOrbs are the full orbs per planet. With OrbPolicy_Moiety, half
+	// of each is used.
+	PlanetOrbs map[pointid.PointID]float64 `json:"planetOrbs"`
+	// ChartTypeConfigs override this config for a specific chart type
+	// (e.g., "tropical" or "d9"). See ForChartType.
+	ChartTypeConfigs map[string]*Config `json:"chartTypeConfigs"`
+}
+
+// NewDefaultConfig returns the config used throughout the library when
+// nothing else is specified: major aspects with a 5 degree orb (3 degrees for
+// sextiles)
+func NewDefaultConfig() *Config {
+	return &Config{
+		AspectTypes: MajorAspectTypes,
+		OrbPolicy:   OrbPolicy_Aspect,
+		AspectOrbs: map[AspectType]float64{
+			AspectType_Conjunction: 5,
+			AspectType_Opposition:  5,
+			AspectType_Trine:       5,
+			AspectType_Square:      5,
+			AspectType_Sextile:     3,
+		},
+	}
+}
+
+// NewModernConfig returns a config with the major and minor aspects, wider
+// orbs per aspect type and even wider orbs for the luminaries
+func NewModernConfig() *Config {
+	aspectTypes := append([]AspectType{}, MajorAspectTypes...)
+	aspectTypes = append(aspectTypes, MinorAspectTypes...)
+	return &Config{
+		AspectTypes: aspectTypes,
+		OrbPolicy:   OrbPolicy_Aspect,
+		AspectOrbs:  defaultAspectOrbs(),
+		LuminaryOrbs: map[AspectType]float64{
+			AspectType_Conjunction:    10,
+			AspectType_Opposition:     10,
+			AspectType_Trine:          8,
+			AspectType_Square:         8,
+			AspectType_Sextile:        6,
+			AspectType_SemiSquare:     3,
+			AspectType_Sesquiquadrate: 3,
+			AspectType_SemiSextile:    3,
+			AspectType_Quincunx:       4,
+		},
+	}
+}
+
+// NewTraditionalConfig returns a config with the Ptolemaic aspects and
+// William Lilly's moieties
+func NewTraditionalConfig() *Config {
+	return &Config{
+		AspectTypes: MajorAspectTypes,
+		OrbPolicy:   OrbPolicy_Moiety,
+		AspectOrbs:  defaultAspectOrbs(),
+		PlanetOrbs: map[pointid.PointID]float64{
+			pointid.Sun:     15,
+			pointid.Moon:    12,
+			pointid.Mercury: 7,
+			pointid.Venus:   7,
+			pointid.Mars:    7,
+			pointid.Jupiter: 9,
+			pointid.Saturn:  9,
+		},
+	}
+}
+
+// NewHarmonicConfig returns the modern config with the quintile, biquintile,
+// septile and novile added
+func NewHarmonicConfig() *Config {
+	cfg := NewModernConfig()
+	cfg.AspectTypes = append(cfg.AspectTypes, HarmonicAspectTypes...)
+	return cfg
+}
+
+func defaultAspectOrbs() map[AspectType]float64 {
+	return map[AspectType]float64{
+		AspectType_Conjunction:    8,
+		AspectType_Opposition:     8,
+		AspectType_Trine:          7,
+		AspectType_Square:         7,
+		AspectType_Sextile:        5,
+		AspectType_SemiSquare:     2,
+		AspectType_Sesquiquadrate: 2,
+		AspectType_SemiSextile:    2,
+		AspectType_Quincunx:       3,
+		AspectType_Quintile:       2,
+		AspectType_Biquintile:     2,
+		AspectType_Septile:        1,
+		AspectType_Novile:         1,
+	}
+}
+
+// ForChartType returns the config to use for a chart type. If there's no
+// override for chartType, c itself is returned.
+func (c *Config) ForChartType(chartType string) *Config {
+	if override, ok := c.ChartTypeConfigs[chartType]; ok && override != nil {
+		return override
+	}
+	return c
+}
+
+// Includes reports whether at is one of the aspects this config looks for
+func (c *Config) Includes(at AspectType) bool {
+	for _, a := range c.AspectTypes {
+		if a == at {
+			return true
+		}
+	}
+	return false
+}
+
+// Orb returns the orb allowed for an aspect of type at between p1 and p2
+func (c *Config) Orb(at AspectType, p1, p2 pointid.PointID) float64 {
+	aspectOrb := c.AspectOrbs[at]
+	if !at.IsMajor() {
+		return aspectOrb
+	}
+
+	switch c.OrbPolicy {
+	case OrbPolicy_Planet:
+		orb1, ok1 := c.PlanetOrbs[p1]
+		orb2, ok2 := c.PlanetOrbs[p2]
+		if !ok1 || !ok2 {
+			return aspectOrb
+		}
+		return math.Max(orb1, orb2)
+	case OrbPolicy_Moiety:
+		orb1, ok1 := c.PlanetOrbs[p1]
+		orb2, ok2 := c.PlanetOrbs[p2]
+		if !ok1 || !ok2 {
+			return aspectOrb
+		}
+		return orb1/2 + orb2/2
+	default:
+		if p1.IsLuminary() || p2.IsLuminary() {
+			if orb, ok := c.LuminaryOrbs[at]; ok {
+				return orb
+			}
+		}
+		return aspectOrb
+	}
+}
@@ -0,0 +1,97 @@
+package aspect
+
+import (
+	"math"
+	"time"
+
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/zodiacalpos"
+)
+
+// AspectMotion tells if an aspect is getting tighter or wider
+type AspectMotion string
+
+const (
+	// AspectMotion_Unknown is used when the speeds of the points are unknown
+	// (or equal, so the aspect never perfects)
+	AspectMotion_Unknown    AspectMotion = "unknown"
+	AspectMotion_Applying   AspectMotion = "applying"
+	AspectMotion_Separating AspectMotion = "separating"
+)
+
+func (am AspectMotion) String() string {
+	return string(am)
+}
+
+// NewAspectWithSpeeds is like NewAspect but also uses the daily motion of both
+// points (in degrees, negative when retrograde) to figure out if the aspect
+// is applying or separating and when it perfects.
+func NewAspectWithSpeeds(
+	lhsID pointid.PointID,
+	lhsZP *zodiacalpos.ZodiacalPos,
+	lhsSpeed float64,
+	rhsID pointid.PointID,
+	rhsZP *zodiacalpos.ZodiacalPos,
+	rhsSpeed float64,
+	cfg *Config,
+) *Aspect {
+	asp := NewAspect(lhsID, lhsZP, rhsID, rhsZP, cfg)
+	if asp.Type == AspectType_None {
+		return asp
+	}
+
+	// Signed distance from lhs to rhs, in (-180, 180]
+	sep := rhsZP.AbsDegrees() - lhsZP.AbsDegrees()
+	for sep > 180 {
+		sep -= 360
+	}
+	for sep <= -180 {
+		sep += 360
+	}
+	// How fast the (unsigned) distance between the two points is changing
+	rate := rhsSpeed - lhsSpeed
+	if sep < 0 {
+		rate = -rate
+	}
+	// Signed deviation from exact: negative means the points haven't
+	// reached the exact aspect degree yet
+	deviation := math.Abs(sep) - asp.Type.Degree()
+	if rate == 0 {
+		return asp
+	}
+
+	asp.DaysToPerfection = -deviation / rate
+	switch {
+	case deviation == 0:
+		asp.Motion = AspectMotion_Separating
+	case math.Signbit(deviation) != math.Signbit(rate):
+		asp.Motion = AspectMotion_Applying
+	default:
+		asp.Motion = AspectMotion_Separating
+	}
+	return asp
+}
+
+// IsApplying reports whether the aspect is getting tighter
+func (a *Aspect) IsApplying() bool {
+	return a.Motion == AspectMotion_Applying
+}
+
+// IsSeparating reports whether the aspect is getting wider
+func (a *Aspect) IsSeparating() bool {
+	return a.Motion == AspectMotion_Separating
+}
+
+// PerfectionTime estimates when the aspect perfects (or did perfect, if the
+// aspect is separating) given the time the aspect was calculated at.
+//
+// The estimate assumes both points keep their current speed. It returns false
+// if the motion of the aspect is unknown.
+func (a *Aspect) PerfectionTime(from time.Time) (time.Time, bool) {
+	if a.Motion == AspectMotion_Unknown || a.Motion == "" {
+		return time.Time{}, false
+	}
+	return from.Add(
+		time.Duration(a.DaysToPerfection * float64(24*time.Hour)),
+	), true
+}
@@ -16,6 +16,9 @@ type AstroPoint struct {
 	ZodiacalPos  *zodiacalpos.ZodiacalPos `json:"zodiacalPos"`
 	House        house.House              `json:"house"`
 	IsRetrograde bool                     `json:"isRetrograde"`
+	// Speed is the daily motion in longitude, in degrees. It is negative
+	// when the point is retrograde
+	Speed float64 `json:"speed"`
 }
 
 func (p *AstroPoint) String() string {
@@ -132,5 +135,22 @@ func (p *AstroPoint) IsFall() bool {
 }
 
 func (ap *AstroPoint) GetAspect(rhs *AstroPoint) *aspect.Aspect {
-	return aspect.NewAspect(ap.ID, ap.ZodiacalPos, rhs.ID, rhs.ZodiacalPos)
+	return ap.GetAspectWithConfig(rhs, nil)
+}
+
+// GetAspectWithConfig is like GetAspect but uses cfg to decide which aspects
+// and orbs to consider
+func (ap *AstroPoint) GetAspectWithConfig(
+	rhs *AstroPoint,
+	cfg *aspect.Config,
+) *aspect.Aspect {
+	return aspect.NewAspectWithSpeeds(
+		ap.ID,
+		ap.ZodiacalPos,
+		ap.Speed,
+		rhs.ID,
+		rhs.ZodiacalPos,
+		rhs.Speed,
+		cfg,
+	)
 }
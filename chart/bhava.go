@@ -0,0 +1,212 @@
+package chart
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/afjoseph/sacredstar/zodiacalpos"
+)
+
+// BhavaSystem decides how the bhavas (houses) of a Bhava Chalit chart are
+// measured from the ascendant degree
+type BhavaSystem string
+
+const (
+	BhavaSystem_None BhavaSystem = ""
+	// Every bhava is 30° wide, and the ascendant is the middle of the 1st
+	BhavaSystem_Equal BhavaSystem = "equal"
+	// The quadrants between the ascendant, the MC and their opposites are
+	// trisected to get the bhava madhyas (like Porphyry), and the sandhis are
+	// halfway between them
+	BhavaSystem_Sripati BhavaSystem = "sripati"
+)
+
+func (bs BhavaSystem) String() string {
+	return string(bs)
+}
+
+// Bhava is a house of a Bhava Chalit chart. It goes from the sandhi (the
+// junction) with the previous bhava to the sandhi with the next one, and is
+// strongest at its madhya (its middle).
+type Bhava struct {
+	House house.House `json:"house"`
+	// Start is the longitude of the sandhi with the previous bhava
+	Start float64 `json:"start"`
+	// Madhya is the longitude of the middle of the bhava
+	Madhya float64 `json:"madhya"`
+	// End is the longitude of the sandhi with the next bhava
+	End float64 `json:"end"`
+	// Sign is the sign of the madhya: its lord is the lord of the bhava
+	Sign sign.Sign `json:"sign"`
+}
+
+func (b *Bhava) String() string {
+	return fmt.Sprintf(
+		"Bhava{House: %s, Start: %f, Madhya: %f, End: %f, Sign: %s}",
+		b.House,
+		b.Start,
+		b.Madhya,
+		b.End,
+		b.Sign,
+	)
+}
+
+// Contains reports whether lon falls in b, from its start sandhi up to (and
+// not including) its end sandhi
+func (b *Bhava) Contains(lon float64) bool {
+	return math.Mod(lon-b.Start+360, 360) < math.Mod(b.End-b.Start+360, 360)
+}
+
+// BhavaShift is a point that's in a different house in the Bhava Chalit
+// chart than in the rashi (whole sign) chart
+type BhavaShift struct {
+	Point      pointid.PointID `json:"point"`
+	RashiHouse house.House     `json:"rashiHouse"`
+	BhavaHouse house.House     `json:"bhavaHouse"`
+}
+
+func (bs *BhavaShift) String() string {
+	return fmt.Sprintf(
+		"BhavaShift{Point: %s, RashiHouse: %s, BhavaHouse: %s}",
+		bs.Point,
+		bs.RashiHouse,
+		bs.BhavaHouse,
+	)
+}
+
+// calculateBhavas returns the 12 bhavas of bs for a chart whose (sidereal)
+// ascendant is at ascLon, in ayanamsa
+func calculateBhavas(
+	swe *wrapper.SwissEph,
+	timeInJulian float64,
+	lon, lat float64,
+	ascLon float64,
+	ayanamsa wrapper.Ayanamsa,
+	bs BhavaSystem,
+) ([]*Bhava, error) {
+	madhyas := make([]float64, 12)
+	switch bs {
+	case BhavaSystem_Equal:
+		for i := range madhyas {
+			madhyas[i] = math.Mod(ascLon+float64(i)*30, 360)
+		}
+	case BhavaSystem_Sripati:
+		// The Porphyry cusps are the Sripati madhyas. Swisseph's own Sripati
+		// system gives the sandhis instead.
+		var err error
+		madhyas, err = swe.Cusps(
+			timeInJulian,
+			lon, lat,
+			true,
+			ayanamsa,
+			wrapper.HouseSystem_Porphyry,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("while calculating bhava madhyas: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown bhava system: %s", bs)
+	}
+
+	// sandhis[i] is the sandhi between bhava i and bhava i+1
+	sandhis := make([]float64, 12)
+	for i, m := range madhyas {
+		next := madhyas[(i+1)%12]
+		sandhis[i] = math.Mod(m+math.Mod(next-m+360, 360)/2, 360)
+	}
+	ret := []*Bhava{}
+	for i, m := range madhyas {
+		h, err := house.HouseFromInt(i + 1)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, &Bhava{
+			House:  h,
+			Start:  sandhis[(i+11)%12],
+			Madhya: m,
+			End:    sandhis[i],
+			Sign:   zodiacalpos.NewZodiacalPosFromLongitude(m).Sign,
+		})
+	}
+	return ret, nil
+}
+
+// GetBhava returns the bhava of house h, or nil if c has no bhavas (see
+// Config.BhavaSystem)
+func (c *Chart) GetBhava(h house.House) *Bhava {
+	for _, b := range c.Bhavas {
+		if b.House == h {
+			return b
+		}
+	}
+	return nil
+}
+
+// GetBhavaHouseFor returns the house of id in the Bhava Chalit chart of c,
+// instead of by whole signs like AstroPoint.House
+func (c *Chart) GetBhavaHouseFor(id pointid.PointID) (house.House, error) {
+	if c.Bhavas == nil {
+		return house.HouseNone, fmt.Errorf("%s chart has no bhavas", c.ChartType)
+	}
+	p := c.GetPoint(id)
+	if p == nil {
+		return house.HouseNone, fmt.Errorf("%s not found in chart", id)
+	}
+	for _, b := range c.Bhavas {
+		if b.Contains(p.Longitude) {
+			return b.House, nil
+		}
+	}
+	// Can only happen through rounding, right at a sandhi
+	return house.House12, nil
+}
+
+// GetBhavaLordFor is like GetHouseLordFor, but the lord is the ruler of the
+// sign of the bhava madhya of h
+func (c *Chart) GetBhavaLordFor(
+	h house.House,
+	placementType HouseLordPlacement,
+) (pointid.PointID, error) {
+	b := c.GetBhava(h)
+	if b == nil {
+		return pointid.None, fmt.Errorf("%s chart has no bhavas", c.ChartType)
+	}
+	switch placementType {
+	case HouseLordPlacement_Traditional:
+		return b.Sign.TraditionalRuler(), nil
+	case HouseLordPlacement_Modern:
+		return b.Sign.ModernRuler(), nil
+	default:
+		return pointid.None, fmt.Errorf("invalid placement type: %d",
+			placementType)
+	}
+}
+
+// BhavaShifts returns the points of c (except the ascendant) that are in a
+// different house in the Bhava Chalit chart than in the rashi chart, in the
+// order of c.Points
+func (c *Chart) BhavaShifts() ([]*BhavaShift, error) {
+	ret := []*BhavaShift{}
+	for _, p := range c.Points {
+		if p.ID == pointid.ASC {
+			continue
+		}
+		h, err := c.GetBhavaHouseFor(p.ID)
+		if err != nil {
+			return nil, err
+		}
+		if h == p.House {
+			continue
+		}
+		ret = append(ret, &BhavaShift{
+			Point:      p.ID,
+			RashiHouse: p.House,
+			BhavaHouse: h,
+		})
+	}
+	return ret, nil
+}
@@ -0,0 +1,63 @@
+package chart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBhavaChalit(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+	// London coordinates
+	lon, lat := -0.1278, 51.5074
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// No bhavas unless asked for
+	c, err := NewChartFromUTC(swe, date, lon, lat, D1ChartType, pointid.VedicPlanets)
+	require.NoError(t, err)
+	require.Nil(t, c.Bhavas)
+	_, err = c.BhavaShifts()
+	require.Error(t, err)
+
+	cfg := NewDefaultConfig()
+	cfg.BhavaSystem = BhavaSystem_Equal
+	c, err = NewChartFromUTCWithConfig(swe, date, lon, lat, D1ChartType, pointid.VedicPlanets, cfg)
+	require.NoError(t, err)
+	require.Len(t, c.Bhavas, 12)
+	asc := c.MustGetPoint(pointid.ASC).Longitude
+	require.InDelta(t, asc, c.GetBhava(house.House1).Madhya, 1e-9)
+	require.InDelta(t, asc-15, c.GetBhava(house.House1).Start, 1e-9)
+	// The ascendant is at Virgo 12°53': Mercury, at Scorpio 28°05', is past
+	// the sandhi of the 3rd and 4th bhavas (Scorpio 27°53')
+	shifts, err := c.BhavaShifts()
+	require.NoError(t, err)
+	require.Equal(t, []*BhavaShift{
+		{Point: pointid.Mercury, RashiHouse: house.House3, BhavaHouse: house.House4},
+	}, shifts)
+
+	cfg.BhavaSystem = BhavaSystem_Sripati
+	c, err = NewChartFromUTCWithConfig(swe, date, lon, lat, D1ChartType, pointid.VedicPlanets, cfg)
+	require.NoError(t, err)
+	require.InDelta(t, asc, c.GetBhava(house.House1).Madhya, 1e-9)
+	// The 10th bhava madhya is the MC, at Gemini 15°01'
+	tenth := c.GetBhava(house.House10)
+	require.Equal(t, sign.Gemini, tenth.Sign)
+	require.InDelta(t, 75.02, tenth.Madhya, 0.01)
+	require.InDelta(t, (tenth.Start+tenth.End)/2, tenth.Madhya, 1)
+	lord, err := c.GetBhavaLordFor(house.House10, HouseLordPlacement_Traditional)
+	require.NoError(t, err)
+	require.Equal(t, pointid.Mercury, lord)
+	// The sandhis are further out than the equal ones: Mercury stays put
+	h, err := c.GetBhavaHouseFor(pointid.Mercury)
+	require.NoError(t, err)
+	require.Equal(t, house.House3, h)
+	shifts, err = c.BhavaShifts()
+	require.NoError(t, err)
+	require.Empty(t, shifts)
+}
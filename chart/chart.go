@@ -36,8 +36,18 @@ type Chart struct {
 	Time      timeandzone.TimeAndZone  `json:"time"`
 	ChartType ChartType                `json:"chartType"`
 	Points    []*astropoint.AstroPoint `json:"points"`
-	Aspects   []*aspect.Aspect         `json:"aspects"`
-	Lunation  *lunation.Lunation       `json:"lunations"`
+	// Aspects only have a known Motion in D1 and tropical charts
+	Aspects  []*aspect.Aspect   `json:"aspects"`
+	Lunation *lunation.Lunation `json:"lunations"`
+	// Cusps are the longitudes of the cusps of the 12 houses, in the house
+	// system of the chart's Config. Only D1 and tropical charts have them.
+	Cusps []float64 `json:"cusps,omitempty"`
+	// Ayanamsa is the one the sidereal positions of the chart use, and the
+	// one tropical charts are made sidereal with (e.g., for nakshatras)
+	Ayanamsa wrapper.Ayanamsa `json:"ayanamsa,omitempty"`
+	// Bhavas are the houses of the Bhava Chalit chart, in the bhava system
+	// of the chart's Config. Only D1 charts have them, and only if asked for.
+	Bhavas []*Bhava `json:"bhavas,omitempty"`
 }
 
 func (c *Chart) String() string {
@@ -98,14 +108,53 @@ func NewChartFromJulianDay(
 	calcType ChartType,
 	pointIDs []pointid.PointID,
 ) (*Chart, error) {
+	return NewChartFromJulianDayWithConfig(
+		swe,
+		timeInJulian,
+		lon, lat,
+		calcType,
+		pointIDs,
+		NewDefaultConfig(),
+	)
+}
+
+// NewChartFromJulianDayWithConfig is like NewChartFromJulianDay but lets the
+// caller decide how the chart is cast. If cfg is nil, the default config is
+// used.
+func NewChartFromJulianDayWithConfig(
+	swe *wrapper.SwissEph,
+	timeInJulian float64,
+	lon, lat float64,
+	calcType ChartType,
+	pointIDs []pointid.PointID,
+	cfg *Config,
+) (*Chart, error) {
+	if cfg == nil {
+		cfg = NewDefaultConfig()
+	}
+	aspectConfig := cfg.AspectConfig
+	if aspectConfig == nil {
+		aspectConfig = aspect.NewDefaultConfig()
+	}
+	aspectConfig = aspectConfig.ForChartType(calcType.String())
+	ayanamsa := cfg.Ayanamsa
+	if ayanamsa == "" {
+		ayanamsa = wrapper.DefaultAyanamsa
+	}
+	houseSystem := cfg.HouseSystem
+	if houseSystem == "" {
+		houseSystem = wrapper.HouseSystem_WholeSign
+	}
+
 	// Calculate the ascendant always since we use it to calculate
 	// the houses for all the other points
-	asc, err := CalculateAscendant(
+	asc, err := calculateAscendant(
 		swe,
 		timeInJulian,
 		lon,
 		lat,
 		calcType,
+		ayanamsa,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("while calculating ascendant: %v", err)
@@ -115,6 +164,7 @@ func NewChartFromJulianDay(
 
 	// For each point, calculate the longitude, sign and house
 	didCalculateRahuKetu := false
+	specialIDs := []pointid.PointID{}
 	for _, id := range pointIDs {
 		if id == pointid.Ketu || id == pointid.Rahu {
 			if didCalculateRahuKetu {
@@ -127,6 +177,7 @@ func NewChartFromJulianDay(
 				timeInJulian,
 				calcType,
 				asc.ZodiacalPos,
+				ayanamsa,
 			)
 			if rahu != nil && ketu != nil {
 				didCalculateRahuKetu = true
@@ -136,6 +187,10 @@ func NewChartFromJulianDay(
 		} else if id == pointid.ASC {
 			// Already calculated
 			continue
+		} else if IsSpecialPoint(id) {
+			// Calculated below, once we have all the planets
+			specialIDs = append(specialIDs, id)
+			continue
 		} else {
 			var p *astropoint.AstroPoint
 			p, err = calculatePlanet(
@@ -144,6 +199,7 @@ func NewChartFromJulianDay(
 				id,
 				calcType,
 				asc.ZodiacalPos,
+				ayanamsa,
 			)
 			if p != nil {
 				points = append(points, p)
@@ -155,19 +211,57 @@ func NewChartFromJulianDay(
 		}
 	}
 
-	// For each point, calculate the aspect with other points
+	if len(specialIDs) > 0 {
+		specials, err := calculateSpecialPoints(
+			swe,
+			timeInJulian,
+			lon, lat,
+			calcType,
+			asc,
+			specialIDs,
+			ayanamsa,
+		)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, specials...)
+	}
+
+	// For each pair of points, calculate the aspect between them. Each pair
+	// is only listed once. The special lagnas and upagrahas are left out:
+	// they're not bodies, and have no speed.
 	aspects := []*aspect.Aspect{}
-	for _, p1 := range points {
-		for _, p2 := range points {
-			if p1 == p2 {
+	for i, p1 := range points {
+		if IsSpecialPoint(p1.ID) {
+			continue
+		}
+		for _, p2 := range points[i+1:] {
+			if IsSpecialPoint(p2.ID) {
 				continue
 			}
-			asp := aspect.NewAspect(
-				p1.ID,
-				p1.ZodiacalPos,
-				p2.ID,
-				p2.ZodiacalPos,
-			)
+			// The speeds are D1 speeds. Points don't move linearly in most
+			// varga charts (e.g., the unequal divisions of D30), so the
+			// motion of their aspects is left unknown.
+			var asp *aspect.Aspect
+			if calcType == D1ChartType || calcType == TropicalChartType {
+				asp = aspect.NewAspectWithSpeeds(
+					p1.ID,
+					p1.ZodiacalPos,
+					p1.Speed,
+					p2.ID,
+					p2.ZodiacalPos,
+					p2.Speed,
+					aspectConfig,
+				)
+			} else {
+				asp = aspect.NewAspect(
+					p1.ID,
+					p1.ZodiacalPos,
+					p2.ID,
+					p2.ZodiacalPos,
+					aspectConfig,
+				)
+			}
 			if asp == nil || asp.Type == aspect.AspectType_None {
 				continue
 			}
@@ -182,6 +276,32 @@ func NewChartFromJulianDay(
 		ChartType: calcType,
 		Points:    points,
 		Aspects:   aspects,
+		Ayanamsa:  ayanamsa,
+	}
+	if calcType == D1ChartType || calcType == TropicalChartType {
+		chrt.Cusps, err = swe.Cusps(
+			timeInJulian,
+			lon, lat,
+			calcType == D1ChartType,
+			ayanamsa,
+			houseSystem,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("while calculating cusps: %v", err)
+		}
+	}
+	if calcType == D1ChartType && cfg.BhavaSystem != BhavaSystem_None {
+		chrt.Bhavas, err = calculateBhavas(
+			swe,
+			timeInJulian,
+			lon, lat,
+			asc.Longitude,
+			ayanamsa,
+			cfg.BhavaSystem,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("while calculating bhavas: %v", err)
+		}
 	}
 
 	// Check if the pointIDs includes both the moon and the sun, else we can't
@@ -204,31 +324,66 @@ func NewChartFromJulianDay(
 	return chrt, nil
 }
 
+// CalculateAscendant returns the ascendant of a chart of calcType. Sidereal
+// charts use wrapper.DefaultAyanamsa, like the charts cast with the default
+// config.
 func CalculateAscendant(
 	swe *wrapper.SwissEph,
 	timeInJulian float64,
 	lon, lat float64,
 	calcType ChartType,
+) (*astropoint.AstroPoint, error) {
+	return calculateAscendant(swe, timeInJulian, lon, lat, calcType, "")
+}
+
+// calculateAscendant is like CalculateAscendant, but sidereal charts use
+// ayanamsa (or wrapper.DefaultAyanamsa, if it's empty)
+func calculateAscendant(
+	swe *wrapper.SwissEph,
+	timeInJulian float64,
+	lon, lat float64,
+	calcType ChartType,
+	ayanamsa wrapper.Ayanamsa,
 ) (*astropoint.AstroPoint, error) {
 	// Calculate houses and ascendant
 	cusps := make([]C.double, 13)
 	cuspsPtr := &(cusps[0])
 	ascmc := make([]C.double, 10)
 	ascmcPtr := &(ascmc[0])
+	// swe_houses_ex2 is like swe_houses_ex but also gives us the daily
+	// motion of the ascendant, which we need to know if an aspect to the
+	// ascendant is applying or separating
+	cuspsSpeed := make([]C.double, 13)
+	cuspsSpeedPtr := &(cuspsSpeed[0])
+	ascmcSpeed := make([]C.double, 10)
+	ascmcSpeedPtr := &(ascmcSpeed[0])
+	errBytes := make([]byte, C.AS_MAXCH)
+	errPtr := (*C.char)(C.CBytes(errBytes))
+	defer C.free(unsafe.Pointer(errPtr))
 	var ascZodiacalPos *zodiacalpos.ZodiacalPos
 	if calcType.IsVarga() {
-		if ret := C.swe_houses_ex(
-			C.double(timeInJulian),
-			C.int(C.SEFLG_SIDEREAL),
-			C.double(lat),
-			C.double(lon),
-			// Whole sign
-			C.int('W'),
-			// Output
-			cuspsPtr,
-			ascmcPtr,
-		); ret < 0 {
-			return nil, fmt.Errorf("swe_houses_ex failed")
+		var ret C.int
+		if err := wrapper.WithAyanamsa(ayanamsa, func() {
+			ret = C.swe_houses_ex2(
+				C.double(timeInJulian),
+				C.int(C.SEFLG_SIDEREAL),
+				C.double(lat),
+				C.double(lon),
+				// Whole sign
+				C.int('W'),
+				// Output
+				cuspsPtr,
+				ascmcPtr,
+				cuspsSpeedPtr,
+				ascmcSpeedPtr,
+				errPtr,
+			)
+		}); err != nil {
+			return nil, err
+		}
+		if ret < 0 {
+			return nil, fmt.Errorf("swe_houses_ex2 failed: %s",
+				C.GoString(errPtr))
 		}
 		// Translate the degree to a sign and degree based on
 		// the varga chart type
@@ -244,8 +399,9 @@ func CalculateAscendant(
 		}
 		// ascDeg = 0
 	} else if calcType == TropicalChartType {
-		if ret := C.swe_houses(
+		if ret := C.swe_houses_ex2(
 			C.double(timeInJulian),
+			C.int(0),
 			C.double(lat),
 			C.double(lon),
 			// Whole sign
@@ -253,8 +409,12 @@ func CalculateAscendant(
 			// Output
 			cuspsPtr,
 			ascmcPtr,
+			cuspsSpeedPtr,
+			ascmcSpeedPtr,
+			errPtr,
 		); ret < 0 {
-			return nil, fmt.Errorf("swe_houses_ex failed")
+			return nil, fmt.Errorf("swe_houses_ex2 failed: %s",
+				C.GoString(errPtr))
 		}
 		ascZodiacalPos = zodiacalpos.NewZodiacalPosFromLongitude(float64(ascmc[0]))
 	}
@@ -264,6 +424,7 @@ func CalculateAscendant(
 		Longitude:   float64(ascmc[0]),
 		ZodiacalPos: ascZodiacalPos,
 		House:       house.House1,
+		Speed:       float64(ascmcSpeed[0]),
 	}, nil
 }
 
@@ -272,6 +433,7 @@ func calculateRahuKetu(
 	timeInJulian float64,
 	chartCalcType ChartType,
 	ascendantZodiacalPos *zodiacalpos.ZodiacalPos,
+	ayanamsa wrapper.Ayanamsa,
 ) (*astropoint.AstroPoint, *astropoint.AstroPoint, error) {
 	// XXX <26-01-2024, afjoseph> SwissEph doesn't have a way to
 	// calculate Ketu, but it knows Rahu as C.SE_TRUE_NODE.
@@ -282,6 +444,7 @@ func calculateRahuKetu(
 		pointid.Rahu,
 		chartCalcType,
 		ascendantZodiacalPos,
+		ayanamsa,
 	)
 	if err != nil {
 		return nil, nil, fmt.Errorf("while calculating Rahu: %v", err)
@@ -294,18 +457,22 @@ func calculateRahuKetu(
 		Longitude:   ketuZodPos.AbsDegrees(),
 		ZodiacalPos: ketuZodPos,
 		House:       rahu.House.Opposite(),
+		// The nodes are always opposite each other, so they move together
+		Speed: rahu.Speed,
 	}, nil
 }
 
 // calculatePlanet calculates an astropoint.AstroPoint for a given time and a
 // point. If ascendantZodiacalPos is not nil, it will calculate the house for
-// the point as well.
+// the point as well. Sidereal charts use ayanamsa, or the default one if it's
+// empty.
 func calculatePlanet(
 	swe *wrapper.SwissEph,
 	timeInJulian float64,
 	pid pointid.PointID,
 	chartType ChartType,
 	ascendantZodiacalPos *zodiacalpos.ZodiacalPos,
+	ayanamsa wrapper.Ayanamsa,
 ) (*astropoint.AstroPoint, error) {
 	flag := C.int(0)
 	if chartType.IsVarga() {
@@ -324,22 +491,32 @@ func calculatePlanet(
 	// - speed in dist
 	xx := make([]C.double, 6)
 	xxPtr := &(xx[0])
-	ret := C.swe_calc_ut(
-		// Julian day
-		C.double(timeInJulian),
-		// Planet ID
-		// XXX <19-01-2024, afjoseph> PointID are organized
-		// in the same way swisseph accepts them, so Sun is 0, Moon
-		// is 1, etc.
-		C.int(pid.SwissEphID()),
-		// iflag
-		flag,
-		// C.int(0),
-		// Output
-		xxPtr,
-		// Error
-		errPtr,
-	)
+	var ret C.int
+	calc := func() {
+		ret = C.swe_calc_ut(
+			// Julian day
+			C.double(timeInJulian),
+			// Planet ID
+			// XXX <19-01-2024, afjoseph> PointID are organized
+			// in the same way swisseph accepts them, so Sun is 0, Moon
+			// is 1, etc.
+			C.int(pid.SwissEphID()),
+			// iflag
+			flag,
+			// C.int(0),
+			// Output
+			xxPtr,
+			// Error
+			errPtr,
+		)
+	}
+	if chartType.IsVarga() {
+		if err := wrapper.WithAyanamsa(ayanamsa, calc); err != nil {
+			return nil, err
+		}
+	} else {
+		calc()
+	}
 	if ret < 0 {
 		return nil, fmt.Errorf("swe_calc_ut failed: %s",
 			C.GoString(errPtr))
@@ -365,6 +542,7 @@ func calculatePlanet(
 		ZodiacalPos:  zp,
 		House:        h,
 		IsRetrograde: float64(xx[3]) < 0,
+		Speed:        float64(xx[3]),
 	}
 
 	return p, nil
@@ -388,6 +566,27 @@ func NewChartFromUTC(
 	)
 }
 
+// NewChartFromUTCWithConfig is like NewChartFromUTC but lets the caller decide
+// how the chart is cast
+func NewChartFromUTCWithConfig(
+	swe *wrapper.SwissEph,
+	date time.Time,
+	lon, lat float64,
+	calcType ChartType,
+	pointIDs []pointid.PointID,
+	cfg *Config,
+) (*Chart, error) {
+	return NewChartFromJulianDayWithConfig(
+		swe,
+		swe.GoTimeToJulianDay(date),
+		lon,
+		lat,
+		calcType,
+		pointIDs,
+		cfg,
+	)
+}
+
 func (c *Chart) GetHouseLordFor(
 	h house.House,
 	placementType HouseLordPlacement,
@@ -445,11 +644,24 @@ func (c *Chart) MustGetPoint(id pointid.PointID) *astropoint.AstroPoint {
 	return p
 }
 
+// HasAspectIgnoreDegree reports whether the chart has asp, regardless of the
+// order of its points
 func (c *Chart) HasAspectIgnoreDegree(asp *aspect.Aspect) bool {
 	for _, a := range c.Aspects {
-		if asp.Equals(a, true) {
+		if asp.Equals(a, true) || asp.Equals(a.Reversed(), true) {
 			return true
 		}
 	}
 	return false
 }
+
+// GetAspect returns the aspect between p1 and p2, regardless of their order,
+// or nil if they're not in aspect
+func (c *Chart) GetAspect(p1, p2 pointid.PointID) *aspect.Aspect {
+	for _, a := range c.Aspects {
+		if (a.P1 == p1 && a.P2 == p2) || (a.P1 == p2 && a.P2 == p1) {
+			return a
+		}
+	}
+	return nil
+}
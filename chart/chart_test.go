@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/afjoseph/sacredstar/aspect"
 	"github.com/afjoseph/sacredstar/pointid"
 	"github.com/afjoseph/sacredstar/sign"
 	"github.com/afjoseph/sacredstar/wrapper"
@@ -165,6 +166,46 @@ func TestNewChart(t *testing.T) {
 				pointid.Ketu:    zodiacalpos.NewZodiacalPos(sign.Capricorn, 28, 48),
 			},
 		},
+		{
+			title:     "2024-01-01 D2",
+			inputDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			ChartType: D2ChartType,
+			// London coordinates
+			lon: -0.1278,
+			lat: 51.5074,
+			expectedZodiacalPositions: map[pointid.PointID]*zodiacalpos.ZodiacalPos{
+				pointid.ASC:     zodiacalpos.NewZodiacalPos(sign.Cancer, 25, 44),
+				pointid.Sun:     zodiacalpos.NewZodiacalPos(sign.Cancer, 1, 40),
+				pointid.Moon:    zodiacalpos.NewZodiacalPos(sign.Leo, 23, 36),
+				pointid.Mercury: zodiacalpos.NewZodiacalPos(sign.Leo, 26, 9),
+				pointid.Venus:   zodiacalpos.NewZodiacalPos(sign.Cancer, 16, 49),
+				pointid.Mars:    zodiacalpos.NewZodiacalPos(sign.Leo, 6, 14),
+				pointid.Jupiter: zodiacalpos.NewZodiacalPos(sign.Leo, 22, 45),
+				pointid.Saturn:  zodiacalpos.NewZodiacalPos(sign.Leo, 18, 6),
+				pointid.Rahu:    zodiacalpos.NewZodiacalPos(sign.Leo, 23, 45),
+				pointid.Ketu:    zodiacalpos.NewZodiacalPos(sign.Aquarius, 23, 45),
+			},
+		},
+		{
+			title:     "2024-01-01 D30",
+			inputDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			ChartType: D30ChartType,
+			// London coordinates
+			lon: -0.1278,
+			lat: 51.5074,
+			expectedZodiacalPositions: map[pointid.PointID]*zodiacalpos.ZodiacalPos{
+				pointid.ASC:     zodiacalpos.NewZodiacalPos(sign.Pisces, 3, 15),
+				pointid.Sun:     zodiacalpos.NewZodiacalPos(sign.Sagittarius, 21, 52),
+				pointid.Moon:    zodiacalpos.NewZodiacalPos(sign.Sagittarius, 6, 45),
+				pointid.Mercury: zodiacalpos.NewZodiacalPos(sign.Scorpio, 18, 29),
+				pointid.Venus:   zodiacalpos.NewZodiacalPos(sign.Virgo, 14, 38),
+				pointid.Mars:    zodiacalpos.NewZodiacalPos(sign.Aries, 18, 41),
+				pointid.Jupiter: zodiacalpos.NewZodiacalPos(sign.Sagittarius, 5, 11),
+				pointid.Saturn:  zodiacalpos.NewZodiacalPos(sign.Aquarius, 24, 18),
+				pointid.Rahu:    zodiacalpos.NewZodiacalPos(sign.Scorpio, 11, 17),
+				pointid.Ketu:    zodiacalpos.NewZodiacalPos(sign.Taurus, 11, 16),
+			},
+		},
 		// {
 		// 	title:                "2024-01-01 D24",
 		// 	inputDate:            time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
@@ -337,17 +378,17 @@ func TestNakshatra(t *testing.T) {
 			Magha,
 		},
 	} {
-		ap, err := calculatePlanet(
+		c, err := NewChartFromUTC(
 			swe,
-			swe.GoTimeToJulianDay(tc.inputTime),
-			pointid.Moon,
+			tc.inputTime,
+			-0.1278, 51.5074,
 			D1ChartType,
-			nil,
+			[]pointid.PointID{pointid.Moon},
 		)
 		assert.NoError(t, err)
-		assert.NotNil(t, ap)
+		assert.NotNil(t, c)
 
-		n, err := NewNakshatraFromChart(swe, ap)
+		n, err := NewNakshatraFromChart(swe, c, pointid.Moon)
 		assert.NoError(t, err)
 		// fmt.Printf("For %s, nakshatra = %s, pada = %s\n", tc.inputTime, n, p)
 		assert.Equal(
@@ -357,5 +398,104 @@ func TestNakshatra(t *testing.T) {
 			"for %s",
 			tc.inputTime,
 		)
+
+		// Tropical charts are made sidereal first
+		tropical, err := NewChartFromUTC(
+			swe,
+			tc.inputTime,
+			-0.1278, 51.5074,
+			TropicalChartType,
+			[]pointid.PointID{pointid.Moon},
+		)
+		assert.NoError(t, err)
+		n, err = NewNakshatraFromChart(swe, tropical, pointid.Moon)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expectedNakshatraType, n.Type, "for %s", tc.inputTime)
+	}
+}
+
+func TestChartAspects(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+
+	chartTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c, err := NewChartFromUTC(
+		swe,
+		chartTime,
+		0, 0,
+		TropicalChartType,
+		pointid.ModernPlanets,
+	)
+	assert.NoError(t, err)
+
+	// Each pair is only listed once
+	seen := map[string]bool{}
+	for _, asp := range c.Aspects {
+		key := asp.P1.String() + "-" + asp.P2.String()
+		reversedKey := asp.P2.String() + "-" + asp.P1.String()
+		assert.False(t, seen[reversedKey], "duplicate aspect %s", asp)
+		seen[key] = true
 	}
+
+	// The Moon is trine Uranus and moving away from it: the trine perfected
+	// on 2024-12-31 at around 23:30 UTC
+	asp := c.GetAspect(pointid.Uranus, pointid.Moon)
+	assert.NotNil(t, asp)
+	assert.Equal(t, aspect.AspectType_Trine, asp.Type)
+	assert.True(t, asp.IsSeparating())
+	assert.InDelta(t, 0.27, asp.Deviation, 0.02)
+
+	estimate, ok := asp.PerfectionTime(chartTime)
+	assert.True(t, ok)
+	exact, err := c.FindAspectPerfection(swe, asp)
+	assert.NoError(t, err)
+	assert.True(t, exact.Before(chartTime))
+	assert.InDelta(t, 0, exact.Sub(estimate).Hours(), 1)
+
+	// Points don't move linearly in the varga charts, so the motion of
+	// their aspects is unknown
+	for _, ct := range []ChartType{D2ChartType, D9ChartType, D30ChartType} {
+		varga, err := NewChartFromUTC(swe, chartTime, 0, 0, ct, pointid.VedicPlanets)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, varga.Aspects, ct)
+		for _, asp := range varga.Aspects {
+			assert.Equal(t, aspect.AspectMotion_Unknown, asp.Motion, asp.String())
+		}
+	}
+}
+
+func TestChartAyanamsa(t *testing.T) {
+	b := wrapper.NewWithBuiltinPath()
+	defer b.Close()
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jd := b.GoTimeToJulianDay(date)
+	// London coordinates
+	lon, lat := -0.1278, 51.5074
+
+	// Charts cast with the default config use the same ayanamsa as the
+	// sidereal calculations that don't ask for one
+	d1, err := NewChartFromUTC(b, date, lon, lat, D1ChartType, pointid.VedicPlanets)
+	assert.NoError(t, err)
+	assert.Equal(t, wrapper.DefaultAyanamsa, d1.Ayanamsa)
+	assert.InDelta(t, 255.848, d1.MustGetPoint(pointid.Sun).Longitude, 0.001)
+	sunLon, _, err := b.CalcUT(jd, pointid.Sun.SwissEphID(), true)
+	assert.NoError(t, err)
+	assert.InDelta(t, sunLon, d1.MustGetPoint(pointid.Sun).Longitude, 1e-9)
+	asc, err := CalculateAscendant(b, jd, lon, lat, D1ChartType)
+	assert.NoError(t, err)
+	assert.InDelta(t, asc.Longitude, d1.MustGetPoint(pointid.ASC).Longitude, 1e-9)
+
+	// Tropical charts are made sidereal with their own ayanamsa
+	cfg := NewDefaultConfig()
+	cfg.Ayanamsa = wrapper.Ayanamsa_Raman
+	tropical, err := NewChartFromUTCWithConfig(b, date, lon, lat, TropicalChartType, pointid.VedicPlanets, cfg)
+	assert.NoError(t, err)
+	raman, err := NewChartFromUTCWithConfig(b, date, lon, lat, D1ChartType, pointid.VedicPlanets, cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, wrapper.Ayanamsa_Raman, raman.Ayanamsa)
+	lonFromTropical, err := tropical.siderealLongitude(b, pointid.Sun)
+	assert.NoError(t, err)
+	// They differ by the nutation, which sidereal positions don't have
+	assert.InDelta(t, raman.MustGetPoint(pointid.Sun).Longitude, lonFromTropical, 0.01)
+	assert.Greater(t, raman.MustGetPoint(pointid.Sun).Longitude-d1.MustGetPoint(pointid.Sun).Longitude, 1.0)
 }
@@ -0,0 +1,33 @@
+package chart
+
+import (
+	"github.com/afjoseph/sacredstar/aspect"
+	"github.com/afjoseph/sacredstar/wrapper"
+)
+
+// Config holds the options used while casting a chart
+type Config struct {
+	// AspectConfig decides which aspects are calculated between the points
+	// of the chart. Per-chart-type overrides are honored (see
+	// aspect.Config.ForChartType).
+	AspectConfig *aspect.Config `json:"aspectConfig"`
+	// Ayanamsa is used for the sidereal positions of varga charts. If empty,
+	// it's wrapper.DefaultAyanamsa.
+	Ayanamsa wrapper.Ayanamsa `json:"ayanamsa"`
+	// HouseSystem decides the cusps of D1 and tropical charts (see
+	// Chart.Cusps). If empty, it's whole sign. The houses of the points are
+	// always whole sign.
+	HouseSystem wrapper.HouseSystem `json:"houseSystem"`
+	// BhavaSystem, if set, gives D1 charts a Bhava Chalit chart (see
+	// Chart.Bhavas) as an alternative to their whole sign houses
+	BhavaSystem BhavaSystem `json:"bhavaSystem"`
+}
+
+// NewDefaultConfig returns the config NewChartFromJulianDay uses
+func NewDefaultConfig() *Config {
+	return &Config{
+		AspectConfig: aspect.NewDefaultConfig(),
+		Ayanamsa:     wrapper.DefaultAyanamsa,
+		HouseSystem:  wrapper.HouseSystem_WholeSign,
+	}
+}
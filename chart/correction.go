@@ -16,6 +16,7 @@ func correctJulianDateToZodiacalPos(
 	pid pointid.PointID,
 	targetZodPos *zodiacalpos.ZodiacalPos,
 	targetNakshatraType NakshatraType,
+	ayanamsa wrapper.Ayanamsa,
 	step float64,
 	_range float64,
 ) (float64, error) {
@@ -28,6 +29,7 @@ func correctJulianDateToZodiacalPos(
 			// ascendantZodiacalPos: we don't need to provide it since we're
 			// not calculating houses here
 			nil,
+			ayanamsa,
 		)
 		if err != nil {
 			return 0, false, nil, fmt.Errorf(
@@ -54,6 +54,7 @@ func TestCorrectJulianDateToZodiacalPos(t *testing.T) {
 				tc.pid,
 				tc.targetZodPos,
 				tc.targetNakType,
+				wrapper.Ayanamsa_Lahiri,
 				tc.step,
 				tc._range,
 			)
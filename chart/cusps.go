@@ -0,0 +1,39 @@
+package chart
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+)
+
+// HouseFromCusps returns the house the longitude lon is in, given the
+// longitudes of the 12 cusps: a house goes from its cusp to the next one
+func HouseFromCusps(cusps []float64, lon float64) (house.House, error) {
+	if len(cusps) != 12 {
+		return house.HouseNone, fmt.Errorf("need 12 cusps, got %d", len(cusps))
+	}
+	for i := range cusps {
+		start, end := cusps[i], cusps[(i+1)%12]
+		size := math.Mod(end-start+360, 360)
+		if math.Mod(lon-start+360, 360) < size {
+			return house.HouseFromInt(i + 1)
+		}
+	}
+	// Can only happen through rounding, right at a cusp
+	return house.HouseFromInt(12)
+}
+
+// GetCuspHouseFor returns the house of id by the cusps of c, instead of by
+// whole signs like AstroPoint.House
+func (c *Chart) GetCuspHouseFor(id pointid.PointID) (house.House, error) {
+	if c.Cusps == nil {
+		return house.HouseNone, fmt.Errorf("%s chart has no cusps", c.ChartType)
+	}
+	p := c.GetPoint(id)
+	if p == nil {
+		return house.HouseNone, fmt.Errorf("%s not found in chart", id)
+	}
+	return HouseFromCusps(c.Cusps, p.Longitude)
+}
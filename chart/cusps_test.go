@@ -0,0 +1,27 @@
+package chart
+
+import (
+	"testing"
+
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHouseFromCusps(t *testing.T) {
+	// Unequal houses, with the 7th crossing 0° Aries
+	cusps := []float64{163, 188, 219, 255, 291, 320, 343, 8, 39, 75, 111, 140}
+	for lon, expected := range map[float64]house.House{
+		163: house.House1,
+		187: house.House1,
+		350: house.House7,
+		2:   house.House7,
+		8:   house.House8,
+		150: house.House12,
+	} {
+		h, err := HouseFromCusps(cusps, lon)
+		require.NoError(t, err)
+		require.Equal(t, expected, h, lon)
+	}
+	_, err := HouseFromCusps(cusps[:11], 0)
+	require.Error(t, err)
+}
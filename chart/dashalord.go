@@ -141,6 +141,15 @@ func (dl DashaLord) Next() DashaLord {
 	return DashaLord(i)
 }
 
+func (dl DashaLord) Previous() DashaLord {
+	i := dl.Int()
+	i--
+	if i < 0 {
+		i = TotalDashaLords - 1
+	}
+	return DashaLord(i)
+}
+
 func parseDuration(year, mon, day int) time.Duration {
 	d := year * 8760 // Hours in a calendar year
 	d += mon * 730   // Hours in a calendar month
@@ -363,6 +372,19 @@ func NextAntardasha(mdl DashaLord, adl DashaLord) (DashaLord, DashaLord) {
 	return mdl, adl.Next()
 }
 
+func PreviousAntardasha(mdl DashaLord, adl DashaLord) (DashaLord, DashaLord) {
+	sisters := mdl.GetAntardashas()
+	// If this is the first antardasha, return the previous mahadasha and its
+	// last antardasha
+	if adl == sisters[0] {
+		mdl = mdl.Previous()
+		sisters = mdl.GetAntardashas()
+		return mdl, sisters[len(sisters)-1]
+	}
+	// Else, return the same mahadasha and the previous antardasha
+	return mdl, adl.Previous()
+}
+
 func (dl DashaLord) GetAntardashas() []DashaLord {
 	switch dl {
 	case DashaLordKetu:
@@ -0,0 +1,198 @@
+package chart
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/wrapper"
+)
+
+// DashaYearLength is the length of a "year" in the Vimshottari tables. The
+// texts don't agree on which year is meant, so it's left to the caller.
+type DashaYearLength string
+
+const (
+	// DashaYearLength_Calendar is a 365-day year with 30.4-day months, which
+	// is what the Vimshottari tables here are written in (see
+	// parseDuration). It's the default.
+	DashaYearLength_Calendar DashaYearLength = "calendar"
+	// DashaYearLength_Solar is the 365.25-day Julian year
+	DashaYearLength_Solar DashaYearLength = "solar"
+	// DashaYearLength_Savana is a 360-day year (12 months of 30 days)
+	DashaYearLength_Savana DashaYearLength = "savana"
+	// DashaYearLength_Sidereal is the time the Sun takes to come back to the
+	// same star (365.256363 days)
+	DashaYearLength_Sidereal DashaYearLength = "sidereal"
+)
+
+func (yl DashaYearLength) String() string {
+	return string(yl)
+}
+
+// Days returns the length of the year in days
+func (yl DashaYearLength) Days() float64 {
+	switch yl {
+	case DashaYearLength_Calendar, "":
+		return parseDuration(1, 0, 0).Hours() / 24
+	case DashaYearLength_Solar:
+		return 365.25
+	case DashaYearLength_Savana:
+		return 360
+	case DashaYearLength_Sidereal:
+		return 365.256363
+	}
+	panic(fmt.Sprintf("unknown dasha year length: %s", yl))
+}
+
+// scale converts a duration from the Vimshottari tables (which are in
+// calendar years) to this year length
+func (yl DashaYearLength) scale(d time.Duration) time.Duration {
+	if yl == DashaYearLength_Calendar || yl == "" {
+		return d
+	}
+	return time.Duration(float64(d) * yl.Days() / DashaYearLength_Calendar.Days())
+}
+
+// DashaBalanceMethod decides how much of the Moon's nakshatra (and so of the
+// first mahadasha) is left at birth
+type DashaBalanceMethod int
+
+const (
+	// DashaBalanceMethod_NakshatraTiming finds when the Moon entered and will
+	// leave its nakshatra, and uses the fraction of that time that's left.
+	// Since the Moon's speed varies, this is not the same as the fraction of
+	// degrees. It's the default.
+	DashaBalanceMethod_NakshatraTiming DashaBalanceMethod = iota
+	// DashaBalanceMethod_LongitudeProportion uses the fraction of the
+	// nakshatra's 13°20' that the Moon still has to traverse. This is the
+	// classical method and needs no ephemeris lookups.
+	DashaBalanceMethod_LongitudeProportion
+)
+
+func (bm DashaBalanceMethod) String() string {
+	switch bm {
+	case DashaBalanceMethod_NakshatraTiming:
+		return "nakshatra-timing"
+	case DashaBalanceMethod_LongitudeProportion:
+		return "longitude-proportion"
+	}
+	return "unknown"
+}
+
+// DashaOptions are the knobs of the Vimshottari calculation
+type DashaOptions struct {
+	YearLength    DashaYearLength    `json:"yearLength"`
+	BalanceMethod DashaBalanceMethod `json:"balanceMethod"`
+}
+
+// NewDefaultDashaOptions returns the options NewDashaTree uses
+func NewDefaultDashaOptions() *DashaOptions {
+	return &DashaOptions{
+		YearLength:    DashaYearLength_Calendar,
+		BalanceMethod: DashaBalanceMethod_NakshatraTiming,
+	}
+}
+
+// DashaBalance is the Vimshottari dasha running at birth
+type DashaBalance struct {
+	Nakshatra  NakshatraType `json:"nakshatra"`
+	Mahadasha  DashaLord     `json:"mahadasha"`
+	Antardasha DashaLord     `json:"antardasha"`
+	// NakshatraRemaining is the fraction (from 0 to 1) of the nakshatra the
+	// Moon still has to traverse, which is also the fraction of the
+	// mahadasha that's left
+	NakshatraRemaining float64 `json:"nakshatraRemaining"`
+	// MahadashaRemaining and AntardashaRemaining are how long the periods
+	// still run after birth
+	MahadashaRemaining  time.Duration `json:"mahadashaRemaining"`
+	AntardashaRemaining time.Duration `json:"antardashaRemaining"`
+	// MahadashaStart and AntardashaStart are when the periods started,
+	// before birth
+	MahadashaStart  time.Time `json:"mahadashaStart"`
+	AntardashaStart time.Time `json:"antardashaStart"`
+}
+
+func (db *DashaBalance) String() string {
+	return fmt.Sprintf(
+		"DashaBalance{Nakshatra: %s, Mahadasha: %s, Antardasha: %s, MahadashaRemaining: %v, AntardashaRemaining: %v}",
+		db.Nakshatra,
+		db.Mahadasha,
+		db.Antardasha,
+		db.MahadashaRemaining,
+		db.AntardashaRemaining,
+	)
+}
+
+// CalculateDashaBalance returns the Vimshottari dasha running at birthTime and
+// how much of it is left. opts can be nil to use the defaults.
+func CalculateDashaBalance(
+	swe *wrapper.SwissEph,
+	birthTime time.Time,
+	natal *Chart,
+	opts *DashaOptions,
+) (*DashaBalance, error) {
+	if opts == nil {
+		opts = NewDefaultDashaOptions()
+	}
+	nak, percRemaining, err := calculateMoonNakshatraBalance(
+		swe,
+		birthTime,
+		natal,
+		opts.BalanceMethod,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("while calculating Nakshatra: %v", err)
+	}
+	mdl, adl, adRemaining := nak.GetDashaLordPair(birthTime, percRemaining)
+	adRemaining = opts.YearLength.scale(adRemaining)
+	adStart := birthTime.Add(
+		adRemaining - opts.YearLength.scale(AntardashaDuration(mdl, adl)),
+	)
+	mdStart := adStart
+	for _, sister := range mdl.GetAntardashas() {
+		if sister == adl {
+			break
+		}
+		mdStart = mdStart.Add(-opts.YearLength.scale(AntardashaDuration(mdl, sister)))
+	}
+	mdEnd := mdStart.Add(opts.YearLength.scale(mdl.MahadashaDuration()))
+	return &DashaBalance{
+		Nakshatra:           nak.Type,
+		Mahadasha:           mdl,
+		Antardasha:          adl,
+		NakshatraRemaining:  percRemaining,
+		MahadashaRemaining:  mdEnd.Sub(birthTime),
+		AntardashaRemaining: adRemaining,
+		MahadashaStart:      mdStart,
+		AntardashaStart:     adStart,
+	}, nil
+}
+
+// calculateMoonNakshatraBalance is calculateMoonNakshatraRemaining with a
+// choice of method
+func calculateMoonNakshatraBalance(
+	swe *wrapper.SwissEph,
+	birthTime time.Time,
+	natal *Chart,
+	method DashaBalanceMethod,
+) (*Nakshatra, float64, error) {
+	switch method {
+	case DashaBalanceMethod_NakshatraTiming:
+		return calculateMoonNakshatraRemaining(swe, birthTime, natal)
+	case DashaBalanceMethod_LongitudeProportion:
+		nak, err := NewNakshatraFromChart(swe, natal, pointid.Moon)
+		if err != nil {
+			return nil, 0, fmt.Errorf("while calculating Nakshatra: %v", err)
+		}
+		moonLon, err := natal.siderealLongitude(swe, pointid.Moon)
+		if err != nil {
+			return nil, 0, fmt.Errorf("while getting Moon longitude: %v", err)
+		}
+		nakshatraSpan := 360.0 / 27.0
+		elapsed := math.Mod(moonLon, nakshatraSpan) / nakshatraSpan
+		return nak, 1 - elapsed, nil
+	}
+	return nil, 0, fmt.Errorf("unknown dasha balance method: %s", method)
+}
@@ -0,0 +1,124 @@
+package chart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashaOptions(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+
+	// London, 2024-01-01 00:00 UTC: Ketu/Mercury is running at birth
+	birthTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d1, err := NewChartFromUTC(
+		swe, birthTime, -0.1278, 51.5074, D1ChartType, pointid.VedicPlanets)
+	require.NoError(t, err)
+
+	t.Run("balance", func(t *testing.T) {
+		byTiming, err := CalculateDashaBalance(swe, birthTime, d1, nil)
+		require.NoError(t, err)
+		require.Equal(t, Magha, byTiming.Nakshatra)
+		require.Equal(t, DashaLordKetu, byTiming.Mahadasha)
+		require.Equal(t, DashaLordMercury, byTiming.Antardasha)
+		require.Equal(t,
+			byTiming.MahadashaStart.Add(DashaLordKetu.MahadashaDuration()),
+			birthTime.Add(byTiming.MahadashaRemaining))
+		require.True(t, byTiming.AntardashaStart.Before(birthTime))
+		require.True(t, byTiming.MahadashaStart.Before(byTiming.AntardashaStart))
+
+		// The Moon's speed barely changes within a nakshatra, so both
+		// methods should be close
+		byLongitude, err := CalculateDashaBalance(swe, birthTime, d1, &DashaOptions{
+			YearLength:    DashaYearLength_Calendar,
+			BalanceMethod: DashaBalanceMethod_LongitudeProportion,
+		})
+		require.NoError(t, err)
+		require.Equal(t, DashaLordKetu, byLongitude.Mahadasha)
+		require.InDelta(t,
+			byTiming.NakshatraRemaining,
+			byLongitude.NakshatraRemaining,
+			0.02)
+
+		// A tropical chart gives the same balance, with both methods
+		tropical, err := NewChartFromUTC(
+			swe, birthTime, -0.1278, 51.5074, TropicalChartType, pointid.VedicPlanets)
+		require.NoError(t, err)
+		fromTropical, err := CalculateDashaBalance(swe, birthTime, tropical, nil)
+		require.NoError(t, err)
+		require.Equal(t, byTiming.Nakshatra, fromTropical.Nakshatra)
+		require.Equal(t, byTiming.Antardasha, fromTropical.Antardasha)
+		require.InDelta(t,
+			byTiming.NakshatraRemaining, fromTropical.NakshatraRemaining, 1e-3)
+		fromTropical, err = CalculateDashaBalance(swe, birthTime, tropical, &DashaOptions{
+			YearLength:    DashaYearLength_Calendar,
+			BalanceMethod: DashaBalanceMethod_LongitudeProportion,
+		})
+		require.NoError(t, err)
+		require.InDelta(t,
+			byLongitude.NakshatraRemaining, fromTropical.NakshatraRemaining, 1e-3)
+	})
+
+	t.Run("year length", func(t *testing.T) {
+		dt, err := NewDashaTree(swe, birthTime, d1)
+		require.NoError(t, err)
+		defaultDt, err := NewDashaTreeWithOptions(swe, birthTime, d1, nil)
+		require.NoError(t, err)
+		require.Equal(t, dt.Dashas(), defaultDt.Dashas())
+		require.Equal(t, DashaYearLength_Calendar, dt.YearLength())
+
+		savana, err := NewDashaTreeWithOptions(swe, birthTime, d1, &DashaOptions{
+			YearLength:    DashaYearLength_Savana,
+			BalanceMethod: DashaBalanceMethod_NakshatraTiming,
+		})
+		require.NoError(t, err)
+		mds := savana.Mahadashas()
+		require.Equal(t, DashaLordVenus, mds[1].Lord())
+		require.Equal(t,
+			20*360*24*time.Hour,
+			mds[1].Interval.End.Sub(mds[1].Interval.Start.Time))
+		// Savana years are shorter, so Venus starts earlier
+		require.True(t, mds[1].Interval.Start.Before(dt.Mahadashas()[1].Interval.Start.Time))
+
+		// The year length survives serialization
+		b, err := savana.MarshalJSON()
+		require.NoError(t, err)
+		var unmarshalled DashaTree
+		require.NoError(t, unmarshalled.UnmarshalJSON(b))
+		require.Equal(t, DashaYearLength_Savana, unmarshalled.YearLength())
+	})
+
+	t.Run("sandhi", func(t *testing.T) {
+		dt, err := NewDashaTree(swe, birthTime, d1)
+		require.NoError(t, err)
+		mds := dt.Mahadashas()
+		boundary := mds[1].Interval.Start.Time
+
+		s, didFind := GetDashaSandhiForTime(
+			dt, boundary, DashaLevel_Mahadasha, DefaultDashaSandhiFraction)
+		require.True(t, didFind)
+		require.Equal(t, DashaLordKetu, s.From.Lord())
+		require.Equal(t, DashaLordVenus, s.To.Lord())
+		// 10% of Ketu's 7 years before, 10% of Venus' 20 years after
+		require.Equal(t,
+			boundary.Add(-yearsToDuration(0.7)).Truncate(time.Minute),
+			s.Window.Start.Time)
+		require.Equal(t,
+			boundary.Add(yearsToDuration(2)).Truncate(time.Minute),
+			s.Window.End.Time)
+
+		_, didFind = GetDashaSandhiForTime(
+			dt, boundary.Add(yearsToDuration(5)), DashaLevel_Mahadasha, DefaultDashaSandhiFraction)
+		require.False(t, didFind)
+
+		sandhis := DashaSandhis(
+			dt, birthTime, birthTime.Add(yearsToDuration(25)),
+			DashaLevel_Mahadasha, DefaultDashaSandhiFraction)
+		require.Len(t, sandhis, 2)
+		require.Equal(t, DashaLordSun, sandhis[1].To.Lord())
+	})
+}
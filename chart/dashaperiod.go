@@ -0,0 +1,232 @@
+package chart
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hako/durafmt"
+)
+
+// DashaLevel is the depth of a period in a dasha system. Each period is
+// divided into sub-periods (one per lord) in proportion to the mahadasha
+// years of each lord, starting from the lord of the period itself.
+type DashaLevel int
+
+const (
+	DashaLevel_Mahadasha DashaLevel = iota + 1
+	DashaLevel_Antardasha
+	DashaLevel_Pratyantardasha
+	DashaLevel_Sookshma
+	DashaLevel_Prana
+
+	// TotalVimshottariYears is the length of a full Vimshottari cycle
+	TotalVimshottariYears = 120
+)
+
+func (dl DashaLevel) String() string {
+	switch dl {
+	case DashaLevel_Mahadasha:
+		return "mahadasha"
+	case DashaLevel_Antardasha:
+		return "antardasha"
+	case DashaLevel_Pratyantardasha:
+		return "pratyantardasha"
+	case DashaLevel_Sookshma:
+		return "sookshma"
+	case DashaLevel_Prana:
+		return "prana"
+	}
+	return "unknown"
+}
+
+func (dl DashaLevel) Int() int {
+	return int(dl)
+}
+
+// DashaPeriod is a period at any level of a dasha timeline
+type DashaPeriod struct {
+	System DashaSystem `json:"system"`
+	Level  DashaLevel  `json:"level"`
+	// Lords are the lords of this period and all its parents, starting from
+	// the mahadasha lord
+	Lords []DashaLord `json:"lords"`
+	// Interval is when the period is active. The periods running at birth
+	// start at the birth time, even though they started before it.
+	Interval Interval `json:"interval"`
+
+	// start and duration are the theoretical start and duration of the
+	// period: they are not clipped at the birth time and are used to divide
+	// the period into sub-periods
+	start    time.Time
+	duration time.Duration
+}
+
+func (p DashaPeriod) String() string {
+	lords := []string{}
+	for _, l := range p.Lords {
+		lords = append(lords, l.String())
+	}
+	return fmt.Sprintf(
+		"DashaPeriod{Level: %s, Lords: %s, Interval: %+v, Duration: %+v}",
+		p.Level,
+		strings.Join(lords, "/"),
+		p.Interval,
+		durafmt.Parse(p.Interval.End.Sub(p.Interval.Start.Time)),
+	)
+}
+
+// Lord returns the lord of this period (i.e., the last of Lords)
+func (p DashaPeriod) Lord() DashaLord {
+	return p.Lords[len(p.Lords)-1]
+}
+
+// Contains reports whether t is within the period. The start is inclusive and
+// the end is exclusive, so that every moment belongs to exactly one period
+// at each level.
+func (p DashaPeriod) Contains(t time.Time) bool {
+	return !t.Before(p.Interval.Start.Time) && t.Before(p.Interval.End.Time)
+}
+
+// Overlaps reports whether the period overlaps [start, end)
+func (p DashaPeriod) Overlaps(start, end time.Time) bool {
+	return p.Interval.Start.Before(end) && p.Interval.End.After(start)
+}
+
+// subPeriods divides the period into its sub-periods (one per lord of the
+// system), dropping the ones that ended before notBefore and clipping the one
+// running at notBefore
+func (p DashaPeriod) subPeriods(notBefore time.Time) []DashaPeriod {
+	ret := []DashaPeriod{}
+	start := p.start
+	lord := p.Lord()
+	totalLords := len(p.System.Lords())
+	for i := 0; i < totalLords; i++ {
+		duration := time.Duration(
+			float64(p.duration) *
+				p.System.Years(lord) /
+				p.System.TotalYears(),
+		)
+		end := start.Add(duration)
+		if i == totalLords-1 {
+			// Avoid leaving a gap because of rounding
+			end = p.start.Add(p.duration)
+		}
+		if end.After(notBefore) {
+			lords := append([]DashaLord{}, p.Lords...)
+			clippedStart := start
+			if clippedStart.Before(notBefore) {
+				clippedStart = notBefore
+			}
+			ret = append(ret, DashaPeriod{
+				System:   p.System,
+				Level:    p.Level + 1,
+				Lords:    append(lords, lord),
+				Interval: NewInterval(clippedStart, end),
+				start:    start,
+				duration: duration,
+			})
+		}
+		start = end
+		lord = p.System.Next(lord)
+	}
+	return ret
+}
+
+// isFirst reports whether d is the first period of the tree, which might be
+// clipped
+func (dt *DashaTree) isFirst(d Dasha) bool {
+	first, ok := dt.tree.Min()
+	return ok && first.Interval.Start.Equal(d.Interval.Start.Time)
+}
+
+// antardashaPeriod converts an entry of the tree to an antardasha period
+func (dt *DashaTree) antardashaPeriod(d Dasha) DashaPeriod {
+	start := d.Interval.Start.Time
+	if dt.isFirst(d) {
+		start = dt.firstAntardashaStart
+	}
+	return DashaPeriod{
+		System:   DashaSystem_Vimshottari,
+		Level:    DashaLevel_Antardasha,
+		Lords:    []DashaLord{d.Mahadasha, d.Antardasha},
+		Interval: d.Interval,
+		start:    start,
+		duration: dt.antardashaDuration(d.Mahadasha, d.Antardasha),
+	}
+}
+
+// Mahadashas returns the mahadashas covered by the tree, in order
+func (dt *DashaTree) Mahadashas() []DashaPeriod {
+	ret := []DashaPeriod{}
+	dt.tree.Scan(func(d Dasha) bool {
+		if len(ret) != 0 && ret[len(ret)-1].Lord() == d.Mahadasha {
+			ret[len(ret)-1].Interval.End = d.Interval.End
+			return true
+		}
+		start := d.Interval.Start.Time
+		if dt.isFirst(d) {
+			start = dt.firstMahadashaStart
+		}
+		ret = append(ret, DashaPeriod{
+			System:   DashaSystem_Vimshottari,
+			Level:    DashaLevel_Mahadasha,
+			Lords:    []DashaLord{d.Mahadasha},
+			Interval: d.Interval,
+			start:    start,
+			duration: dt.mahadashaDuration(d.Mahadasha),
+		})
+		return true
+	})
+	return ret
+}
+
+// Children returns the sub-periods of p, in order. Periods at the prana
+// level have no children.
+func (dt *DashaTree) Children(p DashaPeriod) []DashaPeriod {
+	switch p.Level {
+	case DashaLevel_Mahadasha:
+		ret := []DashaPeriod{}
+		dt.tree.Scan(func(d Dasha) bool {
+			if d.Mahadasha == p.Lord() && p.Contains(d.Interval.Start.Time) {
+				ret = append(ret, dt.antardashaPeriod(d))
+			}
+			return true
+		})
+		return ret
+	case DashaLevel_Prana:
+		return nil
+	}
+	return p.subPeriods(dt.startTime)
+}
+
+// System returns DashaSystem_Vimshottari
+func (dt *DashaTree) System() DashaSystem {
+	return DashaSystem_Vimshottari
+}
+
+// GetPeriodsForTime returns the active periods at t, from the mahadasha down
+// to the given level (inclusive)
+func (dt *DashaTree) GetPeriodsForTime(
+	t time.Time,
+	level DashaLevel,
+) ([]DashaPeriod, bool) {
+	return periodsForTime(dt, t, level)
+}
+
+// GetPeriodForTime returns the active period at t at the given level
+func (dt *DashaTree) GetPeriodForTime(
+	t time.Time,
+	level DashaLevel,
+) (DashaPeriod, bool) {
+	return periodForTime(dt, t, level)
+}
+
+// GetPeriodsInRange returns the periods at the given level that overlap
+// [start, end), in order
+func (dt *DashaTree) GetPeriodsInRange(
+	start, end time.Time,
+	level DashaLevel,
+) []DashaPeriod {
+	return periodsInRange(dt, start, end, level)
+}
@@ -0,0 +1,156 @@
+package chart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDashaTree(t *testing.T, birthTime time.Time) *DashaTree {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+	// London coordinates
+	natal, err := NewChartFromUTC(
+		swe, birthTime, -0.1278, 51.5074, D1ChartType, pointid.VedicPlanets)
+	require.NoError(t, err)
+	dt, err := NewDashaTree(swe, birthTime, natal)
+	require.NoError(t, err)
+	return dt
+}
+
+func TestDashaTree_GetPeriodsForTime(t *testing.T) {
+	birthTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dt := newTestDashaTree(t, birthTime)
+
+	type testCase struct {
+		title         string
+		t             time.Time
+		expectedLords []DashaLord
+	}
+	for _, tc := range []testCase{
+		{
+			// Birth is in the Ketu/Mercury antardasha, whose Mercury
+			// pratyantardasha ended before birth
+			title: "at birth",
+			t:     birthTime,
+			expectedLords: []DashaLord{
+				DashaLordKetu,
+				DashaLordMercury,
+				DashaLordKetu,
+				DashaLordSaturn,
+				DashaLordMercury,
+			},
+		},
+		{
+			title: "start of venus mahadasha",
+			t:     time.Date(2024, 10, 22, 0, 0, 0, 0, time.UTC),
+			expectedLords: []DashaLord{
+				DashaLordVenus,
+				DashaLordVenus,
+				DashaLordVenus,
+				DashaLordVenus,
+				DashaLordVenus,
+			},
+		},
+		{
+			title: "later in ketu/mercury",
+			t:     time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			expectedLords: []DashaLord{
+				DashaLordKetu,
+				DashaLordMercury,
+				DashaLordMoon,
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			periods, didFind := dt.GetPeriodsForTime(
+				tc.t,
+				DashaLevel(len(tc.expectedLords)),
+			)
+			require.True(t, didFind)
+			require.Len(t, periods, len(tc.expectedLords))
+			for i, p := range periods {
+				require.Equal(t, DashaLevel(i+1), p.Level)
+				require.Equal(t, tc.expectedLords[:i+1], p.Lords)
+				require.True(t, p.Contains(tc.t))
+			}
+
+			// The first two levels match the MD/AD pairs of the tree
+			d, didFind := dt.GetDashaForTime(tc.t.Add(time.Minute))
+			require.True(t, didFind)
+			require.Equal(t, d.Mahadasha, periods[0].Lord())
+			require.Equal(t, d.Antardasha, periods[1].Lord())
+		})
+	}
+
+	_, didFind := dt.GetPeriodForTime(
+		birthTime.Add(-time.Hour),
+		DashaLevel_Antardasha,
+	)
+	require.False(t, didFind)
+}
+
+func TestDashaTree_Children(t *testing.T) {
+	birthTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dt := newTestDashaTree(t, birthTime)
+
+	// Walk down the Venus mahadasha, which starts after birth, and check
+	// that the children of each period cover it exactly
+	venus := dt.Mahadashas()[1]
+	require.Equal(t, DashaLordVenus, venus.Lord())
+	require.Equal(t, 20*365*24*time.Hour,
+		venus.Interval.End.Sub(venus.Interval.Start.Time))
+
+	p := venus
+	for level := DashaLevel_Antardasha; level <= DashaLevel_Prana; level++ {
+		children := dt.Children(p)
+		require.Len(t, children, TotalDashaLords)
+		require.Equal(t, p.Interval.Start, children[0].Interval.Start)
+		for i := 1; i < len(children); i++ {
+			require.Equal(t,
+				children[i-1].Interval.End,
+				children[i].Interval.Start,
+			)
+		}
+		// The sub-periods start from the lord of the period
+		require.Equal(t, p.Lord(), children[0].Lord())
+		require.Equal(t, p.Lord().Next(), children[1].Lord())
+		if level == DashaLevel_Antardasha {
+			// Antardashas come from the tree and follow its own table
+			require.InDelta(t,
+				float64(p.Interval.End.UnixNano()),
+				float64(children[len(children)-1].Interval.End.UnixNano()),
+				float64(30*24*time.Hour),
+			)
+		} else {
+			require.Equal(t,
+				p.Interval.End,
+				children[len(children)-1].Interval.End,
+			)
+		}
+		p = children[0]
+	}
+	require.Empty(t, dt.Children(p))
+
+	// Venus/Venus/Venus is 20/120 of Venus/Venus (3 years and 4 months)
+	vvv, didFind := dt.GetPeriodForTime(
+		venus.Interval.Start.Time,
+		DashaLevel_Pratyantardasha,
+	)
+	require.True(t, didFind)
+	require.Equal(t,
+		AntardashaDuration(DashaLordVenus, DashaLordVenus)/6,
+		vvv.Interval.End.Sub(vvv.Interval.Start.Time),
+	)
+
+	// Periods running at birth are clipped to it
+	ad, didFind := dt.GetPeriodForTime(birthTime, DashaLevel_Antardasha)
+	require.True(t, didFind)
+	children := dt.Children(ad)
+	require.Equal(t, birthTime, children[0].Interval.Start.Time)
+	require.Equal(t, DashaLordKetu, children[0].Lord())
+	require.Len(t, children, TotalDashaLords-1)
+}
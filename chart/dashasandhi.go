@@ -0,0 +1,102 @@
+package chart
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultDashaSandhiFraction is the fraction of each period that's considered
+// part of the junction with its neighbour. The texts describe the sandhi as
+// the last and first portions of the two periods without agreeing on their
+// size: 10% is a common choice.
+const DefaultDashaSandhiFraction = 0.1
+
+// DashaSandhi is the junction between two consecutive periods at the same
+// level. Results of the ending period wind down and those of the starting one
+// are not yet settled, so events in it are harder to time.
+type DashaSandhi struct {
+	From DashaPeriod `json:"from"`
+	To   DashaPeriod `json:"to"`
+	// Window covers the end of From and the start of To
+	Window Interval `json:"window"`
+}
+
+func (ds DashaSandhi) String() string {
+	return fmt.Sprintf(
+		"DashaSandhi{From: %s, To: %s, Window: %+v}",
+		ds.From.Lord(),
+		ds.To.Lord(),
+		ds.Window,
+	)
+}
+
+// Contains reports whether t is within the window of the sandhi
+func (ds DashaSandhi) Contains(t time.Time) bool {
+	return !t.Before(ds.Window.Start.Time) && t.Before(ds.Window.End.Time)
+}
+
+// newDashaSandhi returns the junction between from and to, with fraction of
+// each period in the window. The periods' theoretical lengths are used, so
+// a clipped period at birth still gets its full share.
+func newDashaSandhi(from, to DashaPeriod, fraction float64) DashaSandhi {
+	boundary := to.Interval.Start.Time
+	return DashaSandhi{
+		From: from,
+		To:   to,
+		Window: NewInterval(
+			boundary.Add(-time.Duration(float64(from.duration)*fraction)),
+			boundary.Add(time.Duration(float64(to.duration)*fraction)),
+		),
+	}
+}
+
+// DashaSandhis returns the junctions between the periods at level whose
+// windows overlap [start, end), in order. fraction is the part of each period
+// in the window (see DefaultDashaSandhiFraction), from 0 to 1.
+func DashaSandhis(
+	tl DashaTimeline,
+	start, end time.Time,
+	level DashaLevel,
+	fraction float64,
+) []DashaSandhi {
+	ret := []DashaSandhi{}
+	if fraction <= 0 || fraction > 1 || !start.Before(end) {
+		return ret
+	}
+	periods := tl.GetPeriodsInRange(start, end, level)
+	if len(periods) == 0 {
+		return ret
+	}
+	// The sandhis at the edges of the range can spill into it from the
+	// neighbouring periods
+	first := periods[0].Interval.Start.Time
+	if prev, didFind := tl.GetPeriodForTime(first.Add(-time.Nanosecond), level); didFind {
+		periods = append([]DashaPeriod{prev}, periods...)
+	}
+	last := periods[len(periods)-1].Interval.End.Time
+	if next, didFind := tl.GetPeriodForTime(last, level); didFind {
+		periods = append(periods, next)
+	}
+	for i := 1; i < len(periods); i++ {
+		s := newDashaSandhi(periods[i-1], periods[i], fraction)
+		if s.Window.Start.Before(end) && s.Window.End.After(start) {
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
+
+// GetDashaSandhiForTime returns the junction at level that t falls in, if any
+func GetDashaSandhiForTime(
+	tl DashaTimeline,
+	t time.Time,
+	level DashaLevel,
+	fraction float64,
+) (DashaSandhi, bool) {
+	for _, s := range DashaSandhis(tl, t, t.Add(time.Nanosecond), level, fraction) {
+		if s.Contains(t) {
+			return s, true
+		}
+	}
+	return DashaSandhi{}, false
+}
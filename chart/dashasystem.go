@@ -0,0 +1,226 @@
+package chart
+
+import (
+	"fmt"
+	"time"
+)
+
+// DashaSystem is a system of planetary periods. All the systems here are
+// nakshatra dashas: the first period is decided by the nakshatra of the Moon
+// at birth, and its balance by how much of the nakshatra is left.
+type DashaSystem string
+
+const (
+	DashaSystem_Vimshottari DashaSystem = "vimshottari"
+	DashaSystem_Yogini      DashaSystem = "yogini"
+	DashaSystem_Ashtottari  DashaSystem = "ashtottari"
+)
+
+func (ds DashaSystem) String() string {
+	return string(ds)
+}
+
+// Lords returns the mahadasha lords of the system, in order
+func (ds DashaSystem) Lords() []DashaLord {
+	switch ds {
+	case DashaSystem_Vimshottari:
+		return MahadashaList
+	case DashaSystem_Yogini:
+		// Mangala, Pingala, Dhanya, Bhramari, Bhadrika, Ulka, Siddha and
+		// Sankata
+		return []DashaLord{
+			DashaLordMoon,
+			DashaLordSun,
+			DashaLordJupiter,
+			DashaLordMars,
+			DashaLordMercury,
+			DashaLordSaturn,
+			DashaLordVenus,
+			DashaLordRahu,
+		}
+	case DashaSystem_Ashtottari:
+		return []DashaLord{
+			DashaLordSun,
+			DashaLordMoon,
+			DashaLordMars,
+			DashaLordMercury,
+			DashaLordSaturn,
+			DashaLordJupiter,
+			DashaLordRahu,
+			DashaLordVenus,
+		}
+	}
+	panic(fmt.Sprintf("unknown dasha system: %s", ds))
+}
+
+// Years returns the length of the mahadasha of lord, in years
+func (ds DashaSystem) Years(lord DashaLord) float64 {
+	switch ds {
+	case DashaSystem_Vimshottari:
+		return lord.MahadashaDuration().Hours() / parseDuration(1, 0, 0).Hours()
+	case DashaSystem_Yogini:
+		for i, l := range ds.Lords() {
+			if l == lord {
+				return float64(i + 1)
+			}
+		}
+	case DashaSystem_Ashtottari:
+		switch lord {
+		case DashaLordSun:
+			return 6
+		case DashaLordMoon:
+			return 15
+		case DashaLordMars:
+			return 8
+		case DashaLordMercury:
+			return 17
+		case DashaLordSaturn:
+			return 10
+		case DashaLordJupiter:
+			return 19
+		case DashaLordRahu:
+			return 12
+		case DashaLordVenus:
+			return 21
+		}
+	}
+	panic(fmt.Sprintf("%s is not a lord in the %s dasha system", lord, ds))
+}
+
+// TotalYears returns the length of a full cycle of the system, in years
+func (ds DashaSystem) TotalYears() float64 {
+	ret := 0.0
+	for _, l := range ds.Lords() {
+		ret += ds.Years(l)
+	}
+	return ret
+}
+
+// Next returns the lord that comes after lord in the system
+func (ds DashaSystem) Next(lord DashaLord) DashaLord {
+	lords := ds.Lords()
+	for i, l := range lords {
+		if l == lord {
+			return lords[(i+1)%len(lords)]
+		}
+	}
+	panic(fmt.Sprintf("%s is not a lord in the %s dasha system", lord, ds))
+}
+
+// Duration returns the length of the mahadasha of lord
+func (ds DashaSystem) Duration(lord DashaLord) time.Duration {
+	return yearsToDuration(ds.Years(lord))
+}
+
+// yearsToDuration converts years to a duration, using the same year length
+// as the Vimshottari tables (see parseDuration)
+func yearsToDuration(years float64) time.Duration {
+	return time.Duration(years * float64(parseDuration(1, 0, 0)))
+}
+
+// DashaTimeline is the query interface shared by all dasha systems, so
+// callers can switch between them
+type DashaTimeline interface {
+	System() DashaSystem
+	// Start and End are the span covered by the timeline
+	Start() time.Time
+	End() time.Time
+	// Mahadashas returns the top-level periods, in order
+	Mahadashas() []DashaPeriod
+	// Children returns the sub-periods of p, in order
+	Children(p DashaPeriod) []DashaPeriod
+	// GetPeriodsForTime returns the active periods at t, from the mahadasha
+	// down to level
+	GetPeriodsForTime(t time.Time, level DashaLevel) ([]DashaPeriod, bool)
+	// GetPeriodForTime returns the active period at t at level
+	GetPeriodForTime(t time.Time, level DashaLevel) (DashaPeriod, bool)
+	// GetPeriodsInRange returns the periods at level that overlap
+	// [start, end), in order
+	GetPeriodsInRange(start, end time.Time, level DashaLevel) []DashaPeriod
+}
+
+var (
+	_ DashaTimeline = (*DashaTree)(nil)
+	_ DashaTimeline = (*NakshatraDashaTree)(nil)
+)
+
+// periodsForTime implements DashaTimeline.GetPeriodsForTime on top of
+// Mahadashas and Children
+func periodsForTime(
+	tl DashaTimeline,
+	t time.Time,
+	level DashaLevel,
+) ([]DashaPeriod, bool) {
+	if level < DashaLevel_Mahadasha || level > DashaLevel_Prana {
+		return nil, false
+	}
+	var md *DashaPeriod
+	for _, p := range tl.Mahadashas() {
+		if p.Contains(t) {
+			md = &p
+			break
+		}
+	}
+	if md == nil {
+		return nil, false
+	}
+	ret := []DashaPeriod{*md}
+	for ret[len(ret)-1].Level < level {
+		didFind := false
+		for _, child := range tl.Children(ret[len(ret)-1]) {
+			if child.Contains(t) {
+				ret = append(ret, child)
+				didFind = true
+				break
+			}
+		}
+		if !didFind {
+			// Can only happen through rounding, since the children of a
+			// period cover all of it
+			return nil, false
+		}
+	}
+	return ret, true
+}
+
+// periodForTime implements DashaTimeline.GetPeriodForTime
+func periodForTime(
+	tl DashaTimeline,
+	t time.Time,
+	level DashaLevel,
+) (DashaPeriod, bool) {
+	periods, didFind := tl.GetPeriodsForTime(t, level)
+	if !didFind {
+		return DashaPeriod{}, false
+	}
+	return periods[len(periods)-1], true
+}
+
+// periodsInRange implements DashaTimeline.GetPeriodsInRange
+func periodsInRange(
+	tl DashaTimeline,
+	start, end time.Time,
+	level DashaLevel,
+) []DashaPeriod {
+	ret := []DashaPeriod{}
+	if !start.Before(end) ||
+		level < DashaLevel_Mahadasha ||
+		level > DashaLevel_Prana {
+		return ret
+	}
+	var walk func(periods []DashaPeriod)
+	walk = func(periods []DashaPeriod) {
+		for _, p := range periods {
+			if !p.Overlaps(start, end) {
+				continue
+			}
+			if p.Level == level {
+				ret = append(ret, p)
+				continue
+			}
+			walk(tl.Children(p))
+		}
+	}
+	walk(tl.Mahadashas())
+	return ret
+}
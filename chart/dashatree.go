@@ -1,12 +1,13 @@
 package chart
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 
-	"github.com/afjoseph/sacredstar/astropoint"
 	"github.com/afjoseph/sacredstar/pointid"
 	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/afjoseph/sacredstar/zodiacalpos"
 	"github.com/tidwall/btree"
 )
 
@@ -16,27 +17,41 @@ const SiderealMoonMonthDelta = 27.326
 type DashaTree struct {
 	tree      *btree.BTreeG[Dasha]
 	startTime time.Time
+	// firstMahadashaStart and firstAntardashaStart are when the periods
+	// running at birth actually started (i.e., before the birth time)
+	firstMahadashaStart  time.Time
+	firstAntardashaStart time.Time
+	// yearLength is the length of a year in the periods of the tree
+	yearLength DashaYearLength
 }
 
-func NewDashaTree(
+// calculateMoonNakshatraRemaining finds the nakshatra of the Moon of natal
+// (cast at birthTime) and the fraction of it that the Moon still has to
+// traverse. All nakshatra dashas (e.g., Vimshottari, Yogini) are derived
+// from these two.
+func calculateMoonNakshatraRemaining(
 	swe *wrapper.SwissEph,
 	birthTime time.Time,
-	moonAstroPoint *astropoint.AstroPoint,
-) (*DashaTree, error) {
+	natal *Chart,
+) (*Nakshatra, float64, error) {
 	chartTimeAsJulian := swe.GoTimeToJulianDay(birthTime.UTC())
-	nak, err := NewNakshatraFromChart(swe, moonAstroPoint)
+	nak, err := NewNakshatraFromChart(swe, natal, pointid.Moon)
 	if err != nil {
-		return nil, fmt.Errorf("while calculating Nakshatra: %v", err)
+		return nil, 0, fmt.Errorf("while calculating Nakshatra: %v", err)
 	}
 	// Calculate how long elapsed in the dasha
-	// 1. Calculate the moon's zodiacal position
-	// moonZodPos := zodiacalpos.NewZodiacalPosFromLongitude(moon.Longitude)
+	// 1. Calculate the moon's (sidereal) zodiacal position
+	moonLon, err := natal.siderealLongitude(swe, pointid.Moon)
+	if err != nil {
+		return nil, 0, fmt.Errorf("while getting Moon longitude: %v", err)
+	}
+	moonZodPos := zodiacalpos.NewZodiacalPosFromLongitude(moonLon)
 	// 2. Calculate the difference between the moon's zodiacal position and
 	//    Nakshatra's max/min zodiacal position
-	diffBetweenMoonAndMaxNakshatraZodPosInJulian := moonAstroPoint.ZodiacalPos.DiffInAbsDegrees(
+	diffBetweenMoonAndMaxNakshatraZodPosInJulian := moonZodPos.DiffInAbsDegrees(
 		nak.MaxZodiacalPos(),
 	)
-	diffBetweenMoonAndMinNakshatraZodPosInJulian := moonAstroPoint.ZodiacalPos.DiffInAbsDegrees(
+	diffBetweenMoonAndMinNakshatraZodPosInJulian := moonZodPos.DiffInAbsDegrees(
 		nak.MinZodiacalPos(),
 	)
 	// 3. Find approx start/end time of Nakshatra
@@ -50,7 +65,7 @@ func NewDashaTree(
 
 	// fmt.Printf(
 	// 	"moonZodPos: %+v | nak.MaxZodiacalPos: %+v | nak.MinZodiacalPos: %+v | chartTimeAsJulian: %f\n",
-	// 	moonAstroPoint.ZodiacalPos,
+	// 	moonZodPos,
 	// 	nak.MaxZodiacalPos(),
 	// 	nak.MinZodiacalPos(),
 	// 	chartTimeAsJulian,
@@ -61,11 +76,12 @@ func NewDashaTree(
 		pointid.Moon,
 		nak.MinZodiacalPos(),
 		nak.Type,
+		natal.Ayanamsa,
 		1.0/60.0/24.0, // Step: 1 minute
 		1.0,           // Range: 1 day
 	)
 	if err != nil {
-		return nil, fmt.Errorf(
+		return nil, 0, fmt.Errorf(
 			"while finding exact start time of Nakshatra: %v",
 			err,
 		)
@@ -75,7 +91,7 @@ func NewDashaTree(
 			"chartTimeAsJulian (%f) < exactNakshatraStartTimeInJulianDays (%f) for %+v",
 			chartTimeAsJulian,
 			exactNakshatraStartTimeInJulianDays,
-			moonAstroPoint,
+			moonZodPos,
 		))
 	}
 	exactNakshatraEndTimeInJulianDays, err := correctJulianDateToZodiacalPos(
@@ -84,11 +100,12 @@ func NewDashaTree(
 		pointid.Moon,
 		nak.MaxZodiacalPos(),
 		nak.Type,
+		natal.Ayanamsa,
 		0.5/60.0/24.0, // Step: 1 minute
 		1.0,           // Range: 1 day
 	)
 	if err != nil {
-		return nil, fmt.Errorf(
+		return nil, 0, fmt.Errorf(
 			"while finding exact start time of Nakshatra: %v",
 			err,
 		)
@@ -98,7 +115,7 @@ func NewDashaTree(
 			"chartTimeAsJulian (%f) > exactNakshatraEndTimeInJulianDays (%f) for moon at %+v",
 			chartTimeAsJulian,
 			exactNakshatraEndTimeInJulianDays,
-			moonAstroPoint,
+			moonZodPos,
 		))
 	}
 	// fmt.Printf(
@@ -128,14 +145,40 @@ func NewDashaTree(
 		panic(fmt.Sprintf(
 			"percNakshatraRemaining > 1: %+v for moon at %+v",
 			percNakshatraRemaining,
-			moonAstroPoint,
+			moonZodPos,
 		))
 	}
 	// percNakshatraElapsing := elapsingNakshatraDurationInJulianDays / totalNakshatraDurationInJulianDays
 	// fmt.Printf("percNakshatraRemaining: %+v\n", percNakshatraRemaining)
 	// fmt.Printf("percNakshatraElapsing: %+v\n", percNakshatraElapsing)
+	return nak, percNakshatraRemaining, nil
+}
+
+// calculateBirthDasha finds the Vimshottari mahadasha and antardasha running
+// at birthTime, and how much of the antardasha is left
+func calculateBirthDasha(
+	swe *wrapper.SwissEph,
+	birthTime time.Time,
+	natal *Chart,
+	opts *DashaOptions,
+) (
+	mahaDashaLord DashaLord,
+	antarDashaLord DashaLord,
+	remainingDurationInAntardasha time.Duration,
+	err error,
+) {
+	nak, percNakshatraRemaining, err := calculateMoonNakshatraBalance(
+		swe,
+		birthTime,
+		natal,
+		opts.BalanceMethod,
+	)
+	if err != nil {
+		return DashaLordNone, DashaLordNone, 0, err
+	}
 	mahaDashaLord, antarDashaLord,
-		remainingDurationInAntardasha := nak.GetDashaLordPair(birthTime, percNakshatraRemaining)
+		remainingDurationInAntardasha = nak.GetDashaLordPair(birthTime, percNakshatraRemaining)
+	remainingDurationInAntardasha = opts.YearLength.scale(remainingDurationInAntardasha)
 	// mahadashaDuration := dashaLordPair.MahadashaDuration()
 	// fmt.Printf("Dasha: %s | Duration: %+v\n", dl, durafmt.Parse(mahadashaDuration))
 	// mahadashaDurationRemaining := time.Duration(
@@ -162,17 +205,57 @@ func NewDashaTree(
 	// ddashaEnd := swe.JulianDayToGoTime(chart.Time).Add(dashaDurationRemaining)
 	// fmt.Printf("ddashaStart: %+v\n", ddashaStart)
 	// fmt.Printf("ddashaEnd: %+v\n", ddashaEnd)
+	return mahaDashaLord, antarDashaLord, remainingDurationInAntardasha, nil
+}
+
+// NewDashaTree casts the 81 mahadasha/antardasha pairs (i.e., a full
+// Vimshottari cycle) starting from birthTime. The pair running at birth is
+// clipped to start at birthTime. natal is the chart cast at birthTime; it can
+// be of any type, since the Moon's nakshatra is taken from its sidereal
+// position (see NewNakshatraFromChart).
+func NewDashaTree(
+	swe *wrapper.SwissEph,
+	birthTime time.Time,
+	natal *Chart,
+) (*DashaTree, error) {
+	return NewDashaTreeWithOptions(swe, birthTime, natal, nil)
+}
+
+// NewDashaTreeWithOptions is like NewDashaTree but with a choice of year
+// length and balance method. opts can be nil to use the defaults.
+func NewDashaTreeWithOptions(
+	swe *wrapper.SwissEph,
+	birthTime time.Time,
+	natal *Chart,
+	opts *DashaOptions,
+) (*DashaTree, error) {
+	if opts == nil {
+		opts = NewDefaultDashaOptions()
+	}
+	mahaDashaLord, antarDashaLord, remainingDurationInAntardasha, err := calculateBirthDasha(
+		swe,
+		birthTime,
+		natal,
+		opts,
+	)
+	if err != nil {
+		return nil, err
+	}
+	dt := newDashaTree(
+		birthTime,
+		mahaDashaLord,
+		antarDashaLord,
+		remainingDurationInAntardasha,
+		opts.YearLength,
+	)
 
 	// Now cast a dasha interval tree from this time
-	t := btree.NewBTreeG[Dasha](func(a, b Dasha) bool {
-		return a.Interval.Start.Before(b.Interval.Start.Time)
-	})
 	dashaStart := birthTime
 	dashaEnd := dashaStart.Add(remainingDurationInAntardasha)
 	for i := 0; i < TotalDashaLordPairCombinations; i++ {
 		if i != 0 {
 			dashaEnd = dashaStart.Add(
-				AntardashaDuration(mahaDashaLord, antarDashaLord),
+				dt.antardashaDuration(mahaDashaLord, antarDashaLord),
 			)
 		}
 		d := Dasha{
@@ -181,30 +264,220 @@ func NewDashaTree(
 			Interval:   NewInterval(dashaStart, dashaEnd),
 		}
 		// fmt.Printf("Adding dasha: %+v\n", d)
-		t.Set(d)
+		dt.tree.Set(d)
+		mahaDashaLord, antarDashaLord = NextAntardasha(
+			mahaDashaLord,
+			antarDashaLord,
+		)
+		dashaStart = dashaEnd
+	}
+	return dt, nil
+}
+
+// NewDashaTreeForRange is like NewDashaTree but it casts all the periods
+// between start and end, which can be before birth or span more than one
+// Vimshottari cycle. Periods are never clipped: the first one starts at or
+// before start and the last one ends at or after end.
+func NewDashaTreeForRange(
+	swe *wrapper.SwissEph,
+	birthTime time.Time,
+	natal *Chart,
+	start, end time.Time,
+) (*DashaTree, error) {
+	return NewDashaTreeForRangeWithOptions(
+		swe,
+		birthTime,
+		natal,
+		start,
+		end,
+		nil,
+	)
+}
+
+// NewDashaTreeForRangeWithOptions is like NewDashaTreeForRange but with a
+// choice of year length and balance method. opts can be nil to use the
+// defaults.
+func NewDashaTreeForRangeWithOptions(
+	swe *wrapper.SwissEph,
+	birthTime time.Time,
+	natal *Chart,
+	start, end time.Time,
+	opts *DashaOptions,
+) (*DashaTree, error) {
+	if !start.Before(end) {
+		return nil, fmt.Errorf("invalid range: %v - %v", start, end)
+	}
+	if opts == nil {
+		opts = NewDefaultDashaOptions()
+	}
+	birthMahaDashaLord, birthAntarDashaLord, remainingDurationInAntardasha, err := calculateBirthDasha(
+		swe,
+		birthTime,
+		natal,
+		opts,
+	)
+	if err != nil {
+		return nil, err
+	}
+	antardashaDuration := func(mdl, adl DashaLord) time.Duration {
+		return opts.YearLength.scale(AntardashaDuration(mdl, adl))
+	}
+	birthAntardashaEnd := birthTime.Add(remainingDurationInAntardasha)
+	birthAntardashaStart := birthAntardashaEnd.Add(
+		-antardashaDuration(birthMahaDashaLord, birthAntarDashaLord),
+	)
+
+	// Walk back from the antardasha running at birth until start...
+	dashas := []Dasha{{
+		Mahadasha:  birthMahaDashaLord,
+		Antardasha: birthAntarDashaLord,
+		Interval:   NewInterval(birthAntardashaStart, birthAntardashaEnd),
+	}}
+	mahaDashaLord, antarDashaLord := birthMahaDashaLord, birthAntarDashaLord
+	dashaEnd := birthAntardashaStart
+	for dashaEnd.After(start) {
+		mahaDashaLord, antarDashaLord = PreviousAntardasha(
+			mahaDashaLord,
+			antarDashaLord,
+		)
+		dashaStart := dashaEnd.Add(
+			-antardashaDuration(mahaDashaLord, antarDashaLord),
+		)
+		dashas = append(dashas, Dasha{
+			Mahadasha:  mahaDashaLord,
+			Antardasha: antarDashaLord,
+			Interval:   NewInterval(dashaStart, dashaEnd),
+		})
+		dashaEnd = dashaStart
+	}
+	// ...and forward until end
+	mahaDashaLord, antarDashaLord = birthMahaDashaLord, birthAntarDashaLord
+	dashaStart := birthAntardashaEnd
+	for dashaStart.Before(end) {
 		mahaDashaLord, antarDashaLord = NextAntardasha(
 			mahaDashaLord,
 			antarDashaLord,
 		)
+		dashaEnd := dashaStart.Add(
+			antardashaDuration(mahaDashaLord, antarDashaLord),
+		)
+		dashas = append(dashas, Dasha{
+			Mahadasha:  mahaDashaLord,
+			Antardasha: antarDashaLord,
+			Interval:   NewInterval(dashaStart, dashaEnd),
+		})
 		dashaStart = dashaEnd
 	}
-	return &DashaTree{
-		tree:      t,
-		startTime: birthTime,
-	}, nil
+
+	// The first period in the tree is not clipped, so it starts where it
+	// says it does
+	first := dashas[len(dashas)-1]
+	for _, d := range dashas {
+		if d.Interval.Start.Before(first.Interval.Start.Time) {
+			first = d
+		}
+	}
+	dt := newDashaTree(
+		first.Interval.Start.Time,
+		first.Mahadasha,
+		first.Antardasha,
+		antardashaDuration(first.Mahadasha, first.Antardasha),
+		opts.YearLength,
+	)
+	for _, d := range dashas {
+		dt.tree.Set(d)
+	}
+	return dt, nil
+}
+
+// newDashaTree returns an empty tree whose first period is the mdl/adl pair
+// with remaining time left in it at startTime
+func newDashaTree(
+	startTime time.Time,
+	mdl, adl DashaLord,
+	remaining time.Duration,
+	yearLength DashaYearLength,
+) *DashaTree {
+	dt := &DashaTree{
+		tree: btree.NewBTreeG[Dasha](func(a, b Dasha) bool {
+			return a.Interval.Start.Before(b.Interval.Start.Time)
+		}),
+		startTime:  startTime,
+		yearLength: yearLength,
+	}
+	// Find when the mahadasha and antardasha running at startTime started,
+	// so that they can be divided into sub-periods
+	dt.firstAntardashaStart = startTime.Add(
+		remaining - dt.antardashaDuration(mdl, adl),
+	)
+	dt.firstMahadashaStart = dt.firstAntardashaStart
+	for _, sister := range mdl.GetAntardashas() {
+		if sister == adl {
+			break
+		}
+		dt.firstMahadashaStart = dt.firstMahadashaStart.Add(
+			-dt.antardashaDuration(mdl, sister),
+		)
+	}
+	return dt
+}
+
+// YearLength returns the length of a year in the periods of the tree
+func (dt *DashaTree) YearLength() DashaYearLength {
+	if dt.yearLength == "" {
+		return DashaYearLength_Calendar
+	}
+	return dt.yearLength
+}
+
+// mahadashaDuration is DashaLord.MahadashaDuration in the year length of the
+// tree
+func (dt *DashaTree) mahadashaDuration(mdl DashaLord) time.Duration {
+	return dt.YearLength().scale(mdl.MahadashaDuration())
+}
+
+// antardashaDuration is AntardashaDuration in the year length of the tree
+func (dt *DashaTree) antardashaDuration(mdl, adl DashaLord) time.Duration {
+	return dt.YearLength().scale(AntardashaDuration(mdl, adl))
+}
+
+// Start returns when the first period in the tree starts
+func (dt *DashaTree) Start() time.Time {
+	return dt.startTime
+}
+
+// End returns when the last period in the tree ends
+func (dt *DashaTree) End() time.Time {
+	last, ok := dt.tree.Max()
+	if !ok {
+		return dt.startTime
+	}
+	return last.Interval.End.Time
+}
+
+// Dashas returns all the mahadasha/antardasha pairs in the tree, in order
+func (dt *DashaTree) Dashas() []Dasha {
+	return dt.tree.Items()
+}
+
+// contains reports whether t is within d. The start is inclusive and the end
+// is exclusive (see DashaPeriod.Contains)
+func contains(d Dasha, t time.Time) bool {
+	return !t.Before(d.Interval.Start.Time) && t.Before(d.Interval.End.Time)
 }
 
 // GetDashaForTime returns the dasha lord and sub dasha lord for the given time
 func (dt *DashaTree) GetDashaForTime(
 	t time.Time,
 ) (ret Dasha, didFind bool) {
-	dt.tree.Scan(func(d Dasha) bool {
-		if d.Interval.IsInBetween(t) {
+	// Seek to the last dasha that starts at or before t
+	pivot := Dasha{Interval: NewInterval(t, t)}
+	dt.tree.Descend(pivot, func(d Dasha) bool {
+		if contains(d, t) {
 			ret = d
 			didFind = true
-			return false
 		}
-		return true
+		return false
 	})
 	if !didFind {
 		return Dasha{}, false
@@ -212,6 +485,96 @@ func (dt *DashaTree) GetDashaForTime(
 	return ret, true
 }
 
+// GetDashasInRange returns the dashas that overlap [start, end), in order
+func (dt *DashaTree) GetDashasInRange(start, end time.Time) []Dasha {
+	ret := []Dasha{}
+	if !start.Before(end) {
+		return ret
+	}
+	if d, didFind := dt.GetDashaForTime(start); didFind {
+		ret = append(ret, d)
+	}
+	pivot := Dasha{Interval: NewInterval(start, start)}
+	dt.tree.Ascend(pivot, func(d Dasha) bool {
+		if !d.Interval.Start.Before(end) {
+			return false
+		}
+		if len(ret) != 0 && ret[len(ret)-1].Interval.Start.Equal(d.Interval.Start.Time) {
+			return true
+		}
+		ret = append(ret, d)
+		return true
+	})
+	return ret
+}
+
+// GetPreviousDasha returns the dasha right before d
+func (dt *DashaTree) GetPreviousDasha(d Dasha) (ret Dasha, didFind bool) {
+	dt.tree.Descend(d, func(item Dasha) bool {
+		if !item.Interval.Start.Before(d.Interval.Start.Time) {
+			return true
+		}
+		ret = item
+		didFind = true
+		return false
+	})
+	return ret, didFind
+}
+
+// GetNextDasha returns the dasha right after d
+func (dt *DashaTree) GetNextDasha(d Dasha) (ret Dasha, didFind bool) {
+	dt.tree.Ascend(d, func(item Dasha) bool {
+		if !item.Interval.Start.After(d.Interval.Start.Time) {
+			return true
+		}
+		ret = item
+		didFind = true
+		return false
+	})
+	return ret, didFind
+}
+
+type dashaTreeJSON struct {
+	StartTime            time.Time `json:"startTime"`
+	FirstMahadashaStart  time.Time `json:"firstMahadashaStart"`
+	FirstAntardashaStart time.Time `json:"firstAntardashaStart"`
+	// YearLength is empty for trees serialized before it was added, which
+	// were all in calendar years
+	YearLength DashaYearLength `json:"yearLength,omitempty"`
+	Dashas     []Dasha         `json:"dashas"`
+}
+
+// MarshalJSON serializes the full timeline of the tree
+func (dt *DashaTree) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dashaTreeJSON{
+		StartTime:            dt.startTime,
+		FirstMahadashaStart:  dt.firstMahadashaStart,
+		FirstAntardashaStart: dt.firstAntardashaStart,
+		YearLength:           dt.YearLength(),
+		Dashas:               dt.Dashas(),
+	})
+}
+
+func (dt *DashaTree) UnmarshalJSON(b []byte) error {
+	var obj dashaTreeJSON
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return fmt.Errorf("while unmarshalling dasha tree: %v", err)
+	}
+	*dt = DashaTree{
+		tree: btree.NewBTreeG[Dasha](func(a, b Dasha) bool {
+			return a.Interval.Start.Before(b.Interval.Start.Time)
+		}),
+		startTime:            obj.StartTime,
+		firstMahadashaStart:  obj.FirstMahadashaStart,
+		firstAntardashaStart: obj.FirstAntardashaStart,
+		yearLength:           obj.YearLength,
+	}
+	for _, d := range obj.Dashas {
+		dt.tree.Set(d)
+	}
+	return nil
+}
+
 func (dt *DashaTree) Dump() {
 	for i, d := range dt.tree.Items() {
 		fmt.Printf("%d: %+v\n", i, d)
@@ -1,6 +1,7 @@
 package chart
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -8,6 +9,7 @@ import (
 	"github.com/afjoseph/sacredstar/pointid"
 	"github.com/afjoseph/sacredstar/wrapper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewDashaTree(t *testing.T) {
@@ -48,17 +50,13 @@ func TestNewDashaTree(t *testing.T) {
 		t.Run(
 			fmt.Sprintf("%s --> %s", tc.birthTime, tc.testTime),
 			func(t *testing.T) {
-				moonAstroPoint, err := calculatePlanet(
-					swe,
-					swe.GoTimeToJulianDay(tc.birthTime),
-					pointid.Moon,
-					D1ChartType,
-					nil,
-				)
+				// London coordinates
+				natal, err := NewChartFromUTC(
+					swe, tc.birthTime, -0.1278, 51.5074, D1ChartType, pointid.VedicPlanets)
 				assert.NoError(t, err)
-				assert.NotNil(t, moonAstroPoint)
+				assert.NotNil(t, natal)
 
-				dt, err := NewDashaTree(swe, tc.birthTime, moonAstroPoint)
+				dt, err := NewDashaTree(swe, tc.birthTime, natal)
 				assert.NoError(t, err)
 				assert.NotNil(t, dt)
 				// dt.Dump()
@@ -85,3 +83,129 @@ func TestNewDashaTree(t *testing.T) {
 		)
 	}
 }
+
+func TestDashaTree_Queries(t *testing.T) {
+	birthTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dt := newTestDashaTree(t, birthTime)
+	dashas := dt.Dashas()
+	require.Len(t, dashas, TotalDashaLordPairCombinations)
+	require.Equal(t, birthTime, dt.Start())
+	require.Equal(t, dashas[len(dashas)-1].Interval.End.Time, dt.End())
+
+	t.Run("seek matches a linear scan", func(t *testing.T) {
+		for tm := birthTime; tm.Before(dt.End()); tm = tm.Add(97 * 24 * time.Hour) {
+			expected := Dasha{}
+			for _, d := range dashas {
+				if !tm.Before(d.Interval.Start.Time) && tm.Before(d.Interval.End.Time) {
+					expected = d
+					break
+				}
+			}
+			actual, didFind := dt.GetDashaForTime(tm)
+			require.True(t, didFind)
+			require.Equal(t, expected, actual)
+		}
+		_, didFind := dt.GetDashaForTime(birthTime.Add(-time.Minute))
+		require.False(t, didFind)
+		_, didFind = dt.GetDashaForTime(dt.End())
+		require.False(t, didFind)
+	})
+
+	t.Run("boundaries belong to the next dasha", func(t *testing.T) {
+		actual, didFind := dt.GetDashaForTime(dashas[1].Interval.Start.Time)
+		require.True(t, didFind)
+		require.Equal(t, dashas[1], actual)
+	})
+
+	t.Run("range", func(t *testing.T) {
+		// Starts in the middle of the 2nd dasha and ends exactly at the start
+		// of the 5th one
+		actual := dt.GetDashasInRange(
+			dashas[1].Interval.Start.Add(time.Hour),
+			dashas[4].Interval.Start.Time,
+		)
+		require.Equal(t, dashas[1:4], actual)
+		require.Empty(t, dt.GetDashasInRange(
+			birthTime.Add(-48*time.Hour),
+			birthTime.Add(-24*time.Hour),
+		))
+		require.Equal(t, dashas, dt.GetDashasInRange(
+			birthTime.Add(-time.Hour),
+			dt.End().Add(time.Hour),
+		))
+	})
+
+	t.Run("previous and next", func(t *testing.T) {
+		prev, didFind := dt.GetPreviousDasha(dashas[10])
+		require.True(t, didFind)
+		require.Equal(t, dashas[9], prev)
+		next, didFind := dt.GetNextDasha(dashas[10])
+		require.True(t, didFind)
+		require.Equal(t, dashas[11], next)
+		_, didFind = dt.GetPreviousDasha(dashas[0])
+		require.False(t, didFind)
+		_, didFind = dt.GetNextDasha(dashas[len(dashas)-1])
+		require.False(t, didFind)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		b, err := json.Marshal(dt)
+		require.NoError(t, err)
+		var actual DashaTree
+		require.NoError(t, json.Unmarshal(b, &actual))
+		require.Equal(t, dashas, actual.Dashas())
+		tm := time.Date(2031, 5, 3, 0, 0, 0, 0, time.UTC)
+		expected, _ := dt.GetPeriodsForTime(tm, DashaLevel_Prana)
+		periods, didFind := actual.GetPeriodsForTime(tm, DashaLevel_Prana)
+		require.True(t, didFind)
+		for i := range periods {
+			require.Equal(t, expected[i].Lords, periods[i].Lords)
+		}
+	})
+}
+
+func TestNewDashaTreeForRange(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+	birthTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// London coordinates
+	natal, err := NewChartFromUTC(
+		swe, birthTime, -0.1278, 51.5074, D1ChartType, pointid.VedicPlanets)
+	require.NoError(t, err)
+	start := time.Date(1950, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2300, 1, 1, 0, 0, 0, 0, time.UTC)
+	dt, err := NewDashaTreeForRange(swe, birthTime, natal, start, end)
+	require.NoError(t, err)
+	require.False(t, dt.Start().After(start))
+	require.False(t, dt.End().Before(end))
+
+	// No gaps, and the lords follow each other
+	dashas := dt.Dashas()
+	for i := 1; i < len(dashas); i++ {
+		require.Equal(t, dashas[i-1].Interval.End, dashas[i].Interval.Start)
+		mdl, adl := NextAntardasha(dashas[i-1].Mahadasha, dashas[i-1].Antardasha)
+		require.Equal(t, mdl, dashas[i].Mahadasha)
+		require.Equal(t, adl, dashas[i].Antardasha)
+	}
+
+	// Periods before birth. Ketu's mahadasha started in 2017, so Mercury's
+	// ran before it
+	before, didFind := dt.GetDashaForTime(time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.True(t, didFind)
+	require.Equal(t, DashaLordMercury, before.Mahadasha)
+
+	// After birth, it agrees with the tree cast from birth
+	fromBirth, err := NewDashaTree(swe, birthTime, natal)
+	require.NoError(t, err)
+	for tm := birthTime; tm.Before(fromBirth.End()); tm = tm.Add(61 * 24 * time.Hour) {
+		expected, _ := fromBirth.GetPeriodsForTime(tm, DashaLevel_Pratyantardasha)
+		actual, didFind := dt.GetPeriodsForTime(tm, DashaLevel_Pratyantardasha)
+		require.True(t, didFind)
+		for i := range actual {
+			require.Equal(t, expected[i].Lords, actual[i].Lords)
+		}
+	}
+
+	_, err = NewDashaTreeForRange(swe, birthTime, natal, end, start)
+	require.Error(t, err)
+}
@@ -0,0 +1,388 @@
+package chart
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/afjoseph/sacredstar/zodiacalpos"
+)
+
+// DignityState is the Vedic dignity of a planet in its sign, from the
+// strongest to the weakest
+type DignityState string
+
+const (
+	DignityState_Exalted      DignityState = "exalted"
+	DignityState_Moolatrikona DignityState = "moolatrikona"
+	DignityState_Own          DignityState = "own"
+	DignityState_GreatFriend  DignityState = "great-friend"
+	DignityState_Friend       DignityState = "friend"
+	DignityState_Neutral      DignityState = "neutral"
+	DignityState_Enemy        DignityState = "enemy"
+	DignityState_GreatEnemy   DignityState = "great-enemy"
+	DignityState_Debilitated  DignityState = "debilitated"
+)
+
+func (ds DignityState) String() string {
+	return string(ds)
+}
+
+// deepExaltation is the longitude where each planet is most exalted. Its
+// deep debilitation is the opposite point.
+//
+// The nodes' exaltation isn't in BPHS itself and varies between schools. We
+// use Rahu in Taurus and Ketu in Scorpio.
+var deepExaltation = map[pointid.PointID]float64{
+	pointid.Sun:     10,
+	pointid.Moon:    33,
+	pointid.Mars:    298,
+	pointid.Mercury: 165,
+	pointid.Jupiter: 95,
+	pointid.Venus:   357,
+	pointid.Saturn:  200,
+	pointid.Rahu:    50,
+	pointid.Ketu:    230,
+}
+
+// ExaltationSign returns the sign pid is exalted in. It's debilitated in the
+// opposite one. ok is false for points that have none.
+func ExaltationSign(pid pointid.PointID) (s sign.Sign, ok bool) {
+	lon, ok := deepExaltation[pid]
+	if !ok {
+		return "", false
+	}
+	return sign.DegreeToSign(lon), true
+}
+
+// DeepExaltation returns the longitude where pid is most exalted. It's most
+// debilitated 180° away.
+func DeepExaltation(pid pointid.PointID) (lon float64, ok bool) {
+	lon, ok = deepExaltation[pid]
+	return lon, ok
+}
+
+// MoolatrikonaRange is the part of a sign that's the moolatrikona of a
+// planet
+type MoolatrikonaRange struct {
+	Sign sign.Sign `json:"sign"`
+	// From and To are the degrees in Sign, To excluded
+	From float64 `json:"from"`
+	To   float64 `json:"to"`
+}
+
+// Contains reports whether the longitude lon is in the range
+func (mr MoolatrikonaRange) Contains(lon float64) bool {
+	lon = math.Mod(lon+360, 360)
+	signDeg := math.Mod(lon, 30)
+	return sign.DegreeToSign(lon) == mr.Sign && signDeg >= mr.From && signDeg < mr.To
+}
+
+var moolatrikona = map[pointid.PointID]MoolatrikonaRange{
+	pointid.Sun:     {sign.Leo, 0, 20},
+	pointid.Moon:    {sign.Taurus, 3, 30},
+	pointid.Mars:    {sign.Aries, 0, 12},
+	pointid.Mercury: {sign.Virgo, 15, 20},
+	pointid.Jupiter: {sign.Sagittarius, 0, 10},
+	pointid.Venus:   {sign.Libra, 0, 15},
+	pointid.Saturn:  {sign.Aquarius, 0, 20},
+	pointid.Rahu:    {sign.Gemini, 0, 30},
+	pointid.Ketu:    {sign.Sagittarius, 0, 30},
+}
+
+// Moolatrikona returns the moolatrikona of pid
+func Moolatrikona(pid pointid.PointID) (mr MoolatrikonaRange, ok bool) {
+	mr, ok = moolatrikona[pid]
+	return mr, ok
+}
+
+// OwnSigns returns the signs pid rules: the ones whose traditional ruler it
+// is, and Aquarius for Rahu and Scorpio for Ketu, which they co-rule
+func OwnSigns(pid pointid.PointID) []sign.Sign {
+	switch pid {
+	case pointid.Rahu:
+		return []sign.Sign{sign.Aquarius}
+	case pointid.Ketu:
+		return []sign.Sign{sign.Scorpio}
+	}
+	ret := []sign.Sign{}
+	for i := 1; i <= 12; i++ {
+		s, _ := sign.NewSignFromInt(i)
+		if s.TraditionalRuler() == pid {
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
+
+// combustionOrbs are how close (in degrees) each planet has to be to the Sun
+// to be combust, direct and retrograde (Surya Siddhanta)
+var combustionOrbs = map[pointid.PointID]struct{ direct, retrograde float64 }{
+	pointid.Moon:    {12, 12},
+	pointid.Mars:    {17, 17},
+	pointid.Mercury: {14, 12},
+	pointid.Jupiter: {11, 11},
+	pointid.Venus:   {10, 8},
+	pointid.Saturn:  {15, 15},
+}
+
+// warPlanets are the planets that can be in a planetary war (graha yuddha)
+var warPlanets = []pointid.PointID{
+	pointid.Mars,
+	pointid.Mercury,
+	pointid.Jupiter,
+	pointid.Venus,
+	pointid.Saturn,
+}
+
+// Dignity is the Vedic dignity of a planet in a chart
+type Dignity struct {
+	Planet    pointid.PointID `json:"planet"`
+	ChartType ChartType       `json:"chartType"`
+	// Sign is the sidereal sign of the planet, even in tropical charts
+	Sign sign.Sign `json:"sign"`
+	// Lord is the lord of Sign
+	Lord  pointid.PointID `json:"lord"`
+	State DignityState    `json:"state"`
+	// ExaltationStrength is from 0 at the deep debilitation point to 1 at
+	// the deep exaltation point, from the position in the chart
+	ExaltationStrength float64 `json:"exaltationStrength"`
+	// IsDeepExaltation and IsDeepDebilitation are true within a degree of
+	// the deep exaltation and debilitation points
+	IsDeepExaltation   bool `json:"isDeepExaltation"`
+	IsDeepDebilitation bool `json:"isDeepDebilitation"`
+	// IsVargottama is true if the planet is in the same sign in D1 and D9
+	IsVargottama bool `json:"isVargottama"`
+	// IsCombust is true if the planet is too close to the Sun. SunDistance
+	// is how far it is from it, in degrees.
+	IsCombust   bool    `json:"isCombust"`
+	SunDistance float64 `json:"sunDistance"`
+	// WarWith is the planet it's in a planetary war with (within a degree),
+	// or pointid.None
+	WarWith     pointid.PointID `json:"warWith"`
+	IsWarWinner bool            `json:"isWarWinner"`
+}
+
+func (d *Dignity) String() string {
+	return fmt.Sprintf(
+		"Dignity{Planet: %s, ChartType: %s, Sign: %s, State: %s, IsVargottama: %t, IsCombust: %t, WarWith: %s}",
+		d.Planet,
+		d.ChartType,
+		d.Sign,
+		d.State,
+		d.IsVargottama,
+		d.IsCombust,
+		d.WarWith,
+	)
+}
+
+// IsInWar reports whether the planet is in a planetary war
+func (d *Dignity) IsInWar() bool {
+	return d.WarWith != pointid.None
+}
+
+// angularDistance returns the shortest distance between two longitudes
+func angularDistance(a, b float64) float64 {
+	d := math.Abs(math.Mod(a-b+360, 360))
+	return math.Min(d, 360-d)
+}
+
+// GetDignity returns the Vedic dignity of pid, one of the seven planets or
+// the nodes, in c. The sign state comes from the position in c, so it works
+// for any chart type (tropical charts use the sidereal positions, since the
+// dignities are sidereal); combustion and planetary wars come from the actual
+// longitudes, so they're the same in every chart type.
+//
+// The yogas, the doshas and the sign dashas only look at the exaltation and
+// debilitation signs, so they keep using AstroPoint.IsExalted and
+// AstroPoint.IsFall.
+func (c *Chart) GetDignity(
+	swe *wrapper.SwissEph,
+	pid pointid.PointID,
+) (*Dignity, error) {
+	if _, ok := deepExaltation[pid]; !ok {
+		return nil, fmt.Errorf("no dignity for %s", pid)
+	}
+	p := c.GetPoint(pid)
+	if p == nil {
+		return nil, fmt.Errorf("%s not found in chart", pid)
+	}
+	pos, err := c.dignityLongitude(swe, pid)
+	if err != nil {
+		return nil, err
+	}
+	s := sign.DegreeToSign(pos)
+	ret := &Dignity{
+		Planet:    pid,
+		ChartType: c.ChartType,
+		Sign:      s,
+		Lord:      s.TraditionalRuler(),
+		WarWith:   pointid.None,
+	}
+
+	// Exaltation
+	deep := deepExaltation[pid]
+	ret.ExaltationStrength = 1 - angularDistance(pos, deep)/180
+	ret.IsDeepExaltation = angularDistance(pos, deep) < 1
+	ret.IsDeepDebilitation = angularDistance(pos, deep+180) < 1
+
+	// Sign state. The moolatrikona comes first: the Moon is only exalted
+	// in the first 3° of Taurus and Mercury in the first 15° of Virgo.
+	exalted, _ := ExaltationSign(pid)
+	debilitated, _ := sign.NewSignFromInt(exalted.Int() + 6)
+	switch {
+	case moolatrikona[pid].Contains(pos):
+		ret.State = DignityState_Moolatrikona
+	case ret.Sign == exalted:
+		ret.State = DignityState_Exalted
+	case slices.Contains(OwnSigns(pid), ret.Sign):
+		ret.State = DignityState_Own
+	case ret.Sign == debilitated:
+		ret.State = DignityState_Debilitated
+	default:
+		// If the lord isn't in the chart, fall back to the natural
+		// relationship
+		rel := NaturalRelationship(pid, ret.Lord)
+		if c.GetPoint(ret.Lord) != nil {
+			lordPos, err := c.dignityLongitude(swe, ret.Lord)
+			if err != nil {
+				return nil, err
+			}
+			rel = CombineRelationships(
+				rel,
+				TemporalRelationshipFromSigns(s, sign.DegreeToSign(lordPos)),
+			)
+		}
+		ret.State = map[Relationship]DignityState{
+			Relationship_GreatFriend: DignityState_GreatFriend,
+			Relationship_Friend:      DignityState_Friend,
+			Relationship_Neutral:     DignityState_Neutral,
+			Relationship_Enemy:       DignityState_Enemy,
+			Relationship_GreatEnemy:  DignityState_GreatEnemy,
+		}[rel]
+	}
+
+	// Vargottama
+	sidereal, err := c.siderealLongitude(swe, pid)
+	if err != nil {
+		return nil, fmt.Errorf("while getting sidereal longitude: %v", err)
+	}
+	d9, err := c.siderealVargaSign(pid, sidereal, D9ChartType)
+	if err != nil {
+		return nil, err
+	}
+	ret.IsVargottama = sign.DegreeToSign(sidereal) == d9
+
+	// Combustion
+	if sun := c.GetPoint(pointid.Sun); sun != nil && pid != pointid.Sun {
+		ret.SunDistance = angularDistance(p.Longitude, sun.Longitude)
+		if orbs, ok := combustionOrbs[pid]; ok {
+			orb := orbs.direct
+			if p.IsRetrograde {
+				orb = orbs.retrograde
+			}
+			ret.IsCombust = ret.SunDistance < orb
+		}
+	}
+
+	// Planetary war
+	if slices.Contains(warPlanets, pid) {
+		for _, other := range warPlanets {
+			o := c.GetPoint(other)
+			if other == pid || o == nil ||
+				angularDistance(p.Longitude, o.Longitude) >= 1 {
+				continue
+			}
+			ret.WarWith = other
+			winner, err := c.WarWinner(swe, pid, other)
+			if err != nil {
+				return nil, fmt.Errorf("while finding winner of war of %s and %s: %v", pid, other, err)
+			}
+			ret.IsWarWinner = winner == pid
+			break
+		}
+	}
+	return ret, nil
+}
+
+// dignityLongitude returns the longitude of pid in c that its dignity comes
+// from: the sidereal one in tropical charts, and the one in c otherwise
+func (c *Chart) dignityLongitude(
+	swe *wrapper.SwissEph,
+	pid pointid.PointID,
+) (float64, error) {
+	if c.ChartType == TropicalChartType {
+		lon, err := c.siderealLongitude(swe, pid)
+		if err != nil {
+			return 0, fmt.Errorf("while getting sidereal longitude: %v", err)
+		}
+		return lon, nil
+	}
+	p := c.GetPoint(pid)
+	if p == nil {
+		return 0, fmt.Errorf("%s not found in chart", pid)
+	}
+	return p.ZodiacalPos.AbsDegrees(), nil
+}
+
+// siderealVargaSign returns the sign of pid, at the sidereal longitude lon,
+// in chartType
+func (c *Chart) siderealVargaSign(
+	pid pointid.PointID,
+	lon float64,
+	chartType ChartType,
+) (sign.Sign, error) {
+	zp, err := transformZodiacalPosToVarga(
+		pid,
+		zodiacalpos.NewZodiacalPosFromLongitude(lon),
+		chartType,
+	)
+	if err != nil {
+		return "", fmt.Errorf("while transforming %s to %s: %v", pid, chartType, err)
+	}
+	return zp.Sign, nil
+}
+
+// WarWinner returns the winner of the planetary war between a and b: the
+// planet further north (with the greater declination) wins (Surya
+// Siddhanta 7). It's the rule of both Dignity.IsWarWinner and the yuddha
+// bala of Shadbala. Other schools make the brighter planet, or Venus always,
+// the winner.
+func (c *Chart) WarWinner(
+	swe *wrapper.SwissEph,
+	a, b pointid.PointID,
+) (pointid.PointID, error) {
+	jd := swe.GoTimeToJulianDay(c.Time.Time.UTC())
+	declA, err := swe.Declination(jd, a.SwissEphID())
+	if err != nil {
+		return pointid.None, err
+	}
+	declB, err := swe.Declination(jd, b.SwissEphID())
+	if err != nil {
+		return pointid.None, err
+	}
+	if declA > declB {
+		return a, nil
+	}
+	return b, nil
+}
+
+// Dignities returns the dignities of the seven planets and the nodes in c,
+// skipping the ones c doesn't have
+func (c *Chart) Dignities(swe *wrapper.SwissEph) ([]*Dignity, error) {
+	ret := []*Dignity{}
+	for _, pid := range pointid.VedicPlanets {
+		if _, ok := deepExaltation[pid]; !ok || c.GetPoint(pid) == nil {
+			continue
+		}
+		d, err := c.GetDignity(swe, pid)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, d)
+	}
+	return ret, nil
+}
@@ -0,0 +1,123 @@
+package chart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoolatrikona(t *testing.T) {
+	// The Moon is exalted in the first 3° of Taurus and in its moolatrikona
+	// after that
+	mt, ok := Moolatrikona(pointid.Moon)
+	require.True(t, ok)
+	require.False(t, mt.Contains(32))
+	require.True(t, mt.Contains(33))
+	require.False(t, mt.Contains(60))
+
+	s, ok := ExaltationSign(pointid.Rahu)
+	require.True(t, ok)
+	require.Equal(t, sign.Taurus, s)
+	_, ok = ExaltationSign(pointid.ASC)
+	require.False(t, ok)
+	require.Equal(t, []sign.Sign{sign.Aries, sign.Scorpio}, OwnSigns(pointid.Mars))
+}
+
+func TestGetDignity(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+	// London coordinates
+	lon, lat := -0.1278, 51.5074
+
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d1, err := NewChartFromUTC(swe, date, lon, lat, D1ChartType, pointid.VedicPlanets)
+	require.NoError(t, err)
+	for pid, expected := range map[pointid.PointID]DignityState{
+		// Aquarius 9°03'
+		pointid.Saturn: DignityState_Moolatrikona,
+		// Jupiter is a natural friend of the Sun, but a temporal enemy in
+		// Aries, 5th from Sagittarius
+		pointid.Sun:   DignityState_Neutral,
+		pointid.Venus: DignityState_Friend,
+		pointid.Rahu:  DignityState_Friend,
+	} {
+		d, err := d1.GetDignity(swe, pid)
+		require.NoError(t, err)
+		require.Equal(t, expected, d.State, pid)
+	}
+	// Mars is 12°44' from the Sun, in its 17° orb
+	mars, err := d1.GetDignity(swe, pointid.Mars)
+	require.NoError(t, err)
+	require.True(t, mars.IsCombust)
+	require.InDelta(t, 12.73, mars.SunDistance, 0.01)
+	// Mercury is 17°45' from the Sun, out of its 12° retrograde orb
+	mercury, err := d1.GetDignity(swe, pointid.Mercury)
+	require.NoError(t, err)
+	require.False(t, mercury.IsCombust)
+	require.False(t, mercury.IsInWar())
+	// The nodes are in Pisces and Virgo in both D1 and D9
+	rahu, err := d1.GetDignity(swe, pointid.Rahu)
+	require.NoError(t, err)
+	require.True(t, rahu.IsVargottama)
+
+	// Same planets in D9
+	d9, err := NewChartFromUTC(swe, date, lon, lat, D9ChartType, pointid.VedicPlanets)
+	require.NoError(t, err)
+	ds, err := d9.Dignities(swe)
+	require.NoError(t, err)
+	require.Len(t, ds, len(pointid.VedicPlanets))
+	for _, d := range ds {
+		require.Equal(t, D9ChartType, d.ChartType)
+		switch d.Planet {
+		case pointid.Jupiter:
+			require.Equal(t, DignityState_Exalted, d.State)
+		case pointid.Mercury, pointid.Venus:
+			require.Equal(t, DignityState_Debilitated, d.State)
+		case pointid.Rahu:
+			require.True(t, d.IsVargottama)
+		}
+	}
+
+	// A tropical chart has the same dignities: Saturn is in tropical Pisces,
+	// but it's still in its sidereal moolatrikona
+	tropical, err := NewChartFromUTC(swe, date, lon, lat, TropicalChartType, pointid.VedicPlanets)
+	require.NoError(t, err)
+	require.Equal(t, sign.Pisces, tropical.MustGetPoint(pointid.Saturn).ZodiacalPos.Sign)
+	for _, pid := range pointid.VedicPlanets {
+		expected, err := d1.GetDignity(swe, pid)
+		require.NoError(t, err)
+		actual, err := tropical.GetDignity(swe, pid)
+		require.NoError(t, err)
+		require.Equal(t, TropicalChartType, actual.ChartType)
+		require.Equal(t, expected.Sign, actual.Sign, pid)
+		require.Equal(t, expected.State, actual.State, pid)
+		// D1 positions are rounded to the minute
+		require.InDelta(t, expected.ExaltationStrength, actual.ExaltationStrength, 1e-4, pid)
+		require.Equal(t, expected.IsVargottama, actual.IsVargottama, pid)
+	}
+
+	// Saturn is in Aries 19°55', next to its deep debilitation point, and
+	// Mercury and Venus are 16' apart in Aquarius
+	date = time.Date(2000, 3, 16, 0, 0, 0, 0, time.UTC)
+	d1, err = NewChartFromUTC(swe, date, lon, lat, D1ChartType, pointid.VedicPlanets)
+	require.NoError(t, err)
+	saturn, err := d1.GetDignity(swe, pointid.Saturn)
+	require.NoError(t, err)
+	require.Equal(t, DignityState_Debilitated, saturn.State)
+	require.True(t, saturn.IsDeepDebilitation)
+	require.InDelta(t, 0, saturn.ExaltationStrength, 0.001)
+	mercury, err = d1.GetDignity(swe, pointid.Mercury)
+	require.NoError(t, err)
+	venus, err := d1.GetDignity(swe, pointid.Venus)
+	require.NoError(t, err)
+	require.Equal(t, pointid.Venus, mercury.WarWith)
+	require.Equal(t, pointid.Mercury, venus.WarWith)
+	require.NotEqual(t, mercury.IsWarWinner, venus.IsWarWinner)
+
+	_, err = d1.GetDignity(swe, pointid.ASC)
+	require.Error(t, err)
+}
@@ -0,0 +1,262 @@
+package chart
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+)
+
+// Drishti is the Vedic (Parashari) notion of an aspect. Unlike Western
+// aspects (see the aspect package), drishti is directional: a planet casts its
+// glance on the signs that are a given number of houses away from it,
+// regardless of the exact degrees. All planets aspect the 7th house from
+// themselves, and Mars, Jupiter and Saturn have special aspects on top of
+// that.
+//
+// Drishti strength is measured in virupas: 60 virupas is a full aspect.
+
+// NodeDrishti decides which aspects Rahu and Ketu cast, since the schools
+// don't agree on it
+type NodeDrishti int
+
+const (
+	// NodeDrishti_Jupiter gives the nodes the same aspects as Jupiter (5th,
+	// 7th and 9th). This is the most common view.
+	NodeDrishti_Jupiter NodeDrishti = iota
+	// NodeDrishti_Seventh gives the nodes the 7th house aspect only, like the
+	// other planets
+	NodeDrishti_Seventh
+	// NodeDrishti_None means the nodes cast no aspects at all, since they are
+	// shadow planets without a body (and so without eyes)
+	NodeDrishti_None
+)
+
+func (nd NodeDrishti) String() string {
+	switch nd {
+	case NodeDrishti_Jupiter:
+		return "jupiter"
+	case NodeDrishti_Seventh:
+		return "seventh"
+	case NodeDrishti_None:
+		return "none"
+	}
+	return "unknown"
+}
+
+// FullDrishtiVirupas is the strength of a full aspect
+const FullDrishtiVirupas = 60.0
+
+type Drishti struct {
+	From pointid.PointID `json:"from"`
+	To   pointid.PointID `json:"to"`
+	// HouseDistance is the house of To counted from From (e.g., 7 for an
+	// opposition)
+	HouseDistance int `json:"houseDistance"`
+	// Virupas is the strength of the aspect from 0 to 60, based on the exact
+	// angle between the two points
+	Virupas float64 `json:"virupas"`
+}
+
+func (d *Drishti) String() string {
+	return fmt.Sprintf(
+		"Drishti{From: %s, To: %s, HouseDistance: %d, Virupas: %f}",
+		d.From,
+		d.To,
+		d.HouseDistance,
+		d.Virupas,
+	)
+}
+
+// DrishtiHouses returns the houses (counted from the planet itself) that pid
+// fully aspects. The ascendant and other non-planets cast no aspects.
+func DrishtiHouses(pid pointid.PointID, nd NodeDrishti) []int {
+	switch pid {
+	case pointid.Sun, pointid.Moon, pointid.Mercury, pointid.Venus:
+		return []int{7}
+	case pointid.Mars:
+		return []int{4, 7, 8}
+	case pointid.Jupiter:
+		return []int{5, 7, 9}
+	case pointid.Saturn:
+		return []int{3, 7, 10}
+	case pointid.Rahu, pointid.Ketu:
+		switch nd {
+		case NodeDrishti_Jupiter:
+			return []int{5, 7, 9}
+		case NodeDrishti_Seventh:
+			return []int{7}
+		}
+	}
+	return nil
+}
+
+// AspectedSigns returns the signs the point pid fully aspects in this chart
+func (c *Chart) AspectedSigns(pid pointid.PointID, nd NodeDrishti) []sign.Sign {
+	p := c.GetPoint(pid)
+	if p == nil {
+		return nil
+	}
+	ret := []sign.Sign{}
+	for _, h := range DrishtiHouses(pid, nd) {
+		s, err := sign.NewSignFromInt(p.ZodiacalPos.Sign.Int() + h - 1)
+		if err != nil {
+			panic(err)
+		}
+		ret = append(ret, s)
+	}
+	return ret
+}
+
+// GetDrishti returns the sign-based (full) aspect that "from" casts on "to",
+// or nil if there's none
+func (c *Chart) GetDrishti(from, to pointid.PointID, nd NodeDrishti) *Drishti {
+	if from == to {
+		return nil
+	}
+	fromPoint := c.GetPoint(from)
+	toPoint := c.GetPoint(to)
+	if fromPoint == nil || toPoint == nil {
+		return nil
+	}
+	dist := house.NewHouseFromSign(
+		toPoint.ZodiacalPos.Sign,
+		fromPoint.ZodiacalPos.Sign,
+	).Int()
+	for _, h := range DrishtiHouses(from, nd) {
+		if h != dist {
+			continue
+		}
+		return &Drishti{
+			From:          from,
+			To:            to,
+			HouseDistance: dist,
+			Virupas: DrishtiVirupas(
+				from,
+				fromPoint.ZodiacalPos.AbsDegrees(),
+				toPoint.ZodiacalPos.AbsDegrees(),
+			),
+		}
+	}
+	return nil
+}
+
+// HasDrishti reports whether "from" fully aspects "to" by sign
+func (c *Chart) HasDrishti(from, to pointid.PointID, nd NodeDrishti) bool {
+	return c.GetDrishti(from, to, nd) != nil
+}
+
+// AspectsHouse reports whether pid fully aspects the sign of house h
+func (c *Chart) AspectsHouse(pid pointid.PointID, h house.House, nd NodeDrishti) bool {
+	s := c.GetSignOfHouse(h)
+	for _, as := range c.AspectedSigns(pid, nd) {
+		if as == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Drishtis returns all the sign-based aspects between the points of this
+// chart. Special points (see IsSpecialPoint) are left out.
+func (c *Chart) Drishtis(nd NodeDrishti) []*Drishti {
+	ret := []*Drishti{}
+	for _, from := range c.Points {
+		if IsSpecialPoint(from.ID) {
+			continue
+		}
+		for _, to := range c.Points {
+			if IsSpecialPoint(to.ID) {
+				continue
+			}
+			if d := c.GetDrishti(from.ID, to.ID, nd); d != nil {
+				ret = append(ret, d)
+			}
+		}
+	}
+	return ret
+}
+
+// VirupaDrishtis is like Drishtis but it's based on the exact angle between
+// the points instead of their signs: every pair with a non-zero strength is
+// returned, with HouseDistance still counted by sign
+func (c *Chart) VirupaDrishtis() []*Drishti {
+	ret := []*Drishti{}
+	for _, from := range c.Points {
+		if from.ID == pointid.ASC || IsSpecialPoint(from.ID) {
+			continue
+		}
+		for _, to := range c.Points {
+			if from.ID == to.ID || IsSpecialPoint(to.ID) {
+				continue
+			}
+			v := DrishtiVirupas(
+				from.ID,
+				from.ZodiacalPos.AbsDegrees(),
+				to.ZodiacalPos.AbsDegrees(),
+			)
+			if v <= 0 {
+				continue
+			}
+			ret = append(ret, &Drishti{
+				From: from.ID,
+				To:   to.ID,
+				HouseDistance: house.NewHouseFromSign(
+					to.ZodiacalPos.Sign,
+					from.ZodiacalPos.Sign,
+				).Int(),
+				Virupas: v,
+			})
+		}
+	}
+	return ret
+}
+
+// DrishtiVirupas returns the strength (in virupas, from 0 to 60) of the aspect
+// a planet at fromLon casts on a point at toLon, following the drishti bala
+// rules of Brihat Parashara Hora Shastra: the strength peaks at the 7th house
+// (180 degrees) and Mars, Jupiter and Saturn get extra strength around their
+// special aspects
+func DrishtiVirupas(from pointid.PointID, fromLon, toLon float64) float64 {
+	angle := math.Mod(toLon-fromLon+360, 360)
+	var ret float64
+	switch {
+	case angle < 30:
+		ret = 0
+	case angle < 60:
+		ret = (angle - 30) / 2
+	case angle < 90:
+		ret = angle - 60 + 15
+	case angle < 120:
+		ret = (120-angle)/2 + 30
+	case angle < 150:
+		ret = 150 - angle
+	case angle < 180:
+		ret = (angle - 150) * 2
+	case angle < 300:
+		ret = (300 - angle) / 2
+	default:
+		ret = 0
+	}
+
+	switch from {
+	case pointid.Mars:
+		if (angle >= 90 && angle < 120) || (angle >= 210 && angle < 240) {
+			ret += 15
+		}
+	case pointid.Jupiter:
+		if (angle >= 120 && angle < 150) || (angle >= 240 && angle < 270) {
+			ret += 30
+		}
+	case pointid.Saturn:
+		if (angle >= 60 && angle < 90) || (angle >= 270 && angle < 300) {
+			ret += 45
+		}
+	}
+	// Some commentators let the special aspects go above a full aspect
+	// (e.g., Saturn just before the 4th house). We cap it to keep the scale
+	// comparable across planets
+	return math.Min(ret, FullDrishtiVirupas)
+}
@@ -0,0 +1,113 @@
+package chart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrishtiVirupas(t *testing.T) {
+	type testCase struct {
+		title    string
+		from     pointid.PointID
+		fromLon  float64
+		toLon    float64
+		expected float64
+	}
+	for _, tc := range []testCase{
+		{"same sign", pointid.Sun, 10, 20, 0},
+		{"exact opposition", pointid.Sun, 10, 190, 60},
+		{"opposition wraps around", pointid.Moon, 350, 170, 60},
+		{"sun on the 4th", pointid.Sun, 0, 90, 45},
+		{"sun on the 5th", pointid.Sun, 0, 120, 30},
+		{"sun on the 9th", pointid.Sun, 0, 240, 30},
+		{"mars on the 4th", pointid.Mars, 0, 90, 60},
+		{"mars on the 8th", pointid.Mars, 0, 210, 60},
+		{"jupiter on the 5th", pointid.Jupiter, 0, 120, 60},
+		{"jupiter on the 9th", pointid.Jupiter, 0, 240, 60},
+		{"saturn on the 3rd", pointid.Saturn, 0, 60, 60},
+		{"saturn on the 10th", pointid.Saturn, 0, 270, 60},
+		{"saturn is capped", pointid.Saturn, 0, 89, 60},
+		{"nothing on the 11th", pointid.Saturn, 0, 305, 0},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			require.InDelta(t,
+				tc.expected,
+				DrishtiVirupas(tc.from, tc.fromLon, tc.toLon),
+				0.0001,
+			)
+		})
+	}
+}
+
+func TestGetDrishti(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+
+	// London, 2024-01-01 00:00 UTC. See TestNewChart for the positions
+	chrt, err := NewChartFromUTC(
+		swe,
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		-0.1278, 51.5074,
+		D1ChartType,
+		pointid.VedicPlanets,
+	)
+	require.NoError(t, err)
+
+	type testCase struct {
+		title                 string
+		from, to              pointid.PointID
+		nd                    NodeDrishti
+		expectedHouseDistance int
+	}
+	for _, tc := range []testCase{
+		// Jupiter in Aries aspects Leo (5th), Libra (7th) and Sagittarius
+		// (9th)
+		{"jupiter 5th", pointid.Jupiter, pointid.Moon, NodeDrishti_Jupiter, 5},
+		{"jupiter 9th", pointid.Jupiter, pointid.Sun, NodeDrishti_Jupiter, 9},
+		// Saturn in Aquarius aspects Aries (3rd), Leo (7th) and Scorpio (10th)
+		{"saturn 3rd", pointid.Saturn, pointid.Jupiter, NodeDrishti_Jupiter, 3},
+		{"saturn 7th", pointid.Saturn, pointid.Moon, NodeDrishti_Jupiter, 7},
+		{"saturn 10th", pointid.Saturn, pointid.Venus, NodeDrishti_Jupiter, 10},
+		{"saturn 11th", pointid.Saturn, pointid.Sun, NodeDrishti_Jupiter, 0},
+		// Mars in Sagittarius aspects Pisces (4th)
+		{"mars 4th", pointid.Mars, pointid.Rahu, NodeDrishti_Jupiter, 4},
+		// Drishti isn't mutual: Rahu in Pisces doesn't aspect Sagittarius
+		{"rahu 10th", pointid.Rahu, pointid.Mars, NodeDrishti_Jupiter, 0},
+		// Rahu in Pisces aspects Scorpio (9th) only with NodeDrishti_Jupiter
+		{"rahu 9th", pointid.Rahu, pointid.Venus, NodeDrishti_Jupiter, 9},
+		{"rahu 9th seventh only", pointid.Rahu, pointid.Venus, NodeDrishti_Seventh, 0},
+		{"rahu 7th", pointid.Rahu, pointid.ASC, NodeDrishti_Seventh, 7},
+		{"rahu 7th no node drishti", pointid.Rahu, pointid.ASC, NodeDrishti_None, 0},
+		{"ascendant has no drishti", pointid.ASC, pointid.Rahu, NodeDrishti_Jupiter, 0},
+		{"sun 7th", pointid.Sun, pointid.Moon, NodeDrishti_Jupiter, 0},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			d := chrt.GetDrishti(tc.from, tc.to, tc.nd)
+			if tc.expectedHouseDistance == 0 {
+				require.Nil(t, d)
+				return
+			}
+			require.NotNil(t, d)
+			require.Equal(t, tc.expectedHouseDistance, d.HouseDistance)
+		})
+	}
+
+	// Jupiter at 11°23' Aries on the Moon at 11°48' Leo is almost exactly
+	// trine, which is a full special aspect
+	d := chrt.GetDrishti(pointid.Jupiter, pointid.Moon, NodeDrishti_Jupiter)
+	require.InDelta(t, 59.6, d.Virupas, 0.1)
+
+	require.Equal(t,
+		[]sign.Sign{sign.Aries, sign.Leo, sign.Scorpio},
+		chrt.AspectedSigns(pointid.Saturn, NodeDrishti_Jupiter),
+	)
+	// Leo is the 12th house from a Virgo ascendant
+	require.True(t, chrt.AspectsHouse(pointid.Saturn, house.House12, NodeDrishti_Jupiter))
+	require.False(t, chrt.AspectsHouse(pointid.Saturn, house.House1, NodeDrishti_Jupiter))
+}
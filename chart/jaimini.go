@@ -0,0 +1,285 @@
+package chart
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+)
+
+// Jaimini astrology (from the Jaimini Sutras) uses a different set of tools
+// than Parashari astrology:
+// - Chara (variable) karakas: significators decided by the degrees of the
+//   planets instead of fixed ones (e.g., the Sun is not always the father)
+// - Rashi drishti: signs aspect signs, and planets aspect through the signs
+//   they're in
+// - Arudha padas: the "image" of each house, i.e., how it's perceived
+// - Karakamsa: the navamsa sign of the atmakaraka
+
+type CharaKaraka string
+
+const (
+	CharaKaraka_Atma    CharaKaraka = "atmakaraka"
+	CharaKaraka_Amatya  CharaKaraka = "amatyakaraka"
+	CharaKaraka_Bhratri CharaKaraka = "bhratrikaraka"
+	CharaKaraka_Matri   CharaKaraka = "matrikaraka"
+	CharaKaraka_Pitri   CharaKaraka = "pitrikaraka"
+	CharaKaraka_Putra   CharaKaraka = "putrakaraka"
+	CharaKaraka_Gnati   CharaKaraka = "gnatikaraka"
+	CharaKaraka_Dara    CharaKaraka = "darakaraka"
+)
+
+func (ck CharaKaraka) String() string {
+	return string(ck)
+}
+
+// CharaKarakaScheme is the number of chara karakas to use
+type CharaKarakaScheme int
+
+const (
+	// CharaKarakaScheme_Seven uses the seven visible planets. There's no
+	// pitrikaraka in this scheme: the Sun is the father.
+	CharaKarakaScheme_Seven CharaKarakaScheme = 7
+	// CharaKarakaScheme_Eight adds Rahu, whose degrees are counted backwards
+	// since it's always retrograde
+	CharaKarakaScheme_Eight CharaKarakaScheme = 8
+)
+
+// Karakas returns the karakas of the scheme, from the highest degree to the
+// lowest
+func (s CharaKarakaScheme) Karakas() []CharaKaraka {
+	switch s {
+	case CharaKarakaScheme_Seven:
+		return []CharaKaraka{
+			CharaKaraka_Atma,
+			CharaKaraka_Amatya,
+			CharaKaraka_Bhratri,
+			CharaKaraka_Matri,
+			CharaKaraka_Putra,
+			CharaKaraka_Gnati,
+			CharaKaraka_Dara,
+		}
+	case CharaKarakaScheme_Eight:
+		return []CharaKaraka{
+			CharaKaraka_Atma,
+			CharaKaraka_Amatya,
+			CharaKaraka_Bhratri,
+			CharaKaraka_Matri,
+			CharaKaraka_Pitri,
+			CharaKaraka_Putra,
+			CharaKaraka_Gnati,
+			CharaKaraka_Dara,
+		}
+	}
+	panic(fmt.Sprintf("unknown chara karaka scheme: %d", s))
+}
+
+func (s CharaKarakaScheme) planets() []pointid.PointID {
+	planets := []pointid.PointID{
+		pointid.Sun,
+		pointid.Moon,
+		pointid.Mars,
+		pointid.Mercury,
+		pointid.Jupiter,
+		pointid.Venus,
+		pointid.Saturn,
+	}
+	if s == CharaKarakaScheme_Eight {
+		planets = append(planets, pointid.Rahu)
+	}
+	return planets
+}
+
+// CharaKarakas returns the planet of each chara karaka.
+//
+// The karakas are decided by the degrees of the planets in their D1 signs, so
+// any sidereal chart (D1 or a varga) gives the same result. Tropical charts
+// are rejected.
+func (c *Chart) CharaKarakas(
+	scheme CharaKarakaScheme,
+) (map[CharaKaraka]pointid.PointID, error) {
+	if c.ChartType == TropicalChartType {
+		return nil, fmt.Errorf("chara karakas need a sidereal chart")
+	}
+	karakas := scheme.Karakas()
+	planets := scheme.planets()
+	degrees := map[pointid.PointID]float64{}
+	for _, pid := range planets {
+		p := c.GetPoint(pid)
+		if p == nil {
+			return nil, fmt.Errorf("while getting chara karakas: %s not found in chart", pid)
+		}
+		// AstroPoint.Longitude is the D1 longitude even for varga charts,
+		// which is what we want here
+		deg := math.Mod(p.Longitude, 30)
+		if pid == pointid.Rahu {
+			deg = 30 - deg
+		}
+		degrees[pid] = deg
+	}
+	// Sort by degrees, highest first. Ties keep the order of planets, which
+	// is rare enough to not matter
+	slices.SortStableFunc(planets, func(lhs, rhs pointid.PointID) int {
+		if degrees[lhs] > degrees[rhs] {
+			return -1
+		}
+		if degrees[lhs] < degrees[rhs] {
+			return 1
+		}
+		return 0
+	})
+
+	ret := map[CharaKaraka]pointid.PointID{}
+	for i, karaka := range karakas {
+		ret[karaka] = planets[i]
+	}
+	return ret, nil
+}
+
+// Atmakaraka returns the planet with the highest degrees in its sign
+func (c *Chart) Atmakaraka(scheme CharaKarakaScheme) (pointid.PointID, error) {
+	karakas, err := c.CharaKarakas(scheme)
+	if err != nil {
+		return pointid.None, err
+	}
+	return karakas[CharaKaraka_Atma], nil
+}
+
+// Karakamsa returns the sign the atmakaraka (taken from d1) occupies in d9
+func Karakamsa(
+	d1, d9 *Chart,
+	scheme CharaKarakaScheme,
+) (sign.Sign, error) {
+	if d9.ChartType != D9ChartType {
+		return "", fmt.Errorf("karakamsa needs a D9 chart, got %s", d9.ChartType)
+	}
+	ak, err := d1.Atmakaraka(scheme)
+	if err != nil {
+		return "", fmt.Errorf("while getting atmakaraka: %v", err)
+	}
+	p := d9.GetPoint(ak)
+	if p == nil {
+		return "", fmt.Errorf("atmakaraka %s not found in D9 chart", ak)
+	}
+	return p.ZodiacalPos.Sign, nil
+}
+
+// RashiDrishti returns the signs that s aspects according to Jaimini:
+// - Movable signs aspect the fixed signs, except the one next to them
+// - Fixed signs aspect the movable signs, except the one before them
+// - Dual signs aspect the other dual signs
+func RashiDrishti(s sign.Sign) []sign.Sign {
+	i := s.Int()
+	ret := []sign.Sign{}
+	for j := 1; j <= 12; j++ {
+		if j == i {
+			continue
+		}
+		switch (i - 1) % 3 {
+		case 0: // Movable
+			if (j-1)%3 != 1 || j == i+1 {
+				continue
+			}
+		case 1: // Fixed
+			if (j-1)%3 != 0 || j == i-1 {
+				continue
+			}
+		case 2: // Dual
+			if (j-1)%3 != 2 {
+				continue
+			}
+		}
+		target, err := sign.NewSignFromInt(j)
+		if err != nil {
+			panic(err)
+		}
+		ret = append(ret, target)
+	}
+	return ret
+}
+
+// HasRashiDrishti reports whether the sign "from" aspects the sign "to".
+// Rashi drishti is always mutual.
+func HasRashiDrishti(from, to sign.Sign) bool {
+	return slices.Contains(RashiDrishti(from), to)
+}
+
+// PointsWithRashiDrishtiOn returns the points that aspect s through the signs
+// they're in. The ascendant and the special points are excluded.
+func (c *Chart) PointsWithRashiDrishtiOn(s sign.Sign) []pointid.PointID {
+	ret := []pointid.PointID{}
+	for _, p := range c.Points {
+		if p.ID == pointid.ASC || IsSpecialPoint(p.ID) {
+			continue
+		}
+		if HasRashiDrishti(p.ZodiacalPos.Sign, s) {
+			ret = append(ret, p.ID)
+		}
+	}
+	return ret
+}
+
+// ArudhaPada returns the sign of the arudha pada of house h: count the
+// houses from h to its lord, then count as many from the lord. If that lands
+// on h itself or on the 7th from h, the 10th from there is taken instead.
+//
+// The arudha pada of the 1st house is the arudha lagna (AL) and the one of the
+// 12th house is the upapada lagna (UL).
+func (c *Chart) ArudhaPada(h house.House) (sign.Sign, error) {
+	// XXX <19-10-2026, agent> Scorpio and Aquarius have two lords in Jaimini
+	// (Mars/Ketu and Saturn/Rahu) and the stronger of the two should be
+	// picked. We only use the traditional lord for now
+	lordID, err := c.GetHouseLordFor(h, HouseLordPlacement_Traditional)
+	if err != nil {
+		return "", fmt.Errorf("while getting lord of %s house: %v", h, err)
+	}
+	lord := c.GetPoint(lordID)
+	if lord == nil {
+		return "", fmt.Errorf("lord %s of %s house not found in chart", lordID, h)
+	}
+	houseSign := c.GetSignOfHouse(h)
+	lordSign := lord.ZodiacalPos.Sign
+	dist := house.NewHouseFromSign(lordSign, houseSign).Int()
+	pada, err := sign.NewSignFromInt(lordSign.Int() + dist - 1)
+	if err != nil {
+		return "", fmt.Errorf("while getting arudha pada of %s house: %v", h, err)
+	}
+	padaDist := house.NewHouseFromSign(pada, houseSign).Int()
+	if padaDist == 1 || padaDist == 7 {
+		pada, err = sign.NewSignFromInt(pada.Int() + 9)
+		if err != nil {
+			return "", fmt.Errorf("while getting arudha pada of %s house: %v", h, err)
+		}
+	}
+	return pada, nil
+}
+
+// ArudhaPadas returns the arudha padas of all houses. See ArudhaPada.
+func (c *Chart) ArudhaPadas() (map[house.House]sign.Sign, error) {
+	ret := map[house.House]sign.Sign{}
+	for i := 1; i <= 12; i++ {
+		h, err := house.HouseFromInt(i)
+		if err != nil {
+			return nil, err
+		}
+		pada, err := c.ArudhaPada(h)
+		if err != nil {
+			return nil, err
+		}
+		ret[h] = pada
+	}
+	return ret, nil
+}
+
+// ArudhaLagna returns the arudha pada of the 1st house
+func (c *Chart) ArudhaLagna() (sign.Sign, error) {
+	return c.ArudhaPada(house.House1)
+}
+
+// UpapadaLagna returns the arudha pada of the 12th house
+func (c *Chart) UpapadaLagna() (sign.Sign, error) {
+	return c.ArudhaPada(house.House12)
+}
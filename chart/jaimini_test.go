@@ -0,0 +1,146 @@
+package chart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJaimini(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+
+	// London, 2024-01-01 00:00 UTC. See TestNewChart for the positions
+	birthTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d1, err := NewChartFromUTC(
+		swe, birthTime, -0.1278, 51.5074, D1ChartType, pointid.VedicPlanets)
+	require.NoError(t, err)
+	d9, err := NewChartFromUTC(
+		swe, birthTime, -0.1278, 51.5074, D9ChartType, pointid.VedicPlanets)
+	require.NoError(t, err)
+
+	t.Run("chara karakas (7)", func(t *testing.T) {
+		karakas, err := d1.CharaKarakas(CharaKarakaScheme_Seven)
+		require.NoError(t, err)
+		require.Equal(t, map[CharaKaraka]pointid.PointID{
+			CharaKaraka_Atma:    pointid.Mercury, // 28°05'
+			CharaKaraka_Amatya:  pointid.Sun,     // 15°50'
+			CharaKaraka_Bhratri: pointid.Moon,    // 11°48'
+			CharaKaraka_Matri:   pointid.Jupiter, // 11°23'
+			CharaKaraka_Putra:   pointid.Saturn,  // 9°03'
+			CharaKaraka_Gnati:   pointid.Venus,   // 8°25'
+			CharaKaraka_Dara:    pointid.Mars,    // 3°07'
+		}, karakas)
+	})
+
+	t.Run("chara karakas (8)", func(t *testing.T) {
+		karakas, err := d1.CharaKarakas(CharaKarakaScheme_Eight)
+		require.NoError(t, err)
+		require.Equal(t, map[CharaKaraka]pointid.PointID{
+			CharaKaraka_Atma:    pointid.Mercury,
+			CharaKaraka_Amatya:  pointid.Sun,
+			CharaKaraka_Bhratri: pointid.Moon,
+			CharaKaraka_Matri:   pointid.Jupiter,
+			CharaKaraka_Pitri:   pointid.Saturn,
+			CharaKaraka_Putra:   pointid.Venus,
+			CharaKaraka_Gnati:   pointid.Mars,
+			// Rahu at 26°53' Pisces counts as 3°06'
+			CharaKaraka_Dara: pointid.Rahu,
+		}, karakas)
+	})
+
+	t.Run("varga charts use D1 degrees", func(t *testing.T) {
+		lhs, err := d1.CharaKarakas(CharaKarakaScheme_Eight)
+		require.NoError(t, err)
+		rhs, err := d9.CharaKarakas(CharaKarakaScheme_Eight)
+		require.NoError(t, err)
+		require.Equal(t, lhs, rhs)
+	})
+
+	t.Run("karakamsa", func(t *testing.T) {
+		// Mercury is in Pisces in the D9
+		s, err := Karakamsa(d1, d9, CharaKarakaScheme_Seven)
+		require.NoError(t, err)
+		require.Equal(t, sign.Pisces, s)
+
+		_, err = Karakamsa(d1, d1, CharaKarakaScheme_Seven)
+		require.Error(t, err)
+	})
+
+	t.Run("arudha padas", func(t *testing.T) {
+		padas, err := d1.ArudhaPadas()
+		require.NoError(t, err)
+		require.Equal(t, map[house.House]sign.Sign{
+			// Virgo's lord (Mercury) is 3 signs away in Scorpio
+			house.House1: sign.Capricorn,
+			house.House2: sign.Sagittarius,
+			house.House3: sign.Capricorn,
+			house.House4: sign.Leo,
+			house.House5: sign.Pisces,
+			// Aquarius' lord (Saturn) is in Aquarius so the pada falls in the
+			// house itself: the 10th from it is taken instead
+			house.House6: sign.Scorpio,
+			house.House7: sign.Taurus,
+			house.House8: sign.Leo,
+			// Taurus' lord (Venus) is 7 signs away so the pada falls in the
+			// house itself again
+			house.House9:  sign.Aquarius,
+			house.House10: sign.Aries,
+			house.House11: sign.Virgo,
+			house.House12: sign.Aries,
+		}, padas)
+
+		al, err := d1.ArudhaLagna()
+		require.NoError(t, err)
+		require.Equal(t, sign.Capricorn, al)
+		ul, err := d1.UpapadaLagna()
+		require.NoError(t, err)
+		require.Equal(t, sign.Aries, ul)
+	})
+
+	t.Run("rashi drishti", func(t *testing.T) {
+		require.Equal(t,
+			[]sign.Sign{sign.Leo, sign.Scorpio, sign.Aquarius},
+			RashiDrishti(sign.Aries),
+		)
+		require.Equal(t,
+			[]sign.Sign{sign.Cancer, sign.Libra, sign.Capricorn},
+			RashiDrishti(sign.Taurus),
+		)
+		require.Equal(t,
+			[]sign.Sign{sign.Virgo, sign.Sagittarius, sign.Pisces},
+			RashiDrishti(sign.Gemini),
+		)
+		require.Equal(t,
+			[]sign.Sign{sign.Aries, sign.Cancer, sign.Libra},
+			RashiDrishti(sign.Aquarius),
+		)
+		for i := 1; i <= 12; i++ {
+			from, err := sign.NewSignFromInt(i)
+			require.NoError(t, err)
+			for _, to := range RashiDrishti(from) {
+				require.True(t, HasRashiDrishti(to, from))
+			}
+		}
+
+		// Scorpio is aspected by Aries, Cancer and Capricorn, and only
+		// Jupiter (in Aries) is in one of them
+		require.Equal(t,
+			[]pointid.PointID{pointid.Jupiter},
+			d1.PointsWithRashiDrishtiOn(sign.Scorpio),
+		)
+	})
+
+	t.Run("tropical charts are rejected", func(t *testing.T) {
+		tropical, err := NewChartFromUTC(
+			swe, birthTime, -0.1278, 51.5074, TropicalChartType, pointid.VedicPlanets)
+		require.NoError(t, err)
+		_, err = tropical.CharaKarakas(CharaKarakaScheme_Seven)
+		require.Error(t, err)
+	})
+}
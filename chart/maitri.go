@@ -0,0 +1,138 @@
+package chart
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+)
+
+// Relationship is how a planet sees another one (maitri). Natural and
+// temporal relationships are only Friend, Neutral (natural only) or Enemy;
+// the five-fold (panchadha) one adds them up.
+type Relationship int
+
+const (
+	Relationship_GreatEnemy  Relationship = -2 // adhi-shatru
+	Relationship_Enemy       Relationship = -1 // shatru
+	Relationship_Neutral     Relationship = 0  // sama
+	Relationship_Friend      Relationship = 1  // mitra
+	Relationship_GreatFriend Relationship = 2  // adhi-mitra
+)
+
+func (r Relationship) String() string {
+	switch r {
+	case Relationship_GreatEnemy:
+		return "great-enemy"
+	case Relationship_Enemy:
+		return "enemy"
+	case Relationship_Neutral:
+		return "neutral"
+	case Relationship_Friend:
+		return "friend"
+	case Relationship_GreatFriend:
+		return "great-friend"
+	}
+	return "unknown"
+}
+
+func (r Relationship) IsFriendly() bool {
+	return r > Relationship_Neutral
+}
+
+func (r Relationship) IsInimical() bool {
+	return r < Relationship_Neutral
+}
+
+// The relationships live here and not in rulership so that Chart can expose
+// them: rulership imports chart. rulership.CalculateMaitri builds the full
+// table of a chart.
+
+// naturalFriends and naturalEnemies are the naisargika relationships of the
+// seven planets (BPHS 3.55) and the nodes. Planets in neither are neutral.
+//
+// BPHS doesn't give the nodes' relationships and sources vary. We follow the
+// most common table, and the seven planets stay neutral to the nodes.
+var (
+	naturalFriends = map[pointid.PointID][]pointid.PointID{
+		pointid.Sun:     {pointid.Moon, pointid.Mars, pointid.Jupiter},
+		pointid.Moon:    {pointid.Sun, pointid.Mercury},
+		pointid.Mars:    {pointid.Sun, pointid.Moon, pointid.Jupiter},
+		pointid.Mercury: {pointid.Sun, pointid.Venus},
+		pointid.Jupiter: {pointid.Sun, pointid.Moon, pointid.Mars},
+		pointid.Venus:   {pointid.Mercury, pointid.Saturn},
+		pointid.Saturn:  {pointid.Mercury, pointid.Venus},
+		pointid.Rahu:    {pointid.Mercury, pointid.Venus, pointid.Saturn},
+		pointid.Ketu:    {pointid.Mars, pointid.Venus, pointid.Saturn},
+	}
+	naturalEnemies = map[pointid.PointID][]pointid.PointID{
+		pointid.Sun:     {pointid.Venus, pointid.Saturn},
+		pointid.Mars:    {pointid.Mercury},
+		pointid.Mercury: {pointid.Moon},
+		pointid.Jupiter: {pointid.Mercury, pointid.Venus},
+		pointid.Venus:   {pointid.Sun, pointid.Moon},
+		pointid.Saturn:  {pointid.Sun, pointid.Moon, pointid.Mars},
+		pointid.Rahu:    {pointid.Sun, pointid.Moon, pointid.Mars},
+		pointid.Ketu:    {pointid.Sun, pointid.Moon},
+	}
+)
+
+// NaturalRelationship returns how pid sees other by nature (naisargika
+// maitri). It's not symmetric: the Moon is a friend of Mercury, but Mercury
+// is an enemy of the Moon. Points other than the seven planets and the
+// nodes are neutral.
+func NaturalRelationship(pid, other pointid.PointID) Relationship {
+	switch {
+	case slices.Contains(naturalFriends[pid], other):
+		return Relationship_Friend
+	case slices.Contains(naturalEnemies[pid], other):
+		return Relationship_Enemy
+	}
+	return Relationship_Neutral
+}
+
+// TemporalRelationshipFromSigns returns the temporal (tatkalika) relationship
+// of a planet in pidSign with one in otherSign: planets in the 2nd, 3rd, 4th,
+// 10th, 11th and 12th signs from each other are friends, and enemies
+// otherwise. It's symmetric.
+func TemporalRelationshipFromSigns(pidSign, otherSign sign.Sign) Relationship {
+	switch (otherSign.Int() - pidSign.Int() + 12) % 12 {
+	case 1, 2, 3, 9, 10, 11:
+		return Relationship_Friend
+	}
+	return Relationship_Enemy
+}
+
+// CombineRelationships returns the five-fold (panchadha) relationship from a
+// natural and a temporal one
+func CombineRelationships(natural, temporal Relationship) Relationship {
+	return natural + temporal
+}
+
+// TemporalRelationship returns the temporal relationship of pid with other
+// from their signs in c
+func (c *Chart) TemporalRelationship(
+	pid, other pointid.PointID,
+) (Relationship, error) {
+	p, o := c.GetPoint(pid), c.GetPoint(other)
+	if p == nil {
+		return Relationship_Neutral, fmt.Errorf("%s not found in chart", pid)
+	}
+	if o == nil {
+		return Relationship_Neutral, fmt.Errorf("%s not found in chart", other)
+	}
+	return TemporalRelationshipFromSigns(p.ZodiacalPos.Sign, o.ZodiacalPos.Sign), nil
+}
+
+// PanchadhaRelationship returns the five-fold relationship of pid with other:
+// their natural relationship combined with their temporal one in c
+func (c *Chart) PanchadhaRelationship(
+	pid, other pointid.PointID,
+) (Relationship, error) {
+	temporal, err := c.TemporalRelationship(pid, other)
+	if err != nil {
+		return Relationship_Neutral, err
+	}
+	return CombineRelationships(NaturalRelationship(pid, other), temporal), nil
+}
@@ -0,0 +1,96 @@
+package chart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNaturalRelationship(t *testing.T) {
+	// Not symmetric
+	require.Equal(t, Relationship_Friend, NaturalRelationship(pointid.Moon, pointid.Mercury))
+	require.Equal(t, Relationship_Enemy, NaturalRelationship(pointid.Mercury, pointid.Moon))
+	require.Equal(t, Relationship_Neutral, NaturalRelationship(pointid.Saturn, pointid.Jupiter))
+	// The Moon has no enemies
+	for _, pid := range []pointid.PointID{
+		pointid.Sun,
+		pointid.Mars,
+		pointid.Mercury,
+		pointid.Jupiter,
+		pointid.Venus,
+		pointid.Saturn,
+	} {
+		require.False(t, NaturalRelationship(pointid.Moon, pid).IsInimical())
+	}
+	require.Equal(t, Relationship_Enemy, NaturalRelationship(pointid.Rahu, pointid.Sun))
+	require.Equal(t, Relationship_Neutral, NaturalRelationship(pointid.Sun, pointid.Rahu))
+	require.Equal(t, Relationship_Neutral, NaturalRelationship(pointid.ASC, pointid.Sun))
+}
+
+func TestTemporalRelationshipFromSigns(t *testing.T) {
+	for _, tc := range []struct {
+		other    sign.Sign
+		expected Relationship
+	}{
+		{sign.Aries, Relationship_Enemy},
+		{sign.Taurus, Relationship_Friend},
+		{sign.Gemini, Relationship_Friend},
+		{sign.Cancer, Relationship_Friend},
+		{sign.Leo, Relationship_Enemy},
+		{sign.Virgo, Relationship_Enemy},
+		{sign.Libra, Relationship_Enemy},
+		{sign.Scorpio, Relationship_Enemy},
+		{sign.Sagittarius, Relationship_Enemy},
+		{sign.Capricorn, Relationship_Friend},
+		{sign.Aquarius, Relationship_Friend},
+		{sign.Pisces, Relationship_Friend},
+	} {
+		require.Equal(t, tc.expected, TemporalRelationshipFromSigns(sign.Aries, tc.other), tc.other)
+		require.Equal(t, tc.expected, TemporalRelationshipFromSigns(tc.other, sign.Aries), tc.other)
+	}
+}
+
+func TestChart_PanchadhaRelationship(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+	chrt, err := NewChartFromUTC(
+		swe,
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		-0.1278, 51.5074,
+		D1ChartType,
+		pointid.VedicPlanets,
+	)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		pid, other pointid.PointID
+		temporal   Relationship
+		panchadha  Relationship
+	}{
+		// Mercury in Scorpio is 4th from the Moon in Leo
+		{pointid.Moon, pointid.Mercury, Relationship_Friend, Relationship_GreatFriend},
+		{pointid.Mercury, pointid.Moon, Relationship_Friend, Relationship_Neutral},
+		// Venus in Scorpio is 8th from Jupiter in Aries
+		{pointid.Jupiter, pointid.Venus, Relationship_Enemy, Relationship_GreatEnemy},
+		// The Moon in Leo is 9th from the Sun in Sagittarius
+		{pointid.Sun, pointid.Moon, Relationship_Enemy, Relationship_Neutral},
+		// Mercury in Scorpio is 12th from the Sun in Sagittarius
+		{pointid.Sun, pointid.Mercury, Relationship_Friend, Relationship_Friend},
+		// The Sun and Mars are together in Sagittarius
+		{pointid.Sun, pointid.Mars, Relationship_Enemy, Relationship_Neutral},
+	} {
+		temporal, err := chrt.TemporalRelationship(tc.pid, tc.other)
+		require.NoError(t, err)
+		require.Equal(t, tc.temporal, temporal, "%s -> %s", tc.pid, tc.other)
+		panchadha, err := chrt.PanchadhaRelationship(tc.pid, tc.other)
+		require.NoError(t, err)
+		require.Equal(t, tc.panchadha, panchadha, "%s -> %s", tc.pid, tc.other)
+	}
+
+	_, err = chrt.PanchadhaRelationship(pointid.Sun, pointid.Uranus)
+	require.Error(t, err)
+}
@@ -5,7 +5,7 @@ import (
 	"math"
 	"time"
 
-	"github.com/afjoseph/sacredstar/astropoint"
+	"github.com/afjoseph/sacredstar/pointid"
 	"github.com/afjoseph/sacredstar/wrapper"
 	"github.com/afjoseph/sacredstar/zodiacalpos"
 )
@@ -172,22 +172,141 @@ func NewNakshatraTypeFromInt(i int) (NakshatraType, error) {
 	return NakshatraNone, fmt.Errorf("invalid nakshatra %d", i)
 }
 
+// Lord returns the planet ruling the nakshatra, which is also the lord of the
+// Vimshottari mahadasha running when the Moon is in it
+func (n NakshatraType) Lord() DashaLord {
+	switch n {
+	case Aswini, Magha, Mula:
+		return DashaLordKetu
+	case Bharani, PurvaPhalguni, PurvaAshadha:
+		return DashaLordVenus
+	case Krittika, UttaraPhalguni, UttaraAshadha:
+		return DashaLordSun
+	case Rohini, Hasta, Shravana:
+		return DashaLordMoon
+	case Mrigashirsha, Chitra, Dhanishta:
+		return DashaLordMars
+	case Ardra, Swati, Shatabhisha:
+		return DashaLordRahu
+	case Punarvasu, Vishakha, PurvaBhadrapada:
+		return DashaLordJupiter
+	case Pushya, Anuradha, UttaraBhadrapada:
+		return DashaLordSaturn
+	case Ashlesha, Jyeshtha, Revati:
+		return DashaLordMercury
+	}
+	panic(fmt.Sprintf("unknown nakshatra %s", n))
+}
+
+// Deity returns the presiding deity of the nakshatra
+func (n NakshatraType) Deity() string {
+	switch n {
+	case Aswini:
+		return "Ashvini Kumaras"
+	case Bharani:
+		return "Yama"
+	case Krittika:
+		return "Agni"
+	case Rohini:
+		return "Prajapati"
+	case Mrigashirsha:
+		return "Soma"
+	case Ardra:
+		return "Rudra"
+	case Punarvasu:
+		return "Aditi"
+	case Pushya:
+		return "Brihaspati"
+	case Ashlesha:
+		return "Sarpas"
+	case Magha:
+		return "Pitris"
+	case PurvaPhalguni:
+		return "Bhaga"
+	case UttaraPhalguni:
+		return "Aryaman"
+	case Hasta:
+		return "Savitr"
+	case Chitra:
+		return "Tvashtr"
+	case Swati:
+		return "Vayu"
+	case Vishakha:
+		return "Indragni"
+	case Anuradha:
+		return "Mitra"
+	case Jyeshtha:
+		return "Indra"
+	case Mula:
+		return "Nirriti"
+	case PurvaAshadha:
+		return "Apas"
+	case UttaraAshadha:
+		return "Vishvedevas"
+	case Shravana:
+		return "Vishnu"
+	case Dhanishta:
+		return "Vasus"
+	case Shatabhisha:
+		return "Varuna"
+	case PurvaBhadrapada:
+		return "Aja Ekapada"
+	case UttaraBhadrapada:
+		return "Ahir Budhnya"
+	case Revati:
+		return "Pushan"
+	}
+	panic(fmt.Sprintf("unknown nakshatra %s", n))
+}
+
+const (
+	// NakshatraSpan is the length of a nakshatra, in degrees (13°20')
+	NakshatraSpan = 360.0 / 27.0
+	// PadaSpan is the length of a pada (a quarter of a nakshatra), in
+	// degrees (3°20')
+	PadaSpan = NakshatraSpan / 4
+)
+
 type Nakshatra struct {
 	Type NakshatraType
+	// Pada is the quarter of the nakshatra, from 0 to 3. Note that the texts
+	// count padas from 1: see PadaNumber.
 	Pada int
 }
 
+// PadaNumber returns the pada as the texts count it, from 1 to 4
+func (n *Nakshatra) PadaNumber() int {
+	return n.Pada + 1
+}
+
+// NewNakshatraFromChart returns the nakshatra of pid in c. Like
+// Chart.GetNakshatraDetails, it works for any chart type: the position of
+// pid in tropical charts is made sidereal with the chart's ayanamsa.
 func NewNakshatraFromChart(
 	swe *wrapper.SwissEph,
-	ap *astropoint.AstroPoint,
+	c *Chart,
+	pid pointid.PointID,
 ) (*Nakshatra, error) {
-	if ap == nil {
-		return nil, fmt.Errorf("astro point is nil")
+	if c == nil {
+		return nil, fmt.Errorf("chart is nil")
+	}
+	lon, err := c.siderealLongitude(swe, pid)
+	if err != nil {
+		return nil, fmt.Errorf("while getting sidereal longitude: %v", err)
 	}
+	return NewNakshatraFromSiderealLongitude(lon)
+}
 
+// NewNakshatraFromSiderealLongitude returns the nakshatra at the sidereal
+// longitude lon
+func NewNakshatraFromSiderealLongitude(lon float64) (*Nakshatra, error) {
+	lon = math.Mod(lon, 360)
+	if lon < 0 {
+		lon += 360
+	}
 	// There are 27 nakshatras, each is 13.333333333333334 degrees
 	// long. The first nakshatra starts at 0 degrees Aries.
-	nakshatraIdx := int(ap.Longitude / 13.333333333333334)
+	nakshatraIdx := int(lon / NakshatraSpan)
 	nid, err := NewNakshatraTypeFromInt(nakshatraIdx)
 	if err != nil {
 		return nil, fmt.Errorf("NewNakshatraFromInt failed: %v", err)
@@ -195,34 +314,14 @@ func NewNakshatraFromChart(
 
 	pada := int(
 		math.Floor(
-			math.Mod(ap.Longitude, 13.333333333333334) / 3.3333333333333333,
+			math.Mod(lon, NakshatraSpan) / PadaSpan,
 		),
 	)
 	return &Nakshatra{nid, pada}, nil
 }
 
 func (n *Nakshatra) MahadashaLord() DashaLord {
-	switch n.Type {
-	case Aswini, Magha, Mula:
-		return DashaLordKetu
-	case Bharani, PurvaPhalguni, UttaraPhalguni:
-		return DashaLordVenus
-	case Krittika, UttaraAshadha, UttaraBhadrapada:
-		return DashaLordSun
-	case Rohini, Hasta, Shravana:
-		return DashaLordMoon
-	case Mrigashirsha, Chitra, Dhanishta:
-		return DashaLordMars
-	case Ardra, Swati, Shatabhisha:
-		return DashaLordRahu
-	case Punarvasu, Vishakha, PurvaBhadrapada:
-		return DashaLordJupiter
-	case Pushya, Anuradha:
-		return DashaLordSaturn
-	case Ashlesha, Jyeshtha, Revati:
-		return DashaLordMercury
-	}
-	panic(fmt.Sprintf("unknown nakshatra %s", n.Type))
+	return n.Type.Lord()
 }
 
 func (n *Nakshatra) MinZodiacalPos() *zodiacalpos.ZodiacalPos {
@@ -0,0 +1,325 @@
+package chart
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/tidwall/btree"
+)
+
+// NakshatraDashaTree is a dasha timeline for the systems whose sub-periods
+// are all proportional to the mahadasha years (e.g., Yogini and Ashtottari).
+// Vimshottari has its own tree (DashaTree) since its antardashas come from
+// tables.
+type NakshatraDashaTree struct {
+	system DashaSystem
+	// tree holds the mahadashas, sorted by start time
+	tree      *btree.BTreeG[DashaPeriod]
+	startTime time.Time
+}
+
+// NewYoginiDashaTree casts the Yogini dasha (8 lords, 36 years) from
+// birthTime. Like NewDashaTree, it covers 120 years from birth, so more than
+// three Yogini cycles.
+func NewYoginiDashaTree(
+	swe *wrapper.SwissEph,
+	birthTime time.Time,
+	natal *Chart,
+) (*NakshatraDashaTree, error) {
+	nak, percRemaining, err := calculateMoonNakshatraRemaining(
+		swe,
+		birthTime,
+		natal,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("while calculating Nakshatra: %v", err)
+	}
+	lord := YoginiLordForNakshatra(nak.Type)
+	remaining := yearsToDuration(
+		DashaSystem_Yogini.Years(lord) * percRemaining,
+	)
+	return newNakshatraDashaTree(
+		DashaSystem_Yogini,
+		birthTime,
+		lord,
+		remaining,
+	), nil
+}
+
+// NewAshtottariDashaTree casts the Ashtottari dasha (8 lords, 108 years) from
+// birthTime. Like NewDashaTree, it covers 120 years from birth.
+//
+// Ashtottari is only meant to be used for some charts: see
+// IsAshtottariApplicable.
+func NewAshtottariDashaTree(
+	swe *wrapper.SwissEph,
+	birthTime time.Time,
+	natal *Chart,
+) (*NakshatraDashaTree, error) {
+	nak, percRemaining, err := calculateMoonNakshatraRemaining(
+		swe,
+		birthTime,
+		natal,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("while calculating Nakshatra: %v", err)
+	}
+	lord, idx, total := AshtottariLordForNakshatra(nak.Type)
+	// Each lord rules a group of nakshatras and its years are spread evenly
+	// over them. So the balance also counts the nakshatras left in the
+	// group after this one
+	remainingInGroup := (float64(total-idx-1) + percRemaining) / float64(total)
+	remaining := yearsToDuration(
+		DashaSystem_Ashtottari.Years(lord) * remainingInGroup,
+	)
+	return newNakshatraDashaTree(
+		DashaSystem_Ashtottari,
+		birthTime,
+		lord,
+		remaining,
+	), nil
+}
+
+// newNakshatraDashaTree casts the mahadashas of system starting from lord,
+// which has remaining time left in it at birthTime
+func newNakshatraDashaTree(
+	system DashaSystem,
+	birthTime time.Time,
+	lord DashaLord,
+	remaining time.Duration,
+) *NakshatraDashaTree {
+	t := btree.NewBTreeG[DashaPeriod](func(a, b DashaPeriod) bool {
+		return a.Interval.Start.Before(b.Interval.Start.Time)
+	})
+	end := birthTime.Add(parseDuration(TotalVimshottariYears, 0, 0))
+	duration := system.Duration(lord)
+	mdStart := birthTime.Add(remaining - duration)
+	for mdStart.Before(end) {
+		mdEnd := mdStart.Add(duration)
+		clippedStart := mdStart
+		if clippedStart.Before(birthTime) {
+			clippedStart = birthTime
+		}
+		t.Set(DashaPeriod{
+			System:   system,
+			Level:    DashaLevel_Mahadasha,
+			Lords:    []DashaLord{lord},
+			Interval: NewInterval(clippedStart, mdEnd),
+			start:    mdStart,
+			duration: duration,
+		})
+		lord = system.Next(lord)
+		duration = system.Duration(lord)
+		mdStart = mdEnd
+	}
+	return &NakshatraDashaTree{
+		system:    system,
+		tree:      t,
+		startTime: birthTime,
+	}
+}
+
+func (ndt *NakshatraDashaTree) System() DashaSystem {
+	return ndt.system
+}
+
+// Start returns when the first period in the tree starts
+func (ndt *NakshatraDashaTree) Start() time.Time {
+	return ndt.startTime
+}
+
+// End returns when the last period in the tree ends
+func (ndt *NakshatraDashaTree) End() time.Time {
+	last, ok := ndt.tree.Max()
+	if !ok {
+		return ndt.startTime
+	}
+	return last.Interval.End.Time
+}
+
+// Mahadashas returns the mahadashas covered by the tree, in order
+func (ndt *NakshatraDashaTree) Mahadashas() []DashaPeriod {
+	return ndt.tree.Items()
+}
+
+// Children returns the sub-periods of p, in order. Periods at the prana
+// level have no children.
+func (ndt *NakshatraDashaTree) Children(p DashaPeriod) []DashaPeriod {
+	if p.Level >= DashaLevel_Prana {
+		return nil
+	}
+	return p.subPeriods(ndt.startTime)
+}
+
+// GetPeriodsForTime returns the active periods at t, from the mahadasha down
+// to the given level (inclusive)
+func (ndt *NakshatraDashaTree) GetPeriodsForTime(
+	t time.Time,
+	level DashaLevel,
+) ([]DashaPeriod, bool) {
+	return periodsForTime(ndt, t, level)
+}
+
+// GetPeriodForTime returns the active period at t at the given level
+func (ndt *NakshatraDashaTree) GetPeriodForTime(
+	t time.Time,
+	level DashaLevel,
+) (DashaPeriod, bool) {
+	return periodForTime(ndt, t, level)
+}
+
+// GetPeriodsInRange returns the periods at the given level that overlap
+// [start, end), in order
+func (ndt *NakshatraDashaTree) GetPeriodsInRange(
+	start, end time.Time,
+	level DashaLevel,
+) []DashaPeriod {
+	return periodsInRange(ndt, start, end, level)
+}
+
+// YoginiName returns the name of the yogini ruled by lord
+func YoginiName(lord DashaLord) string {
+	switch lord {
+	case DashaLordMoon:
+		return "Mangala"
+	case DashaLordSun:
+		return "Pingala"
+	case DashaLordJupiter:
+		return "Dhanya"
+	case DashaLordMars:
+		return "Bhramari"
+	case DashaLordMercury:
+		return "Bhadrika"
+	case DashaLordSaturn:
+		return "Ulka"
+	case DashaLordVenus:
+		return "Siddha"
+	case DashaLordRahu:
+		return "Sankata"
+	}
+	panic(fmt.Sprintf("%s is not a yogini lord", lord))
+}
+
+// YoginiLordForNakshatra returns the lord of the Yogini dasha running when the
+// Moon is in nak: add 3 to the (1-based) number of the nakshatra and divide
+// by 8. The remainder is the yogini (0 being the 8th).
+func YoginiLordForNakshatra(nak NakshatraType) DashaLord {
+	lords := DashaSystem_Yogini.Lords()
+	i := (nak.Int() + 3) % len(lords)
+	if i == 0 {
+		i = len(lords)
+	}
+	return lords[i-1]
+}
+
+// AshtottariLordForNakshatra returns the lord of the Ashtottari dasha running
+// when the Moon is in nak, along with the (0-based) index of nak among the
+// nakshatras ruled by that lord and how many of them there are.
+//
+// Traditionally, Abhijit is counted as a 28th nakshatra between Uttara
+// Ashadha and Shravana. We don't split it out, so Saturn rules 3 nakshatras
+// instead of 4.
+func AshtottariLordForNakshatra(nak NakshatraType) (DashaLord, int, int) {
+	groups := []struct {
+		lord       DashaLord
+		nakshatras []NakshatraType
+	}{
+		{DashaLordSun, []NakshatraType{Ardra, Punarvasu, Pushya, Ashlesha}},
+		{DashaLordMoon, []NakshatraType{Magha, PurvaPhalguni, UttaraPhalguni}},
+		{DashaLordMars, []NakshatraType{Hasta, Chitra, Swati, Vishakha}},
+		{DashaLordMercury, []NakshatraType{Anuradha, Jyeshtha, Mula}},
+		{DashaLordSaturn, []NakshatraType{PurvaAshadha, UttaraAshadha, Shravana}},
+		{DashaLordJupiter, []NakshatraType{Dhanishta, Shatabhisha, PurvaBhadrapada}},
+		{DashaLordRahu, []NakshatraType{UttaraBhadrapada, Revati, Aswini, Bharani}},
+		{DashaLordVenus, []NakshatraType{Krittika, Rohini, Mrigashirsha}},
+	}
+	for _, g := range groups {
+		for i, n := range g.nakshatras {
+			if n == nak {
+				return g.lord, i, len(g.nakshatras)
+			}
+		}
+	}
+	panic(fmt.Sprintf("unknown nakshatra %s", nak))
+}
+
+// IsAshtottariApplicable reports whether Ashtottari dasha applies to the D1
+// chart d1, i.e., if either IsAshtottariApplicableByRahu or
+// IsAshtottariApplicableByPaksha holds
+func IsAshtottariApplicable(d1 *Chart) (bool, error) {
+	byRahu, err := IsAshtottariApplicableByRahu(d1)
+	if err != nil {
+		return false, err
+	}
+	if byRahu {
+		return true, nil
+	}
+	return IsAshtottariApplicableByPaksha(d1)
+}
+
+// IsAshtottariApplicableByRahu reports whether Rahu is in a kendra (1, 4, 7,
+// 10) or a trikona (5, 9) from the lord of the ascendant, but not in the
+// ascendant itself
+func IsAshtottariApplicableByRahu(d1 *Chart) (bool, error) {
+	if d1.ChartType != D1ChartType {
+		return false, fmt.Errorf("Ashtottari applicability needs a D1 chart, got %s", d1.ChartType)
+	}
+	lagnaLordID, err := d1.GetHouseLordFor(house.House1, HouseLordPlacement_Traditional)
+	if err != nil {
+		return false, fmt.Errorf("while getting lord of the ascendant: %v", err)
+	}
+	lagnaLord := d1.GetPoint(lagnaLordID)
+	rahu := d1.GetPoint(pointid.Rahu)
+	if lagnaLord == nil || rahu == nil {
+		return false, fmt.Errorf("chart needs %s and %s", lagnaLordID, pointid.Rahu)
+	}
+	if rahu.House == house.House1 {
+		return false, nil
+	}
+	switch house.NewHouseFromSign(
+		rahu.ZodiacalPos.Sign,
+		lagnaLord.ZodiacalPos.Sign,
+	) {
+	case house.House1, house.House4, house.House7, house.House10,
+		house.House5, house.House9:
+		return true, nil
+	}
+	return false, nil
+}
+
+// IsAshtottariApplicableByPaksha reports whether the birth happened during
+// the day in the dark half of the lunar month (Krishna paksha), or during the
+// night in the bright half (Shukla paksha).
+//
+// The Sun is considered above the horizon if it's in the half of the ecliptic
+// between the descendant and the ascendant, which ignores its latitude.
+func IsAshtottariApplicableByPaksha(d1 *Chart) (bool, error) {
+	if d1.ChartType != D1ChartType {
+		return false, fmt.Errorf("Ashtottari applicability needs a D1 chart, got %s", d1.ChartType)
+	}
+	asc := d1.GetPoint(pointid.ASC)
+	sun := d1.GetPoint(pointid.Sun)
+	moon := d1.GetPoint(pointid.Moon)
+	if asc == nil || sun == nil || moon == nil {
+		return false, fmt.Errorf("chart needs the ascendant, the Sun and the Moon")
+	}
+	isDay := degreesAhead(asc.Longitude, sun.Longitude) > 180
+	isShukla := degreesAhead(sun.Longitude, moon.Longitude) < 180
+	return isDay != isShukla, nil
+}
+
+// degreesAhead returns how many degrees "to" is ahead of "from" along the
+// zodiac, from 0 to 360
+func degreesAhead(from, to float64) float64 {
+	diff := to - from
+	for diff < 0 {
+		diff += 360
+	}
+	for diff >= 360 {
+		diff -= 360
+	}
+	return diff
+}
@@ -0,0 +1,159 @@
+package chart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashaSystem(t *testing.T) {
+	require.Equal(t, 120.0, DashaSystem_Vimshottari.TotalYears())
+	require.Equal(t, 36.0, DashaSystem_Yogini.TotalYears())
+	require.Equal(t, 108.0, DashaSystem_Ashtottari.TotalYears())
+	require.Equal(t, DashaLordKetu, DashaSystem_Vimshottari.Next(DashaLordMercury))
+	require.Equal(t, DashaLordMoon, DashaSystem_Yogini.Next(DashaLordRahu))
+	require.Equal(t, DashaLordSun, DashaSystem_Ashtottari.Next(DashaLordVenus))
+
+	require.Equal(t, DashaLordMars, YoginiLordForNakshatra(Aswini))
+	require.Equal(t, DashaLordMercury, YoginiLordForNakshatra(Magha))
+	require.Equal(t, DashaLordSaturn, YoginiLordForNakshatra(Revati))
+	require.Equal(t, "Bhadrika", YoginiName(DashaLordMercury))
+
+	lord, idx, total := AshtottariLordForNakshatra(Aswini)
+	require.Equal(t, DashaLordRahu, lord)
+	require.Equal(t, 2, idx)
+	require.Equal(t, 4, total)
+}
+
+func TestNakshatraDashaTree(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+
+	// London, 2024-01-01 00:00 UTC. The Moon is at 11°48' Leo, in Magha
+	birthTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d1, err := NewChartFromUTC(
+		swe, birthTime, -0.1278, 51.5074, D1ChartType, pointid.VedicPlanets)
+	require.NoError(t, err)
+
+	yogini, err := NewYoginiDashaTree(swe, birthTime, d1)
+	require.NoError(t, err)
+	ashtottari, err := NewAshtottariDashaTree(swe, birthTime, d1)
+	require.NoError(t, err)
+	vimshottari, err := NewDashaTree(swe, birthTime, d1)
+	require.NoError(t, err)
+
+	type testCase struct {
+		title             string
+		timeline          DashaTimeline
+		expectedFirstLord DashaLord
+		// expectedBalance is how much of the first mahadasha is left at
+		// birth, in years
+		expectedBalance float64
+	}
+	for _, tc := range []testCase{
+		{
+			// Magha is the 10th nakshatra: (10 + 3) % 8 = 5, i.e., Bhadrika.
+			// The Moon has ~12% of Magha left
+			title:             "yogini",
+			timeline:          yogini,
+			expectedFirstLord: DashaLordMercury,
+			expectedBalance:   5 * 0.116,
+		},
+		{
+			// Magha is the first of the Moon's three nakshatras
+			title:             "ashtottari",
+			timeline:          ashtottari,
+			expectedFirstLord: DashaLordMoon,
+			expectedBalance:   15 * (2 + 0.116) / 3,
+		},
+		{
+			title:             "vimshottari",
+			timeline:          vimshottari,
+			expectedFirstLord: DashaLordKetu,
+			expectedBalance:   7 * 0.116,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			tl := tc.timeline
+			mds := tl.Mahadashas()
+			require.Equal(t, tc.expectedFirstLord, mds[0].Lord())
+			require.Equal(t, birthTime, mds[0].Interval.Start.Time)
+			require.Equal(t, birthTime, tl.Start())
+			require.InDelta(t,
+				tc.expectedBalance,
+				mds[0].Interval.End.Sub(birthTime).Hours()/parseDuration(1, 0, 0).Hours(),
+				0.05,
+			)
+			// About 120 years are covered
+			require.InDelta(t,
+				120,
+				tl.End().Sub(birthTime).Hours()/parseDuration(1, 0, 0).Hours(),
+				21,
+			)
+
+			// Mahadashas follow the order of the system
+			for i := 1; i < len(mds); i++ {
+				require.Equal(t, tl.System(), mds[i].System)
+				require.Equal(t, tl.System().Next(mds[i-1].Lord()), mds[i].Lord())
+				require.Equal(t, mds[i-1].Interval.End, mds[i].Interval.Start)
+			}
+
+			// The same queries work across systems
+			tm := time.Date(2050, 6, 1, 0, 0, 0, 0, time.UTC)
+			periods, didFind := tl.GetPeriodsForTime(tm, DashaLevel_Prana)
+			require.True(t, didFind)
+			require.Len(t, periods, 5)
+			for _, p := range periods {
+				require.True(t, p.Contains(tm))
+			}
+			pd, didFind := tl.GetPeriodForTime(tm, DashaLevel_Pratyantardasha)
+			require.True(t, didFind)
+			require.Equal(t, periods[2].Lords, pd.Lords)
+
+			children := tl.Children(periods[1])
+			require.Len(t, children, len(tl.System().Lords()))
+			require.Equal(t, periods[1].Lord(), children[0].Lord())
+			require.Equal(t, periods[1].Interval.End, children[len(children)-1].Interval.End)
+
+			inRange := tl.GetPeriodsInRange(
+				periods[1].Interval.Start.Time,
+				periods[1].Interval.End.Time,
+				DashaLevel_Pratyantardasha,
+			)
+			require.Equal(t, children, inRange)
+		})
+	}
+}
+
+func TestIsAshtottariApplicable(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+
+	// London, 2024-01-01 00:00 UTC
+	birthTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d1, err := NewChartFromUTC(
+		swe, birthTime, -0.1278, 51.5074, D1ChartType, pointid.VedicPlanets)
+	require.NoError(t, err)
+
+	// The lagna lord (Mercury) is in Scorpio and Rahu is in Pisces, the 5th
+	// from it
+	byRahu, err := IsAshtottariApplicableByRahu(d1)
+	require.NoError(t, err)
+	require.True(t, byRahu)
+	// Night birth in Krishna paksha
+	byPaksha, err := IsAshtottariApplicableByPaksha(d1)
+	require.NoError(t, err)
+	require.False(t, byPaksha)
+	isApplicable, err := IsAshtottariApplicable(d1)
+	require.NoError(t, err)
+	require.True(t, isApplicable)
+
+	d9, err := NewChartFromUTC(
+		swe, birthTime, -0.1278, 51.5074, D9ChartType, pointid.VedicPlanets)
+	require.NoError(t, err)
+	_, err = IsAshtottariApplicable(d9)
+	require.Error(t, err)
+}
@@ -0,0 +1,175 @@
+package chart
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/wrapper"
+)
+
+// NakshatraDetails describes the nakshatra a point of a chart is in
+type NakshatraDetails struct {
+	Point     pointid.PointID `json:"point"`
+	Nakshatra NakshatraType   `json:"nakshatra"`
+	// Pada is the quarter of the nakshatra, from 1 to 4 (unlike
+	// Nakshatra.Pada)
+	Pada  int       `json:"pada"`
+	Lord  DashaLord `json:"lord"`
+	Deity string    `json:"deity"`
+	// DegreesInNakshatra is how far the point is into the nakshatra, from 0
+	// to 13°20'
+	DegreesInNakshatra float64 `json:"degreesInNakshatra"`
+	// SiderealLongitude is the longitude the details were derived from
+	SiderealLongitude float64 `json:"siderealLongitude"`
+}
+
+func (nd *NakshatraDetails) String() string {
+	return fmt.Sprintf(
+		"NakshatraDetails{Point: %s, Nakshatra: %s, Pada: %d, Lord: %s, Deity: %s, DegreesInNakshatra: %f}",
+		nd.Point,
+		nd.Nakshatra,
+		nd.Pada,
+		nd.Lord,
+		nd.Deity,
+		nd.DegreesInNakshatra,
+	)
+}
+
+// NewNakshatraDetails returns the nakshatra details of a point at the
+// sidereal longitude lon
+func NewNakshatraDetails(
+	pid pointid.PointID,
+	lon float64,
+) (*NakshatraDetails, error) {
+	nak, err := NewNakshatraFromSiderealLongitude(lon)
+	if err != nil {
+		return nil, fmt.Errorf("while getting nakshatra of %s: %v", pid, err)
+	}
+	return &NakshatraDetails{
+		Point:              pid,
+		Nakshatra:          nak.Type,
+		Pada:               nak.PadaNumber(),
+		Lord:               nak.Type.Lord(),
+		Deity:              nak.Type.Deity(),
+		DegreesInNakshatra: math.Mod(lon, NakshatraSpan),
+		SiderealLongitude:  lon,
+	}, nil
+}
+
+// siderealLongitude returns the sidereal longitude of pid. For tropical
+// charts, the chart's ayanamsa at the time of the chart is taken away.
+func (c *Chart) siderealLongitude(
+	swe *wrapper.SwissEph,
+	pid pointid.PointID,
+) (float64, error) {
+	p := c.GetPoint(pid)
+	if p == nil {
+		return 0, fmt.Errorf("%s not found in chart", pid)
+	}
+	if c.ChartType.IsVarga() {
+		// AstroPoint.Longitude is the D1 sidereal longitude in all varga
+		// charts
+		return p.Longitude, nil
+	}
+	ayanamsa, err := swe.GetAyanamsaFor(
+		swe.GoTimeToJulianDay(c.Time.Time.UTC()),
+		c.Ayanamsa,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return math.Mod(p.Longitude-ayanamsa+360, 360), nil
+}
+
+// GetNakshatraDetails returns the nakshatra details of pid, for any chart
+// type. Varga charts use the D1 position of the point, which is where the
+// nakshatra comes from.
+func (c *Chart) GetNakshatraDetails(
+	swe *wrapper.SwissEph,
+	pid pointid.PointID,
+) (*NakshatraDetails, error) {
+	lon, err := c.siderealLongitude(swe, pid)
+	if err != nil {
+		return nil, fmt.Errorf("while getting sidereal longitude: %v", err)
+	}
+	return NewNakshatraDetails(pid, lon)
+}
+
+// NakshatraDetails returns the nakshatra details of all the points of the
+// chart, in the same order as c.Points
+func (c *Chart) NakshatraDetails(
+	swe *wrapper.SwissEph,
+) ([]*NakshatraDetails, error) {
+	ret := []*NakshatraDetails{}
+	for _, p := range c.Points {
+		nd, err := c.GetNakshatraDetails(swe, p.ID)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, nd)
+	}
+	return ret, nil
+}
+
+// Tara is one of the 9 "stars" of Tara Bala: counting from a birth
+// nakshatra, the nakshatras cycle through them three times
+type Tara int
+
+const (
+	Tara_Janma Tara = iota + 1
+	Tara_Sampat
+	Tara_Vipat
+	Tara_Kshema
+	Tara_Pratyak
+	Tara_Sadhana
+	Tara_Naidhana
+	Tara_Mitra
+	Tara_ParamaMitra
+)
+
+func (t Tara) String() string {
+	switch t {
+	case Tara_Janma:
+		return "janma"
+	case Tara_Sampat:
+		return "sampat"
+	case Tara_Vipat:
+		return "vipat"
+	case Tara_Kshema:
+		return "kshema"
+	case Tara_Pratyak:
+		return "pratyak"
+	case Tara_Sadhana:
+		return "sadhana"
+	case Tara_Naidhana:
+		return "naidhana"
+	case Tara_Mitra:
+		return "mitra"
+	case Tara_ParamaMitra:
+		return "parama-mitra"
+	}
+	return "unknown"
+}
+
+// IsFavorable reports whether the tara is auspicious. Vipat, Pratyak and
+// Naidhana are not, and neither is Janma, which is usually considered mixed
+// and avoided for important work.
+func (t Tara) IsFavorable() bool {
+	switch t {
+	case Tara_Sampat, Tara_Kshema, Tara_Sadhana, Tara_Mitra, Tara_ParamaMitra:
+		return true
+	}
+	return false
+}
+
+// TaraBala returns the tara of the nakshatra "to" counted from the birth
+// nakshatra "from" (both inclusive, so a nakshatra is its own Janma tara)
+func TaraBala(from, to NakshatraType) Tara {
+	count := (int(to)-int(from)+27)%27 + 1
+	ret := count % 9
+	if ret == 0 {
+		ret = 9
+	}
+	return Tara(ret)
+}
@@ -0,0 +1,59 @@
+package chart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNakshatraDetails(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+
+	// London, 2024-01-01 00:00 UTC. The Moon is at 11°48' Leo (sidereal),
+	// i.e., in the 4th pada of Magha
+	birthTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, ct := range []ChartType{D1ChartType, D9ChartType, TropicalChartType} {
+		t.Run(ct.String(), func(t *testing.T) {
+			chrt, err := NewChartFromUTC(
+				swe, birthTime, -0.1278, 51.5074, ct, pointid.VedicPlanets)
+			require.NoError(t, err)
+			nd, err := chrt.GetNakshatraDetails(swe, pointid.Moon)
+			require.NoError(t, err)
+			require.Equal(t, Magha, nd.Nakshatra)
+			require.Equal(t, 4, nd.Pada)
+			require.Equal(t, DashaLordKetu, nd.Lord)
+			require.Equal(t, "Pitris", nd.Deity)
+			require.InDelta(t, 11.8, nd.DegreesInNakshatra, 0.1)
+
+			all, err := chrt.NakshatraDetails(swe)
+			require.NoError(t, err)
+			require.Len(t, all, len(chrt.Points))
+		})
+	}
+
+	require.Equal(t, DashaLordVenus, PurvaAshadha.Lord())
+	require.Equal(t, DashaLordSun, UttaraPhalguni.Lord())
+	require.Equal(t, DashaLordSaturn, UttaraBhadrapada.Lord())
+
+	nak, err := NewNakshatraFromSiderealLongitude(0)
+	require.NoError(t, err)
+	require.Equal(t, Aswini, nak.Type)
+	require.Equal(t, 0, nak.Pada)
+	require.Equal(t, 1, nak.PadaNumber())
+}
+
+func TestTaraBala(t *testing.T) {
+	require.Equal(t, Tara_Janma, TaraBala(Magha, Magha))
+	require.Equal(t, Tara_Sampat, TaraBala(Magha, PurvaPhalguni))
+	require.Equal(t, Tara_ParamaMitra, TaraBala(Magha, Jyeshtha))
+	// The 10th and 19th nakshatras start the cycle again
+	require.Equal(t, Tara_Janma, TaraBala(Magha, Mula))
+	require.Equal(t, Tara_Janma, TaraBala(Magha, Aswini))
+	require.Equal(t, Tara_Naidhana, TaraBala(Aswini, Punarvasu))
+	require.False(t, Tara_Naidhana.IsFavorable())
+	require.True(t, Tara_Sampat.IsFavorable())
+}
@@ -0,0 +1,97 @@
+package chart
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/afjoseph/sacredstar/aspect"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/wrapper"
+)
+
+// FindAspectPerfection finds the exact time an aspect of the chart perfects
+// (or did perfect, if it's separating). Unlike aspect.Aspect.PerfectionTime,
+// which assumes constant speeds, this follows the actual motion of the
+// planets.
+//
+// The ascendant isn't supported since the chart doesn't know its location.
+// Varga charts are searched using their D1 positions.
+func (c *Chart) FindAspectPerfection(
+	swe *wrapper.SwissEph,
+	asp *aspect.Aspect,
+) (time.Time, error) {
+	if asp.Type == aspect.AspectType_None {
+		return time.Time{}, fmt.Errorf("no aspect between %s and %s",
+			asp.P1, asp.P2)
+	}
+	if asp.P1 == pointid.ASC || asp.P2 == pointid.ASC {
+		return time.Time{}, fmt.Errorf(
+			"can't find perfection of aspects to the ascendant")
+	}
+	if IsSpecialPoint(asp.P1) || IsSpecialPoint(asp.P2) {
+		return time.Time{}, fmt.Errorf(
+			"can't find perfection of aspects to special lagnas or upagrahas")
+	}
+	chartType := D1ChartType
+	if c.ChartType == TropicalChartType {
+		chartType = TropicalChartType
+	}
+
+	// Newton's method: step by the time the aspect needs to perfect at the
+	// current speeds, then recalculate the speeds
+	const maxIterations = 50
+	const maxStepInDays = 30.0
+	const epsilonInDegrees = 0.0001
+	jd := swe.GoTimeToJulianDay(c.Time.Time.UTC())
+	for i := 0; i < maxIterations; i++ {
+		lon1, speed1, err := calculateLongitudeAndSpeed(swe, jd, asp.P1, chartType, c.Ayanamsa)
+		if err != nil {
+			return time.Time{}, err
+		}
+		lon2, speed2, err := calculateLongitudeAndSpeed(swe, jd, asp.P2, chartType, c.Ayanamsa)
+		if err != nil {
+			return time.Time{}, err
+		}
+		sep := math.Mod(lon2-lon1+540, 360) - 180
+		rate := speed2 - speed1
+		if sep < 0 {
+			rate = -rate
+		}
+		deviation := math.Abs(sep) - asp.Type.Degree()
+		if math.Abs(deviation) < epsilonInDegrees {
+			return swe.JulianDayToGoTime(jd), nil
+		}
+		if rate == 0 {
+			return time.Time{}, fmt.Errorf("%s never perfects", asp)
+		}
+		step := -deviation / rate
+		step = math.Max(-maxStepInDays, math.Min(maxStepInDays, step))
+		jd += step
+	}
+	return time.Time{}, fmt.Errorf("could not find perfection of %s", asp)
+}
+
+// calculateLongitudeAndSpeed returns the longitude and daily motion of a
+// point. Ketu is calculated as the opposite of Rahu.
+func calculateLongitudeAndSpeed(
+	swe *wrapper.SwissEph,
+	timeInJulian float64,
+	pid pointid.PointID,
+	chartType ChartType,
+	ayanamsa wrapper.Ayanamsa,
+) (float64, float64, error) {
+	id := pid
+	if pid == pointid.Ketu {
+		id = pointid.Rahu
+	}
+	ap, err := calculatePlanet(swe, timeInJulian, id, chartType, nil, ayanamsa)
+	if err != nil {
+		return 0, 0, fmt.Errorf("while calculating %s: %v", pid, err)
+	}
+	lon := ap.Longitude
+	if pid == pointid.Ketu {
+		lon = math.Mod(lon+180, 360)
+	}
+	return lon, ap.Speed, nil
+}
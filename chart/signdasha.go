@@ -0,0 +1,515 @@
+package chart
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+)
+
+// Sign dashas are the Jaimini counterpart of the nakshatra dashas (see
+// DashaSystem): the periods are ruled by signs instead of planets, and both
+// their order and their length come from the placements in the D1 chart
+// instead of the Moon's nakshatra. They start at birth, so there's no balance
+// to compute.
+//
+// The length of the period of a sign is found by counting from the sign to
+// its lord (forward for odd-footed signs, backward for the others) and
+// subtracting one. A lord in its own sign gives 12 years, an exalted lord
+// adds a year and a debilitated lord takes one away. The second cycle gives
+// each sign the years it missed in the first one (i.e., 12 minus the first
+// ones).
+
+type SignDashaSystem string
+
+const (
+	// SignDashaSystem_CharaKNRao is Chara dasha as taught by K.N. Rao: the
+	// periods go forward if the 9th house is odd-footed and backward
+	// otherwise, and the antardashas start from the sign after the
+	// mahadasha sign, which comes last
+	SignDashaSystem_CharaKNRao SignDashaSystem = "chara-knrao"
+	// SignDashaSystem_CharaRath is Chara dasha as taught by Sanjay Rath: the
+	// periods go forward if the ascendant is an odd sign and backward
+	// otherwise, and the antardashas start from the mahadasha sign itself,
+	// going forward if it's odd and backward otherwise
+	SignDashaSystem_CharaRath SignDashaSystem = "chara-rath"
+	// SignDashaSystem_Narayana starts from the stronger of the 1st and 7th
+	// houses. Movable signs are followed by the next sign, fixed signs by the
+	// 6th one and dual signs by the 4th one (i.e., the kendras). The
+	// antardashas start from the sign occupied by the lord of the mahadasha
+	// sign.
+	SignDashaSystem_Narayana SignDashaSystem = "narayana"
+)
+
+func (sds SignDashaSystem) String() string {
+	return string(sds)
+}
+
+// SignDashaPeriod is a mahadasha or an antardasha of a sign dasha
+type SignDashaPeriod struct {
+	System SignDashaSystem `json:"system"`
+	Level  DashaLevel      `json:"level"`
+	// Signs are the signs of this period and its parent, starting from the
+	// mahadasha sign
+	Signs    []sign.Sign `json:"signs"`
+	Interval Interval    `json:"interval"`
+
+	// children are the antardashas of a mahadasha
+	children []SignDashaPeriod
+}
+
+func (p SignDashaPeriod) String() string {
+	return fmt.Sprintf(
+		"SignDashaPeriod{Level: %s, Signs: %v, Interval: %+v}",
+		p.Level,
+		p.Signs,
+		p.Interval,
+	)
+}
+
+// Sign returns the sign of this period (i.e., the last of Signs)
+func (p SignDashaPeriod) Sign() sign.Sign {
+	return p.Signs[len(p.Signs)-1]
+}
+
+// Contains reports whether t is within the period (start inclusive, end
+// exclusive)
+func (p SignDashaPeriod) Contains(t time.Time) bool {
+	return !t.Before(p.Interval.Start.Time) && t.Before(p.Interval.End.Time)
+}
+
+// Overlaps reports whether the period overlaps [start, end)
+func (p SignDashaPeriod) Overlaps(start, end time.Time) bool {
+	return p.Interval.Start.Before(end) && p.Interval.End.After(start)
+}
+
+// SignDashaTimeline holds the mahadashas and antardashas of a sign dasha
+type SignDashaTimeline struct {
+	system     SignDashaSystem
+	startTime  time.Time
+	mahadashas []SignDashaPeriod
+}
+
+// NewSignDashaTimeline casts the sign dasha system for the D1 chart d1, born
+// at birthTime. Like NewDashaTree, it covers (at least) 120 years from birth.
+func NewSignDashaTimeline(
+	d1 *Chart,
+	birthTime time.Time,
+	system SignDashaSystem,
+) (*SignDashaTimeline, error) {
+	if d1.ChartType != D1ChartType {
+		return nil, fmt.Errorf("sign dashas need a D1 chart, got %s", d1.ChartType)
+	}
+	if d1.GetPoint(pointid.ASC) == nil {
+		return nil, fmt.Errorf("sign dashas need the ascendant")
+	}
+	order, forward, err := signDashaOrder(d1, system)
+	if err != nil {
+		return nil, fmt.Errorf("while getting order of %s dasha: %v", system, err)
+	}
+	firstCycle := map[sign.Sign]int{}
+	for _, s := range order {
+		years, err := SignDashaYears(d1, s)
+		if err != nil {
+			return nil, fmt.Errorf("while getting years of %s: %v", s, err)
+		}
+		firstCycle[s] = years
+	}
+
+	ret := &SignDashaTimeline{
+		system:    system,
+		startTime: birthTime,
+	}
+	end := birthTime.Add(parseDuration(TotalVimshottariYears, 0, 0))
+	start := birthTime
+	for cycle := 0; start.Before(end); cycle++ {
+		for _, s := range order {
+			years := firstCycle[s]
+			if cycle%2 == 1 {
+				years = 12 - years
+			}
+			// A sign can get 0 years (e.g., a debilitated lord in the 2nd
+			// sign, or 12 years in the first cycle). It's skipped for that
+			// cycle.
+			if years <= 0 {
+				continue
+			}
+			mdEnd := start.Add(yearsToDuration(float64(years)))
+			md := SignDashaPeriod{
+				System:   system,
+				Level:    DashaLevel_Mahadasha,
+				Signs:    []sign.Sign{s},
+				Interval: NewInterval(start, mdEnd),
+			}
+			md.children, err = signAntardashas(d1, system, forward, md)
+			if err != nil {
+				return nil, fmt.Errorf("while getting antardashas of %s: %v", s, err)
+			}
+			ret.mahadashas = append(ret.mahadashas, md)
+			start = mdEnd
+		}
+	}
+	return ret, nil
+}
+
+func (sdt *SignDashaTimeline) System() SignDashaSystem {
+	return sdt.system
+}
+
+// Start returns when the first period starts (i.e., the birth time)
+func (sdt *SignDashaTimeline) Start() time.Time {
+	return sdt.startTime
+}
+
+// End returns when the last period ends
+func (sdt *SignDashaTimeline) End() time.Time {
+	if len(sdt.mahadashas) == 0 {
+		return sdt.startTime
+	}
+	return sdt.mahadashas[len(sdt.mahadashas)-1].Interval.End.Time
+}
+
+// Mahadashas returns the mahadashas covered by the timeline, in order
+func (sdt *SignDashaTimeline) Mahadashas() []SignDashaPeriod {
+	return sdt.mahadashas
+}
+
+// Children returns the antardashas of the mahadasha p, in order. Antardashas
+// have no children.
+func (sdt *SignDashaTimeline) Children(p SignDashaPeriod) []SignDashaPeriod {
+	return p.children
+}
+
+// GetPeriodsForTime returns the active periods at t, from the mahadasha down
+// to the given level (only mahadashas and antardashas are supported)
+func (sdt *SignDashaTimeline) GetPeriodsForTime(
+	t time.Time,
+	level DashaLevel,
+) ([]SignDashaPeriod, bool) {
+	if level != DashaLevel_Mahadasha && level != DashaLevel_Antardasha {
+		return nil, false
+	}
+	for _, md := range sdt.mahadashas {
+		if !md.Contains(t) {
+			continue
+		}
+		if level == DashaLevel_Mahadasha {
+			return []SignDashaPeriod{md}, true
+		}
+		for _, ad := range md.children {
+			if ad.Contains(t) {
+				return []SignDashaPeriod{md, ad}, true
+			}
+		}
+		return nil, false
+	}
+	return nil, false
+}
+
+// GetPeriodForTime returns the active period at t at the given level
+func (sdt *SignDashaTimeline) GetPeriodForTime(
+	t time.Time,
+	level DashaLevel,
+) (SignDashaPeriod, bool) {
+	periods, didFind := sdt.GetPeriodsForTime(t, level)
+	if !didFind {
+		return SignDashaPeriod{}, false
+	}
+	return periods[len(periods)-1], true
+}
+
+// GetPeriodsInRange returns the periods at the given level that overlap
+// [start, end), in order
+func (sdt *SignDashaTimeline) GetPeriodsInRange(
+	start, end time.Time,
+	level DashaLevel,
+) []SignDashaPeriod {
+	ret := []SignDashaPeriod{}
+	if !start.Before(end) {
+		return ret
+	}
+	for _, md := range sdt.mahadashas {
+		if !md.Overlaps(start, end) {
+			continue
+		}
+		switch level {
+		case DashaLevel_Mahadasha:
+			ret = append(ret, md)
+		case DashaLevel_Antardasha:
+			for _, ad := range md.children {
+				if ad.Overlaps(start, end) {
+					ret = append(ret, ad)
+				}
+			}
+		}
+	}
+	return ret
+}
+
+// IsOddFootedSign reports whether s is one of the odd-footed (savya) signs of
+// Jaimini: Aries, Taurus, Gemini, Libra, Scorpio and Sagittarius. The others
+// are even-footed.
+func IsOddFootedSign(s sign.Sign) bool {
+	switch s {
+	case sign.Aries, sign.Taurus, sign.Gemini,
+		sign.Libra, sign.Scorpio, sign.Sagittarius:
+		return true
+	}
+	return false
+}
+
+// SignDashaYears returns the length, in years, of the first-cycle period of
+// the sign s in the D1 chart d1
+func SignDashaYears(d1 *Chart, s sign.Sign) (int, error) {
+	lordID, err := SignDashaLord(d1, s)
+	if err != nil {
+		return 0, err
+	}
+	lord := d1.GetPoint(lordID)
+	if lord == nil {
+		return 0, fmt.Errorf("lord %s of %s not found in chart", lordID, s)
+	}
+	lordSign := lord.ZodiacalPos.Sign
+	if lordSign == s {
+		return 12, nil
+	}
+	years := countSigns(s, lordSign, IsOddFootedSign(s)) - 1
+	if lord.IsExalted() {
+		years++
+	}
+	if lord.IsFall() {
+		years--
+	}
+	return years, nil
+}
+
+// SignDashaLord returns the lord of the sign s used by the sign dashas.
+// Scorpio and Aquarius have two lords (Mars/Ketu and Saturn/Rahu), and the
+// stronger of the two is used:
+// - If one of them is in the sign itself, the other one
+// - Otherwise, the one with more planets with it
+// - Otherwise, the one with the highest degrees in its sign
+func SignDashaLord(d1 *Chart, s sign.Sign) (pointid.PointID, error) {
+	var lord, coLord pointid.PointID
+	switch s {
+	case sign.Scorpio:
+		lord, coLord = pointid.Mars, pointid.Ketu
+	case sign.Aquarius:
+		lord, coLord = pointid.Saturn, pointid.Rahu
+	default:
+		return s.TraditionalRuler(), nil
+	}
+	lordPoint := d1.GetPoint(lord)
+	coLordPoint := d1.GetPoint(coLord)
+	if lordPoint == nil || coLordPoint == nil {
+		return pointid.None, fmt.Errorf("chart needs %s and %s", lord, coLord)
+	}
+	lordIsIn := lordPoint.ZodiacalPos.Sign == s
+	coLordIsIn := coLordPoint.ZodiacalPos.Sign == s
+	switch {
+	case lordIsIn && !coLordIsIn:
+		return coLord, nil
+	case coLordIsIn && !lordIsIn:
+		return lord, nil
+	}
+	lordCount := len(planetsInSign(d1, lordPoint.ZodiacalPos.Sign))
+	coLordCount := len(planetsInSign(d1, coLordPoint.ZodiacalPos.Sign))
+	if lordCount != coLordCount {
+		if lordCount > coLordCount {
+			return lord, nil
+		}
+		return coLord, nil
+	}
+	if math.Mod(coLordPoint.Longitude, 30) > math.Mod(lordPoint.Longitude, 30) {
+		return coLord, nil
+	}
+	return lord, nil
+}
+
+// planetsInSign returns the points in s, except the ascendant and the special
+// points
+func planetsInSign(c *Chart, s sign.Sign) []pointid.PointID {
+	ret := []pointid.PointID{}
+	for _, p := range c.Points {
+		if p.ID != pointid.ASC && !IsSpecialPoint(p.ID) && p.ZodiacalPos.Sign == s {
+			ret = append(ret, p.ID)
+		}
+	}
+	return ret
+}
+
+// countSigns counts the signs from "from" to "to" (both inclusive), forward or
+// backward. It's 1 if they're the same.
+func countSigns(from, to sign.Sign, forward bool) int {
+	if forward {
+		return house.NewHouseFromSign(to, from).Int()
+	}
+	return house.NewHouseFromSign(from, to).Int()
+}
+
+// stepSign returns the sign n signs after s (or before, if forward is false)
+func stepSign(s sign.Sign, n int, forward bool) sign.Sign {
+	if !forward {
+		n = -n
+	}
+	ret, err := sign.NewSignFromInt(s.Int() + n + 12)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+// signSequence returns the 12 signs starting from start, going every step
+// signs. Once a sign is reached again, the sequence moves on to the sign after
+// the first one of the previous round (e.g., 1, 4, 7, 10, then 2, 5, 8, 11).
+func signSequence(start sign.Sign, step int, forward bool) []sign.Sign {
+	ret := []sign.Sign{}
+	roundStart := start
+	for len(ret) < 12 {
+		s := roundStart
+		for i := 0; i < 12/gcd(step, 12); i++ {
+			ret = append(ret, s)
+			s = stepSign(s, step, forward)
+		}
+		roundStart = stepSign(roundStart, 1, forward)
+	}
+	return ret
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// signDashaOrder returns the mahadasha signs of system, in order, and whether
+// they go forward through the zodiac
+func signDashaOrder(
+	d1 *Chart,
+	system SignDashaSystem,
+) ([]sign.Sign, bool, error) {
+	lagna := d1.GetSignOfHouse(house.House1)
+	switch system {
+	case SignDashaSystem_CharaKNRao:
+		forward := IsOddFootedSign(d1.GetSignOfHouse(house.House9))
+		return signSequence(lagna, 1, forward), forward, nil
+	case SignDashaSystem_CharaRath:
+		forward := lagna.Int()%2 == 1
+		return signSequence(lagna, 1, forward), forward, nil
+	case SignDashaSystem_Narayana:
+		start, err := strongerSign(d1, lagna, d1.GetSignOfHouse(house.House7))
+		if err != nil {
+			return nil, false, err
+		}
+		forward := IsOddFootedSign(start)
+		planets := planetsInSign(d1, start)
+		for _, pid := range planets {
+			if pid == pointid.Saturn {
+				forward = true
+			}
+		}
+		for _, pid := range planets {
+			if pid == pointid.Ketu {
+				forward = !forward
+			}
+		}
+		return signSequence(start, narayanaStep(start), forward), forward, nil
+	}
+	return nil, false, fmt.Errorf("unknown sign dasha system: %s", system)
+}
+
+// narayanaStep returns how many signs Narayana dasha moves from a mahadasha
+// sign to the next, based on the modality of the sign it starts from
+func narayanaStep(s sign.Sign) int {
+	switch (s.Int() - 1) % 3 {
+	case 0: // Movable
+		return 1
+	case 1: // Fixed
+		return 5
+	}
+	// Dual
+	return 3
+}
+
+// strongerSign returns the stronger of the signs a and b: the one with more
+// planets in it or, if they have the same number, the one whose lord has the
+// highest degrees in its sign
+func strongerSign(d1 *Chart, a, b sign.Sign) (sign.Sign, error) {
+	aCount := len(planetsInSign(d1, a))
+	bCount := len(planetsInSign(d1, b))
+	if aCount != bCount {
+		if aCount > bCount {
+			return a, nil
+		}
+		return b, nil
+	}
+	aLordID, err := SignDashaLord(d1, a)
+	if err != nil {
+		return "", err
+	}
+	bLordID, err := SignDashaLord(d1, b)
+	if err != nil {
+		return "", err
+	}
+	aLord := d1.GetPoint(aLordID)
+	bLord := d1.GetPoint(bLordID)
+	if aLord == nil || bLord == nil {
+		return "", fmt.Errorf("chart needs %s and %s", aLordID, bLordID)
+	}
+	if math.Mod(bLord.Longitude, 30) > math.Mod(aLord.Longitude, 30) {
+		return b, nil
+	}
+	return a, nil
+}
+
+// signAntardashas divides the mahadasha md into 12 equal antardashas. forward
+// is the direction of the mahadashas.
+func signAntardashas(
+	d1 *Chart,
+	system SignDashaSystem,
+	forward bool,
+	md SignDashaPeriod,
+) ([]SignDashaPeriod, error) {
+	mdSign := md.Sign()
+	var signs []sign.Sign
+	switch system {
+	case SignDashaSystem_CharaKNRao:
+		// Same direction as the mahadashas, with the mahadasha sign last
+		signs = signSequence(stepSign(mdSign, 1, forward), 1, forward)
+	case SignDashaSystem_CharaRath:
+		signs = signSequence(mdSign, 1, mdSign.Int()%2 == 1)
+	case SignDashaSystem_Narayana:
+		lordID, err := SignDashaLord(d1, mdSign)
+		if err != nil {
+			return nil, err
+		}
+		lord := d1.GetPoint(lordID)
+		if lord == nil {
+			return nil, fmt.Errorf("lord %s of %s not found in chart", lordID, mdSign)
+		}
+		start := lord.ZodiacalPos.Sign
+		signs = signSequence(start, 1, IsOddFootedSign(start))
+	default:
+		return nil, fmt.Errorf("unknown sign dasha system: %s", system)
+	}
+
+	ret := []SignDashaPeriod{}
+	mdStart := md.Interval.Start.Time
+	mdDuration := md.Interval.End.Sub(mdStart)
+	start := mdStart
+	for i, s := range signs {
+		end := mdStart.Add(mdDuration * time.Duration(i+1) / time.Duration(len(signs)))
+		ret = append(ret, SignDashaPeriod{
+			System:   system,
+			Level:    DashaLevel_Antardasha,
+			Signs:    []sign.Sign{mdSign, s},
+			Interval: NewInterval(start, end),
+		})
+		start = end
+	}
+	return ret, nil
+}
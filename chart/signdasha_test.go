@@ -0,0 +1,107 @@
+package chart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignDashaTimeline(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+
+	// London, 2024-01-01 00:00 UTC. Virgo rises, Mercury (its lord) is in
+	// Scorpio and Ketu is in Virgo
+	birthTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d1, err := NewChartFromUTC(
+		swe, birthTime, -0.1278, 51.5074, D1ChartType, pointid.VedicPlanets)
+	require.NoError(t, err)
+
+	// Virgo is even-footed: counting backward from Virgo to Scorpio gives 11
+	// signs, so 10 years
+	years, err := SignDashaYears(d1, sign.Virgo)
+	require.NoError(t, err)
+	require.Equal(t, 10, years)
+	// Libra is odd-footed and Venus is in the next sign
+	years, err = SignDashaYears(d1, sign.Libra)
+	require.NoError(t, err)
+	require.Equal(t, 1, years)
+
+	type testCase struct {
+		system              SignDashaSystem
+		expectedMahadashas  []sign.Sign
+		expectedAntardashas []sign.Sign
+	}
+	for _, tc := range []testCase{
+		{
+			// The 9th house (Taurus) is odd-footed, so forward
+			system:              SignDashaSystem_CharaKNRao,
+			expectedMahadashas:  []sign.Sign{sign.Virgo, sign.Libra, sign.Scorpio},
+			expectedAntardashas: []sign.Sign{sign.Libra, sign.Scorpio, sign.Sagittarius},
+		},
+		{
+			// Virgo is an even sign, so backward
+			system:              SignDashaSystem_CharaRath,
+			expectedMahadashas:  []sign.Sign{sign.Virgo, sign.Leo, sign.Cancer},
+			expectedAntardashas: []sign.Sign{sign.Virgo, sign.Leo, sign.Cancer},
+		},
+		{
+			// Virgo and Pisces have one planet each, but Mercury has more
+			// degrees than Jupiter. Virgo is even-footed, but Ketu is in it,
+			// so forward through the kendras. The antardashas start from
+			// Scorpio, where Mercury is
+			system:              SignDashaSystem_Narayana,
+			expectedMahadashas:  []sign.Sign{sign.Virgo, sign.Sagittarius, sign.Pisces},
+			expectedAntardashas: []sign.Sign{sign.Scorpio, sign.Sagittarius, sign.Capricorn},
+		},
+	} {
+		t.Run(tc.system.String(), func(t *testing.T) {
+			tl, err := NewSignDashaTimeline(d1, birthTime, tc.system)
+			require.NoError(t, err)
+			require.Equal(t, birthTime, tl.Start())
+			require.False(t, tl.End().Before(
+				birthTime.Add(parseDuration(TotalVimshottariYears, 0, 0))))
+
+			mds := tl.Mahadashas()
+			for i, s := range tc.expectedMahadashas {
+				require.Equal(t, s, mds[i].Sign())
+			}
+			// The first mahadasha is Virgo's 10 years
+			require.Equal(t, birthTime, mds[0].Interval.Start.Time)
+			require.Equal(t,
+				birthTime.Add(yearsToDuration(10)),
+				mds[0].Interval.End.Time)
+			for i := 1; i < len(mds); i++ {
+				require.Equal(t, mds[i-1].Interval.End, mds[i].Interval.Start)
+			}
+
+			ads := tl.Children(mds[0])
+			require.Len(t, ads, 12)
+			for i, s := range tc.expectedAntardashas {
+				require.Equal(t, []sign.Sign{sign.Virgo, s}, ads[i].Signs)
+			}
+			require.Equal(t, mds[0].Interval.End, ads[11].Interval.End)
+
+			// Query the 2nd antardasha
+			tm := ads[1].Interval.Start.Add(time.Hour)
+			periods, didFind := tl.GetPeriodsForTime(tm, DashaLevel_Antardasha)
+			require.True(t, didFind)
+			require.Equal(t, []sign.Sign{sign.Virgo, tc.expectedAntardashas[1]}, periods[1].Signs)
+			md, didFind := tl.GetPeriodForTime(tm, DashaLevel_Mahadasha)
+			require.True(t, didFind)
+			require.Equal(t, sign.Virgo, md.Sign())
+			_, didFind = tl.GetPeriodForTime(birthTime.Add(-time.Hour), DashaLevel_Mahadasha)
+			require.False(t, didFind)
+
+			inRange := tl.GetPeriodsInRange(
+				ads[0].Interval.Start.Time,
+				ads[2].Interval.Start.Time,
+				DashaLevel_Antardasha)
+			require.Len(t, inRange, 2)
+		})
+	}
+}
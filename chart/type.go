@@ -10,11 +10,21 @@ type ChartType string
 const (
 	TropicalChartType = ChartType("tropical")
 	D1ChartType       = ChartType("d1")
+	D2ChartType       = ChartType("d2")
+	D3ChartType       = ChartType("d3")
 	D4ChartType       = ChartType("d4")
 	D7ChartType       = ChartType("d7")
 	D9ChartType       = ChartType("d9")
 	D10ChartType      = ChartType("d10")
-	// D24ChartType      = ChartType("D24")
+	D12ChartType      = ChartType("d12")
+	D16ChartType      = ChartType("d16")
+	D20ChartType      = ChartType("d20")
+	D24ChartType      = ChartType("d24")
+	D27ChartType      = ChartType("d27")
+	D30ChartType      = ChartType("d30")
+	D40ChartType      = ChartType("d40")
+	D45ChartType      = ChartType("d45")
+	D60ChartType      = ChartType("d60")
 )
 
 func (c ChartType) String() string {
@@ -28,6 +38,10 @@ func (c ChartType) Int() int {
 		return 0
 	case D1ChartType:
 		return 1
+	case D2ChartType:
+		return 2
+	case D3ChartType:
+		return 3
 	case D4ChartType:
 		return 4
 	case D7ChartType:
@@ -36,16 +50,45 @@ func (c ChartType) Int() int {
 		return 9
 	case D10ChartType:
 		return 10
-		// case D24ChartType:
-		// 	return 24
+	case D12ChartType:
+		return 12
+	case D16ChartType:
+		return 16
+	case D20ChartType:
+		return 20
+	case D24ChartType:
+		return 24
+	case D27ChartType:
+		return 27
+	case D30ChartType:
+		return 30
+	case D40ChartType:
+		return 40
+	case D45ChartType:
+		return 45
+	case D60ChartType:
+		return 60
 	}
 	panic("unreachable")
 }
 
+// Divisions is the number of parts each sign is divided into in this chart
+// type
+func (c ChartType) Divisions() int {
+	if c == TropicalChartType {
+		return 1
+	}
+	return c.Int()
+}
+
 func (c ChartType) Desc() string {
 	switch c {
 	case D1ChartType:
 		return "D1 Rashi"
+	case D2ChartType:
+		return "D2 Hora - Wealth"
+	case D3ChartType:
+		return "D3 Drekkana - Siblings"
 	case D4ChartType:
 		return "D4 Chaturthamsa - Moving home"
 	case D7ChartType:
@@ -54,8 +97,24 @@ func (c ChartType) Desc() string {
 		return "D9 Navamsa - Marriage"
 	case D10ChartType:
 		return "D10 Dasamsa - Career"
-		// case D24ChartType:
-		// 	return VargaChartFunctionTypeD24
+	case D12ChartType:
+		return "D12 Dwadasamsa - Parents"
+	case D16ChartType:
+		return "D16 Shodasamsa - Vehicles"
+	case D20ChartType:
+		return "D20 Vimsamsa - Spiritual progress"
+	case D24ChartType:
+		return "D24 Chaturvimsamsa - Education"
+	case D27ChartType:
+		return "D27 Bhamsa - Strengths and weaknesses"
+	case D30ChartType:
+		return "D30 Trimsamsa - Misfortunes"
+	case D40ChartType:
+		return "D40 Khavedamsa - Maternal legacy"
+	case D45ChartType:
+		return "D45 Akshavedamsa - Paternal legacy"
+	case D60ChartType:
+		return "D60 Shashtiamsa - Past karma"
 	}
 
 	panic("unreachable")
@@ -63,10 +122,21 @@ func (c ChartType) Desc() string {
 
 func (c ChartType) IsVarga() bool {
 	return c == D1ChartType ||
+		c == D2ChartType ||
+		c == D3ChartType ||
 		c == D4ChartType ||
 		c == D7ChartType ||
 		c == D9ChartType ||
-		c == D10ChartType
+		c == D10ChartType ||
+		c == D12ChartType ||
+		c == D16ChartType ||
+		c == D20ChartType ||
+		c == D24ChartType ||
+		c == D27ChartType ||
+		c == D30ChartType ||
+		c == D40ChartType ||
+		c == D45ChartType ||
+		c == D60ChartType
 }
 
 func (c ChartType) Karakas() []pointid.PointID {
@@ -84,11 +154,11 @@ func (c ChartType) Karakas() []pointid.PointID {
 			pointid.Jupiter,
 			pointid.Saturn,
 		}
-		// case D24ChartType:
-		// 	return []PointID{
-		// 		pointid.Mercury,
-		// 		pointid.Jupiter,
-		// 	}
+	case D24ChartType:
+		return []pointid.PointID{
+			pointid.Mercury,
+			pointid.Jupiter,
+		}
 	}
 
 	return nil
@@ -104,8 +174,8 @@ func (c ChartType) ImportantHouses() []house.House {
 		return []house.House{house.House7}
 	case D10ChartType:
 		return []house.House{house.House10}
-		// case D24ChartType:
-		// 	return []House{House5}
+	case D24ChartType:
+		return []house.House{house.House4, house.House5}
 	}
 
 	return nil
@@ -0,0 +1,267 @@
+package chart
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/afjoseph/sacredstar/astropoint"
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/afjoseph/sacredstar/zodiacalpos"
+)
+
+var (
+	// mandiGhatis are when Mandi rises, in ghatis after sunrise (by day) or
+	// sunset (by night), for a day and a night of 30 ghatis each. It's
+	// indexed by time.Weekday (BPHS 3).
+	mandiGhatis = []struct{ day, night float64 }{
+		{26, 10},
+		{22, 6},
+		{18, 2},
+		{14, 26},
+		{10, 22},
+		{6, 18},
+		{2, 14},
+	}
+	// induKalas are the rays of the planets Indu lagna is counted with
+	// (Phaladeepika 6)
+	induKalas = map[pointid.PointID]int{
+		pointid.Sun:     30,
+		pointid.Moon:    16,
+		pointid.Mars:    6,
+		pointid.Mercury: 8,
+		pointid.Jupiter: 10,
+		pointid.Venus:   12,
+		pointid.Saturn:  1,
+	}
+)
+
+const (
+	// ghatisPerDay is the number of ghatis (24 minutes) from a sunrise to
+	// the next one
+	ghatisPerDay = 60.0
+	// Dhuma is this far ahead of the Sun
+	dhumaDistance = 133 + 20.0/60
+)
+
+// hinduDay is the Hindu day a moment is in: it starts at sunrise, and its
+// weekday is the one of that sunrise
+type hinduDay struct {
+	sunrise     float64
+	sunset      float64
+	nextSunrise float64
+	weekday     time.Weekday
+}
+
+// newHinduDay returns the Hindu day jd is in, as seen from lon/lat. Sunrise
+// and sunset follow the Hindu convention, like in the panchanga package.
+func newHinduDay(
+	swe *wrapper.SwissEph,
+	jd float64,
+	lon, lat float64,
+) (*hinduDay, error) {
+	riseTrans := func(jd float64, event wrapper.RiseTransEvent) (float64, error) {
+		return swe.RiseTrans(jd, pointid.Sun.SwissEphID(), lon, lat, event, true)
+	}
+	ret := &hinduDay{}
+	for daysBack := 1.0; daysBack <= 3 && ret.sunrise == 0; daysBack++ {
+		sr, err := riseTrans(jd-daysBack, wrapper.RiseTransEvent_Rise)
+		if err != nil {
+			return nil, fmt.Errorf("while finding sunrise: %v", err)
+		}
+		if sr <= jd {
+			ret.sunrise = sr
+		}
+	}
+	if ret.sunrise == 0 {
+		return nil, fmt.Errorf("could not find sunrise before %f", jd)
+	}
+	var err error
+	if ret.sunset, err = riseTrans(ret.sunrise, wrapper.RiseTransEvent_Set); err != nil {
+		return nil, fmt.Errorf("while finding sunset: %v", err)
+	}
+	// Start a minute after sunrise so that it's not found again
+	if ret.nextSunrise, err = riseTrans(ret.sunrise+1.0/24/60, wrapper.RiseTransEvent_Rise); err != nil {
+		return nil, fmt.Errorf("while finding next sunrise: %v", err)
+	}
+	// The weekday is the one of the local date at sunrise, using the local
+	// mean time of lon
+	ret.weekday = swe.JulianDayToGoTime(ret.sunrise + lon/360).Weekday()
+	return ret, nil
+}
+
+// gulika returns when Gulika rises in the day (or night) jd is in: at the
+// start of Saturn's part of it. The day and the night are divided in eight
+// parts, ruled in weekday order (Sun, Moon, Mars...): the day's first part
+// is ruled by the weekday lord, and the night's by the fifth lord from it.
+// The eighth part has no lord.
+func (hd *hinduDay) gulika(jd float64) float64 {
+	first := int(hd.weekday)
+	start, end := hd.sunrise, hd.sunset
+	if jd >= hd.sunset {
+		first += 4
+		start, end = hd.sunset, hd.nextSunrise
+	}
+	idx := (int(time.Saturday) - first%7 + 7) % 7
+	return start + float64(idx)*(end-start)/8
+}
+
+// mandi returns when Mandi rises in the day (or night) jd is in
+func (hd *hinduDay) mandi(jd float64) float64 {
+	if jd >= hd.sunset {
+		return hd.sunset + (hd.nextSunrise-hd.sunset)*mandiGhatis[hd.weekday].night/30
+	}
+	return hd.sunrise + (hd.sunset-hd.sunrise)*mandiGhatis[hd.weekday].day/30
+}
+
+// IsSpecialPoint reports whether pid is a special lagna or an upagraha. They're
+// sensitive points rather than bodies, so they're left out of the aspects,
+// drishtis and stelliums.
+func IsSpecialPoint(pid pointid.PointID) bool {
+	return pid.IsSpecialLagna() || pid.IsUpagraha()
+}
+
+// calculateSpecialPoints calculates the special lagnas and upagrahas in pids
+// for a chart of chartType, which must be sidereal. Like the planets, their
+// Longitude is the D1 one and their ZodiacalPos and House are in chartType.
+// They have no speed, and they're left out of Chart.Aspects.
+func calculateSpecialPoints(
+	swe *wrapper.SwissEph,
+	timeInJulian float64,
+	lon, lat float64,
+	chartType ChartType,
+	asc *astropoint.AstroPoint,
+	pids []pointid.PointID,
+	ayanamsa wrapper.Ayanamsa,
+) ([]*astropoint.AstroPoint, error) {
+	if !chartType.IsVarga() {
+		return nil, fmt.Errorf("%s chart is not sidereal", chartType)
+	}
+	sun, err := calculatePlanet(swe, timeInJulian, pointid.Sun, D1ChartType, nil, ayanamsa)
+	if err != nil {
+		return nil, fmt.Errorf("while calculating Sun: %v", err)
+	}
+	moon, err := calculatePlanet(swe, timeInJulian, pointid.Moon, D1ChartType, nil, ayanamsa)
+	if err != nil {
+		return nil, fmt.Errorf("while calculating Moon: %v", err)
+	}
+	// The Hindu day is only needed by some of the points, and finding it
+	// fails where the Sun doesn't rise
+	var day *hinduDay
+	getDay := func() (*hinduDay, error) {
+		if day != nil {
+			return day, nil
+		}
+		var err error
+		day, err = newHinduDay(swe, timeInJulian, lon, lat)
+		return day, err
+	}
+	// sunriseLagna is a lagna that starts from the Sun at sunrise and moves
+	// a sign every ghatisPerSign ghatis
+	sunriseLagna := func(ghatisPerSign float64) (float64, error) {
+		day, err := getDay()
+		if err != nil {
+			return 0, err
+		}
+		s, err := calculatePlanet(swe, day.sunrise, pointid.Sun, D1ChartType, nil, ayanamsa)
+		if err != nil {
+			return 0, fmt.Errorf("while calculating Sun at sunrise: %v", err)
+		}
+		ghatis := (timeInJulian - day.sunrise) * ghatisPerDay
+		return s.Longitude + ghatis/ghatisPerSign*30, nil
+	}
+	ascendantAt := func(jd float64) (float64, error) {
+		a, err := calculateAscendant(swe, jd, lon, lat, D1ChartType, ayanamsa)
+		if err != nil {
+			return 0, err
+		}
+		return a.Longitude, nil
+	}
+	dhuma := sun.Longitude + dhumaDistance
+	vyatipata := 360 - dhuma
+	parivesha := vyatipata + 180
+	indrachapa := 360 - parivesha
+
+	ret := []*astropoint.AstroPoint{}
+	for _, pid := range pids {
+		var l float64
+		switch pid {
+		case pointid.HoraLagna:
+			// A sign every hora (2.5 ghatis)
+			l, err = sunriseLagna(2.5)
+		case pointid.GhatiLagna:
+			l, err = sunriseLagna(1)
+		case pointid.BhavaLagna:
+			l, err = sunriseLagna(5)
+		case pointid.SreeLagna:
+			// As far from the ascendant as the Moon has gone through its
+			// nakshatra
+			progress := math.Mod(moon.Longitude, NakshatraSpan) / NakshatraSpan
+			l = asc.Longitude + progress*360
+		case pointid.InduLagna:
+			l = induLagna(asc.Longitude, moon.Longitude)
+		case pointid.Gulika:
+			var d *hinduDay
+			if d, err = getDay(); err == nil {
+				l, err = ascendantAt(d.gulika(timeInJulian))
+			}
+		case pointid.Mandi:
+			var d *hinduDay
+			if d, err = getDay(); err == nil {
+				l, err = ascendantAt(d.mandi(timeInJulian))
+			}
+		case pointid.Dhuma:
+			l = dhuma
+		case pointid.Vyatipata:
+			l = vyatipata
+		case pointid.Parivesha:
+			l = parivesha
+		case pointid.Indrachapa:
+			l = indrachapa
+		case pointid.Upaketu:
+			l = indrachapa + 16 + 40.0/60
+		default:
+			return nil, fmt.Errorf("%s is not a special lagna or upagraha", pid)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("while calculating %s: %v", pid, err)
+		}
+		l = math.Mod(math.Mod(l, 360)+360, 360)
+		zp, err := transformZodiacalPosToVarga(
+			pid,
+			zodiacalpos.NewZodiacalPosFromLongitude(l),
+			chartType,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("while transforming %s to %s: %v", pid, chartType, err)
+		}
+		ret = append(ret, &astropoint.AstroPoint{
+			ID:          pid,
+			Longitude:   l,
+			ZodiacalPos: zp,
+			House:       house.NewHouseFromSign(zp.Sign, asc.ZodiacalPos.Sign),
+		})
+	}
+	return ret, nil
+}
+
+// induLagna returns the longitude of Indu lagna: the kalas of the 9th lords
+// from the ascendant and from the Moon are added up, and Indu lagna is that
+// many signs (once the 12s are taken out) from the Moon.
+//
+// Indu lagna is only a sign. We give it the Moon's degrees in its sign so
+// that it has a position in the varga charts.
+func induLagna(ascLon, moonLon float64) float64 {
+	ninthLord := func(lon float64) pointid.PointID {
+		s, _ := sign.NewSignFromInt(sign.DegreeToSign(lon).Int() + 8)
+		return s.TraditionalRuler()
+	}
+	count := (induKalas[ninthLord(ascLon)] + induKalas[ninthLord(moonLon)]) % 12
+	if count == 0 {
+		count = 12
+	}
+	return moonLon + float64(count-1)*30
+}
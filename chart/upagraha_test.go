@@ -0,0 +1,112 @@
+package chart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/aspect"
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpecialLagnasAndUpagrahas(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+	// London coordinates
+	lon, lat := -0.1278, 51.5074
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	pids := append([]pointid.PointID{}, pointid.VedicPlanets...)
+	pids = append(pids, pointid.SpecialLagnas...)
+	pids = append(pids, pointid.Upagrahas...)
+
+	d1, err := NewChartFromUTC(swe, date, lon, lat, D1ChartType, pids)
+	require.NoError(t, err)
+	sun := d1.MustGetPoint(pointid.Sun).Longitude
+	require.InDelta(t, sun+133+20.0/60-360, d1.MustGetPoint(pointid.Dhuma).Longitude, 1e-9)
+	// Upaketu is a sign behind the Sun
+	require.InDelta(t, sun-30, d1.MustGetPoint(pointid.Upaketu).Longitude, 1e-9)
+	for pid, expected := range map[pointid.PointID]struct {
+		s sign.Sign
+		h house.House
+	}{
+		// They start from the Sun at sunrise, 15h47m (39.45 ghatis)
+		// earlier, on Sunday at 08:13
+		pointid.HoraLagna:  {sign.Aries, house.House8},
+		pointid.GhatiLagna: {sign.Pisces, house.House7},
+		pointid.BhavaLagna: {sign.Leo, house.House12},
+		// The Moon is 88% through Magha: Sree lagna is 88% of the zodiac
+		// from the Virgo ascendant
+		pointid.SreeLagna: {sign.Leo, house.House12},
+		// The 9th lords from the ascendant (Venus) and the Moon (Mars) have
+		// 12+6 kalas: that's the 6th sign from the Moon
+		pointid.InduLagna: {sign.Capricorn, house.House5},
+		// It's the night of Sunday: Saturn rules its 3rd part
+		pointid.Gulika:     {sign.Leo, house.House12},
+		pointid.Dhuma:      {sign.Aries, house.House8},
+		pointid.Vyatipata:  {sign.Pisces, house.House7},
+		pointid.Parivesha:  {sign.Virgo, house.House1},
+		pointid.Indrachapa: {sign.Libra, house.House2},
+	} {
+		p := d1.MustGetPoint(pid)
+		require.Equal(t, expected.s, p.ZodiacalPos.Sign, pid)
+		require.Equal(t, expected.h, p.House, pid)
+	}
+
+	// Gulika is the ascendant at the start of Saturn's part of the night
+	jd := swe.GoTimeToJulianDay(date)
+	day, err := newHinduDay(swe, jd, lon, lat)
+	require.NoError(t, err)
+	require.Equal(t, time.Sunday, day.weekday)
+	require.Equal(t,
+		time.Date(2023, 12, 31, 19, 59, 0, 0, time.UTC),
+		swe.JulianDayToGoTime(day.gulika(jd)),
+	)
+	// Mandi rises 10 ghatis into the night
+	require.Equal(t,
+		time.Date(2023, 12, 31, 21, 20, 0, 0, time.UTC),
+		swe.JulianDayToGoTime(day.mandi(jd)),
+	)
+	// In varga charts, they're transformed like the planets
+	d9, err := NewChartFromUTC(swe, date, lon, lat, D9ChartType, pids)
+	require.NoError(t, err)
+	for _, pid := range append(pointid.SpecialLagnas, pointid.Upagrahas...) {
+		pos, err := d1.GetVargaPos(pid, D9ChartType)
+		require.NoError(t, err)
+		p := d9.MustGetPoint(pid)
+		require.Equal(t, pos, p.ZodiacalPos, pid)
+		require.Equal(t,
+			house.NewHouseFromSign(pos.Sign, d9.MustGetPoint(pointid.ASC).ZodiacalPos.Sign),
+			p.House,
+			pid,
+		)
+	}
+
+	// They're not in the aspects, and their aspects never perfect
+	for _, a := range d1.Aspects {
+		require.False(t, IsSpecialPoint(a.P1) || IsSpecialPoint(a.P2), a.String())
+	}
+	_, err = d1.FindAspectPerfection(swe, &aspect.Aspect{
+		P1:   pointid.Moon,
+		P2:   pointid.Gulika,
+		Type: aspect.AspectType_Conjunction,
+	})
+	require.Error(t, err)
+	// Nor in the drishtis, sign-based or not
+	for _, d := range append(d1.Drishtis(NodeDrishti_Jupiter), d1.VirupaDrishtis()...) {
+		require.False(t, IsSpecialPoint(d.From) || IsSpecialPoint(d.To), d.String())
+	}
+	for i := 1; i <= 12; i++ {
+		s, err := sign.NewSignFromInt(i)
+		require.NoError(t, err)
+		for _, pid := range d1.PointsWithRashiDrishtiOn(s) {
+			require.False(t, IsSpecialPoint(pid), pid)
+		}
+	}
+
+	// They're only defined in sidereal charts
+	_, err = NewChartFromUTC(swe, date, lon, lat, TropicalChartType, pids)
+	require.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package chart
 
 import (
+	"fmt"
 	"math"
 
 	"github.com/afjoseph/sacredstar/pointid"
@@ -19,6 +20,10 @@ func transformZodiacalPosToVarga(
 	}
 
 	switch chartType {
+	case D2ChartType:
+		return transformZodiacalPosToD2(pid, zp)
+	case D3ChartType:
+		return transformZodiacalPosToD3(pid, zp)
 	case D4ChartType:
 		return transformZodiacalPosToD4(pid, zp)
 	case D7ChartType:
@@ -27,13 +32,109 @@ func transformZodiacalPosToVarga(
 		return transformZodiacalPosToD9(pid, zp)
 	case D10ChartType:
 		return transformZodiacalPosToD10(pid, zp)
-	// case D24ChartType:
-	// 	return transformZodiacalPosToD24(pid, zp)
+	case D12ChartType:
+		return transformZodiacalPosToD12(pid, zp)
+	case D16ChartType, D45ChartType:
+		// Movable signs start from Aries, fixed ones from Leo and dual ones
+		// from Sagittarius
+		return transformZodiacalPosByModality(zp, chartType, sign.Aries, sign.Leo, sign.Sagittarius)
+	case D20ChartType:
+		// Movable signs start from Aries, fixed ones from Sagittarius and
+		// dual ones from Leo
+		return transformZodiacalPosByModality(zp, chartType, sign.Aries, sign.Sagittarius, sign.Leo)
+	case D24ChartType:
+		// Odd signs start from Leo and even ones from Cancer
+		return transformZodiacalPosByParity(zp, chartType, sign.Leo, sign.Cancer)
+	case D27ChartType:
+		return transformZodiacalPosToD27(pid, zp)
+	case D30ChartType:
+		return transformZodiacalPosToD30(pid, zp)
+	case D40ChartType:
+		// Odd signs start from Aries and even ones from Libra
+		return transformZodiacalPosByParity(zp, chartType, sign.Aries, sign.Libra)
+	case D60ChartType:
+		// Every sign starts from itself
+		return transformZodiacalPosFromSign(zp, chartType, zp.Sign)
 	default:
 		panic("unreachable")
 	}
 }
 
+// GetVargaPos returns the position of pid in the varga chartType without
+// casting it: it's transformed from the D1 position of pid, which c (a D1 or
+// another varga chart) has in AstroPoint.Longitude
+func (c *Chart) GetVargaPos(
+	pid pointid.PointID,
+	chartType ChartType,
+) (*zodiacalpos.ZodiacalPos, error) {
+	if !c.ChartType.IsVarga() || !chartType.IsVarga() {
+		return nil, fmt.Errorf(
+			"can't get %s position from a %s chart",
+			chartType,
+			c.ChartType,
+		)
+	}
+	p := c.GetPoint(pid)
+	if p == nil {
+		return nil, fmt.Errorf("%s not found in chart", pid)
+	}
+	return transformZodiacalPosToVarga(
+		pid,
+		zodiacalpos.NewZodiacalPosFromLongitude(p.Longitude),
+		chartType,
+	)
+}
+
+func transformZodiacalPosToD2(
+	pid pointid.PointID,
+	zp *zodiacalpos.ZodiacalPos,
+) (*zodiacalpos.ZodiacalPos, error) {
+	totalDivisions := 2
+	cusp := 30.0 / float64(totalDivisions)
+	signDeg := zp.SignDegrees()
+	currDivision := int(signDeg / cusp)
+	perc := math.Mod(signDeg, cusp) / cusp
+	newDegrees := perc * 30.0
+	newMinutes := math.Mod(newDegrees, 1) * 60.0
+
+	// Parashari hora: the first half of odd signs is the Sun's (Leo) and
+	// the second half is the Moon's (Cancer). It's the other way around for
+	// even signs.
+	newSign := sign.Leo
+	if (zp.Sign.Int()%2 == 1) == (currDivision == 1) {
+		newSign = sign.Cancer
+	}
+	return zodiacalpos.NewZodiacalPos(
+		newSign,
+		int(newDegrees),
+		int(newMinutes),
+	), nil
+}
+
+func transformZodiacalPosToD3(
+	pid pointid.PointID,
+	zp *zodiacalpos.ZodiacalPos,
+) (*zodiacalpos.ZodiacalPos, error) {
+	totalDivisions := 3
+	cusp := 30.0 / float64(totalDivisions)
+	signDeg := zp.SignDegrees()
+	currDivision := int(signDeg / cusp)
+	perc := math.Mod(signDeg, cusp) / cusp
+	newDegrees := perc * 30.0
+	newMinutes := math.Mod(newDegrees, 1) * 60.0
+
+	// Sign calculation: the sign itself, then the 5th and 9th from it
+	newSign, err := sign.NewSignFromInt(zp.Sign.Int() + 4*currDivision)
+	if err != nil {
+		return nil, err
+	}
+	return zodiacalpos.NewZodiacalPos(
+		newSign,
+		int(newDegrees),
+		int(newMinutes),
+	), nil
+}
+
 func transformZodiacalPosToD4(
 	pid pointid.PointID,
 	zp *zodiacalpos.ZodiacalPos,
@@ -198,30 +299,141 @@ func transformZodiacalPosToD10(
 	), nil
 }
 
-// func transformZodiacalPosToD24(
-// 	pid pointid.PointID,
-// 	zp *zodiacalpos.ZodiacalPos,
-// ) (*zodiacalpos.ZodiacalPos, error) {
-// 	totalDivisions := 24
-// 	cusp := 30.0 / float64(totalDivisions)
-// 	signDeg := zp.SignDegrees()
-// 	currDivision := int(signDeg / cusp)
-// 	perc := math.Mod(signDeg, cusp) / cusp
-// 	newDegrees := perc * 30.0
-// 	newMinutes := math.Mod(newDegrees, 1) * 60.0
-
-// 	// Sign calculation
-// 	// - odd signs increase by 1
-// 	// - even signs increase by 8+1
-// 	newSignInt := zp.Sign.Int()
-// 	if newSignInt%2 == 0 {
-// 		newSignInt += 8
-// 	}
-// 	newSignInt += currDivision
-// 	if newSignInt > 12 {
-// 		newSignInt -= 12
-// 	}
-// 	newSignInt = int(math.Mod(float64(newSignInt), 12))
-// 	newSign := sign.NewSignFromInt(newSignInt)
-// 	return zodiacalpos.NewZodiacalPos(newSign, int(newDegrees), int(newMinutes)), nil
-// }
+func transformZodiacalPosToD12(
+	pid pointid.PointID,
+	zp *zodiacalpos.ZodiacalPos,
+) (*zodiacalpos.ZodiacalPos, error) {
+	totalDivisions := 12
+	cusp := 30.0 / float64(totalDivisions)
+	signDeg := zp.SignDegrees()
+	currDivision := int(signDeg / cusp)
+	perc := math.Mod(signDeg, cusp) / cusp
+	newDegrees := perc * 30.0
+	newMinutes := math.Mod(newDegrees, 1) * 60.0
+
+	// Sign calculation: count the divisions from the sign itself
+	newSign, err := sign.NewSignFromInt(zp.Sign.Int() + currDivision)
+	if err != nil {
+		return nil, err
+	}
+	return zodiacalpos.NewZodiacalPos(
+		newSign,
+		int(newDegrees),
+		int(newMinutes),
+	), nil
+}
+
+func transformZodiacalPosToD30(
+	pid pointid.PointID,
+	zp *zodiacalpos.ZodiacalPos,
+) (*zodiacalpos.ZodiacalPos, error) {
+	// Parashari trimsamsa: the divisions are unequal and ruled by Mars,
+	// Saturn, Jupiter, Mercury and Venus. Even signs use them in reverse.
+	type division struct {
+		end  float64
+		sign sign.Sign
+	}
+	divisions := []division{
+		{5, sign.Aries},
+		{10, sign.Aquarius},
+		{18, sign.Sagittarius},
+		{25, sign.Gemini},
+		{30, sign.Libra},
+	}
+	if zp.Sign.Int()%2 == 0 {
+		divisions = []division{
+			{5, sign.Taurus},
+			{12, sign.Virgo},
+			{20, sign.Pisces},
+			{25, sign.Capricorn},
+			{30, sign.Scorpio},
+		}
+	}
+	signDeg := zp.SignDegrees()
+	start := 0.0
+	for _, d := range divisions {
+		if signDeg < d.end || d.end == 30 {
+			perc := (signDeg - start) / (d.end - start)
+			newDegrees := perc * 30.0
+			newMinutes := math.Mod(newDegrees, 1) * 60.0
+			return zodiacalpos.NewZodiacalPos(
+				d.sign,
+				int(newDegrees),
+				int(newMinutes),
+			), nil
+		}
+		start = d.end
+	}
+	panic("unreachable")
+}
+
+func transformZodiacalPosToD27(
+	pid pointid.PointID,
+	zp *zodiacalpos.ZodiacalPos,
+) (*zodiacalpos.ZodiacalPos, error) {
+	// Fiery signs start from Aries, earthy ones from Cancer, airy ones from
+	// Libra and watery ones from Capricorn: the nakshatra padas run through
+	// the zodiac without a break
+	first, err := sign.NewSignFromInt(1 + 3*((zp.Sign.Int()-1)%4))
+	if err != nil {
+		return nil, err
+	}
+	return transformZodiacalPosFromSign(zp, D27ChartType, first)
+}
+
+// transformZodiacalPosByModality transforms zp to the equal divisions of
+// chartType, counting them from movable, fixed or dual depending on the
+// sign of zp
+func transformZodiacalPosByModality(
+	zp *zodiacalpos.ZodiacalPos,
+	chartType ChartType,
+	movable, fixed, dual sign.Sign,
+) (*zodiacalpos.ZodiacalPos, error) {
+	switch zp.Sign.Int() % 3 {
+	case 1:
+		return transformZodiacalPosFromSign(zp, chartType, movable)
+	case 2:
+		return transformZodiacalPosFromSign(zp, chartType, fixed)
+	}
+	return transformZodiacalPosFromSign(zp, chartType, dual)
+}
+
+// transformZodiacalPosByParity transforms zp to the equal divisions of
+// chartType, counting them from odd or even depending on the sign of zp
+func transformZodiacalPosByParity(
+	zp *zodiacalpos.ZodiacalPos,
+	chartType ChartType,
+	odd, even sign.Sign,
+) (*zodiacalpos.ZodiacalPos, error) {
+	if zp.Sign.Int()%2 == 1 {
+		return transformZodiacalPosFromSign(zp, chartType, odd)
+	}
+	return transformZodiacalPosFromSign(zp, chartType, even)
+}
+
+// transformZodiacalPosFromSign transforms zp to the equal divisions of
+// chartType, the first of which is in first and the next ones in the signs
+// that follow it
+func transformZodiacalPosFromSign(
+	zp *zodiacalpos.ZodiacalPos,
+	chartType ChartType,
+	first sign.Sign,
+) (*zodiacalpos.ZodiacalPos, error) {
+	totalDivisions := chartType.Divisions()
+	cusp := 30.0 / float64(totalDivisions)
+	signDeg := zp.SignDegrees()
+	currDivision := int(signDeg / cusp)
+	perc := math.Mod(signDeg, cusp) / cusp
+	newDegrees := perc * 30.0
+	newMinutes := math.Mod(newDegrees, 1) * 60.0
+
+	newSign, err := sign.NewSignFromInt(first.Int() + currDivision)
+	if err != nil {
+		return nil, err
+	}
+	return zodiacalpos.NewZodiacalPos(
+		newSign,
+		int(newDegrees),
+		int(newMinutes),
+	), nil
+}
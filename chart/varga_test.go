@@ -0,0 +1,66 @@
+package chart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetVargaPos(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// London coordinates
+	lon, lat := -0.1278, 51.5074
+	d1, err := NewChartFromUTC(swe, date, lon, lat, D1ChartType, pointid.VedicPlanets)
+	require.NoError(t, err)
+
+	// The Sun is in Sagittarius 15°50', a dual, fiery and odd sign
+	for ct, expected := range map[ChartType]sign.Sign{
+		D16ChartType: sign.Leo,       // 9th division from Sagittarius
+		D20ChartType: sign.Gemini,    // 11th division from Leo
+		D24ChartType: sign.Leo,       // 13th division from Leo
+		D27ChartType: sign.Gemini,    // 15th division from Aries
+		D40ChartType: sign.Capricorn, // 22nd division from Aries
+		D45ChartType: sign.Scorpio,   // 24th division from Sagittarius
+		D60ChartType: sign.Cancer,    // 32nd division from Sagittarius
+	} {
+		zp, err := d1.GetVargaPos(pointid.Sun, ct)
+		require.NoError(t, err)
+		require.Equal(t, expected, zp.Sign, ct)
+	}
+
+	// Same as casting the varga
+	for _, ct := range []ChartType{
+		D2ChartType, D3ChartType, D4ChartType, D7ChartType, D9ChartType,
+		D10ChartType, D12ChartType, D16ChartType, D20ChartType, D24ChartType,
+		D27ChartType, D30ChartType, D40ChartType, D45ChartType, D60ChartType,
+	} {
+		c, err := NewChartFromUTC(swe, date, lon, lat, ct, pointid.VedicPlanets)
+		require.NoError(t, err)
+		for _, p := range c.Points {
+			if p.ID == pointid.Ketu {
+				// Ketu is put opposite Rahu in the varga charts, and
+				// doesn't keep its D1 longitude
+				continue
+			}
+			zp, err := d1.GetVargaPos(p.ID, ct)
+			require.NoError(t, err)
+			require.Equal(t, p.ZodiacalPos.Sign, zp.Sign, "%s in %s", p.ID, ct)
+			// It works from other vargas too, since they keep the D1
+			// longitude
+			fromVarga, err := c.GetVargaPos(p.ID, ct)
+			require.NoError(t, err)
+			require.Equal(t, zp, fromVarga)
+		}
+	}
+
+	tropical, err := NewChartFromUTC(swe, date, lon, lat, TropicalChartType, pointid.VedicPlanets)
+	require.NoError(t, err)
+	_, err = tropical.GetVargaPos(pointid.Sun, D9ChartType)
+	require.Error(t, err)
+}
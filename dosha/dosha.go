@@ -0,0 +1,293 @@
+// Package dosha reports the common Vedic afflictions ("doshas") of a D1
+// chart: Manglik dosha, Kaal Sarp dosha and Pitra dosha, plus Saturn's Sade
+// Sati and Dhaiya transits over the natal Moon.
+package dosha
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+)
+
+// planets are the seven classical planets
+var planets = []pointid.PointID{
+	pointid.Sun,
+	pointid.Moon,
+	pointid.Mars,
+	pointid.Mercury,
+	pointid.Jupiter,
+	pointid.Venus,
+	pointid.Saturn,
+}
+
+// checkChart makes sure d1 is a D1 chart with the points the doshas need
+func checkChart(d1 *chart.Chart) error {
+	if d1.ChartType != chart.D1ChartType {
+		return fmt.Errorf("doshas need a D1 chart, got %s", d1.ChartType)
+	}
+	for _, pid := range append(slices.Clone(planets), pointid.ASC, pointid.Rahu, pointid.Ketu) {
+		if d1.GetPoint(pid) == nil {
+			return fmt.Errorf("%s not found in chart", pid)
+		}
+	}
+	return nil
+}
+
+// ManglikHouses are the houses Mars causes Manglik dosha in
+var ManglikHouses = []int{1, 2, 4, 7, 8, 12}
+
+// ManglikReference is Mars's house counted from one reference point
+type ManglikReference struct {
+	From      pointid.PointID `json:"from"`
+	House     house.House     `json:"house"`
+	IsManglik bool            `json:"isManglik"`
+}
+
+// Manglik is the Manglik (or Kuja) dosha: Mars in the 1st, 2nd, 4th, 7th,
+// 8th or 12th house from the lagna, the Moon or Venus
+type Manglik struct {
+	// References are Mars's house from the lagna, the Moon and Venus, in
+	// that order
+	References []ManglikReference `json:"references"`
+	// Cancellations are the rules that cancel the dosha, if any fired
+	Cancellations []string `json:"cancellations"`
+}
+
+func (m *Manglik) String() string {
+	return fmt.Sprintf(
+		"Manglik{Count: %d, IsPresent: %t, IsCancelled: %t}",
+		m.Count(),
+		m.IsPresent(),
+		m.IsCancelled(),
+	)
+}
+
+// Count returns how many of the references Mars is in a Manglik house from,
+// from 0 to 3. The more, the stronger the dosha.
+func (m *Manglik) Count() int {
+	ret := 0
+	for _, r := range m.References {
+		if r.IsManglik {
+			ret++
+		}
+	}
+	return ret
+}
+
+func (m *Manglik) IsCancelled() bool {
+	return len(m.Cancellations) > 0
+}
+
+// IsPresent reports whether the chart is Manglik and the dosha is not
+// cancelled
+func (m *Manglik) IsPresent() bool {
+	return m.Count() > 0 && !m.IsCancelled()
+}
+
+// CalculateManglik returns the Manglik dosha of d1
+func CalculateManglik(d1 *chart.Chart) (*Manglik, error) {
+	if err := checkChart(d1); err != nil {
+		return nil, err
+	}
+	mars := d1.MustGetPoint(pointid.Mars)
+	ret := &Manglik{
+		References:    []ManglikReference{},
+		Cancellations: []string{},
+	}
+	for _, from := range []pointid.PointID{pointid.ASC, pointid.Moon, pointid.Venus} {
+		h := house.NewHouseFromSign(
+			mars.ZodiacalPos.Sign,
+			d1.MustGetPoint(from).ZodiacalPos.Sign,
+		)
+		ret.References = append(ret.References, ManglikReference{
+			From:      from,
+			House:     h,
+			IsManglik: slices.Contains(ManglikHouses, h.Int()),
+		})
+	}
+	if ret.Count() == 0 {
+		return ret, nil
+	}
+
+	s := mars.ZodiacalPos.Sign
+	if mars.IsDomicile() || mars.IsExalted() {
+		ret.Cancellations = append(ret.Cancellations,
+			"mars in its own or exaltation sign")
+	}
+	if d1.MustGetPoint(pointid.Jupiter).ZodiacalPos.Sign == s ||
+		d1.HasDrishti(pointid.Jupiter, pointid.Mars, chart.NodeDrishti_Jupiter) {
+		ret.Cancellations = append(ret.Cancellations,
+			"mars joined or aspected by jupiter")
+	}
+	// Mars is not harmful in some house and sign combinations (counted
+	// from the lagna)
+	for _, c := range []struct {
+		h     int
+		signs []sign.Sign
+	}{
+		{2, []sign.Sign{sign.Gemini, sign.Virgo}},
+		{7, []sign.Sign{sign.Cancer, sign.Capricorn}},
+		{8, []sign.Sign{sign.Sagittarius, sign.Pisces}},
+		{12, []sign.Sign{sign.Taurus, sign.Libra}},
+	} {
+		if mars.House.Int() == c.h && slices.Contains(c.signs, s) {
+			ret.Cancellations = append(ret.Cancellations, fmt.Sprintf(
+				"mars in the %s house in %s",
+				mars.House,
+				s,
+			))
+		}
+	}
+	return ret, nil
+}
+
+// KaalSarpType is named after the house Rahu is in
+type KaalSarpType string
+
+const (
+	KaalSarpType_Anant      KaalSarpType = "anant"
+	KaalSarpType_Kulik      KaalSarpType = "kulik"
+	KaalSarpType_Vasuki     KaalSarpType = "vasuki"
+	KaalSarpType_Shankhpal  KaalSarpType = "shankhpal"
+	KaalSarpType_Padma      KaalSarpType = "padma"
+	KaalSarpType_Mahapadma  KaalSarpType = "mahapadma"
+	KaalSarpType_Takshak    KaalSarpType = "takshak"
+	KaalSarpType_Karkotak   KaalSarpType = "karkotak"
+	KaalSarpType_Shankhchud KaalSarpType = "shankhchud"
+	KaalSarpType_Ghatak     KaalSarpType = "ghatak"
+	KaalSarpType_Vishdhar   KaalSarpType = "vishdhar"
+	KaalSarpType_Sheshnag   KaalSarpType = "sheshnag"
+)
+
+func (kt KaalSarpType) String() string {
+	return string(kt)
+}
+
+// kaalSarpTypes is indexed by Rahu's house - 1
+var kaalSarpTypes = []KaalSarpType{
+	KaalSarpType_Anant,
+	KaalSarpType_Kulik,
+	KaalSarpType_Vasuki,
+	KaalSarpType_Shankhpal,
+	KaalSarpType_Padma,
+	KaalSarpType_Mahapadma,
+	KaalSarpType_Takshak,
+	KaalSarpType_Karkotak,
+	KaalSarpType_Shankhchud,
+	KaalSarpType_Ghatak,
+	KaalSarpType_Vishdhar,
+	KaalSarpType_Sheshnag,
+}
+
+// KaalSarp is the Kaal Sarp dosha: all seven planets hemmed in on one side
+// of the Rahu-Ketu axis
+type KaalSarp struct {
+	IsPresent bool         `json:"isPresent"`
+	Type      KaalSarpType `json:"type"`
+	// RahuHouse is the house of Rahu, which gives the type its name
+	RahuHouse house.House `json:"rahuHouse"`
+	// From is the node the planets follow in the order of the zodiac:
+	// pointid.Rahu for the "proper" Kaal Sarp (Udita), and pointid.Ketu for
+	// its reverse (Anudita, also called Kaal Amrit)
+	From pointid.PointID `json:"from"`
+	// IsPartial is true if a single planet breaks out of the axis. Some
+	// astrologers still count it as a (weaker) Kaal Sarp.
+	IsPartial bool `json:"isPartial"`
+	// Outside are the planets that break out of the axis
+	Outside []pointid.PointID `json:"outside"`
+}
+
+func (ks *KaalSarp) String() string {
+	return fmt.Sprintf(
+		"KaalSarp{IsPresent: %t, Type: %s, From: %s, IsPartial: %t}",
+		ks.IsPresent,
+		ks.Type,
+		ks.From,
+		ks.IsPartial,
+	)
+}
+
+// CalculateKaalSarp returns the Kaal Sarp dosha of d1. Planets are placed
+// against the axis by longitude, not by sign, so a planet in Rahu's sign but
+// behind it breaks the dosha.
+func CalculateKaalSarp(d1 *chart.Chart) (*KaalSarp, error) {
+	if err := checkChart(d1); err != nil {
+		return nil, err
+	}
+	rahu := d1.MustGetPoint(pointid.Rahu)
+	// Planets between Rahu and Ketu in the order of the zodiac, and the other
+	// way around
+	fromRahu, fromKetu := []pointid.PointID{}, []pointid.PointID{}
+	for _, pid := range planets {
+		arc := math.Mod(d1.MustGetPoint(pid).Longitude-rahu.Longitude+360, 360)
+		if arc < 180 {
+			fromRahu = append(fromRahu, pid)
+		} else {
+			fromKetu = append(fromKetu, pid)
+		}
+	}
+	ret := &KaalSarp{
+		Type:      kaalSarpTypes[rahu.House.Int()-1],
+		RahuHouse: rahu.House,
+	}
+	if len(fromKetu) < len(fromRahu) {
+		ret.From, ret.Outside = pointid.Rahu, fromKetu
+	} else {
+		ret.From, ret.Outside = pointid.Ketu, fromRahu
+	}
+	ret.IsPresent = len(ret.Outside) == 0
+	ret.IsPartial = len(ret.Outside) == 1
+	return ret, nil
+}
+
+// Pitra is the Pitra dosha, the affliction of the ancestors: the Sun (the
+// father) or the 9th house (of the ancestors) afflicted by the nodes or
+// Saturn
+type Pitra struct {
+	// Reasons are the afflictions found
+	Reasons []string `json:"reasons"`
+}
+
+func (p *Pitra) String() string {
+	return fmt.Sprintf("Pitra{IsPresent: %t, Reasons: %v}", p.IsPresent(), p.Reasons)
+}
+
+func (p *Pitra) IsPresent() bool {
+	return len(p.Reasons) > 0
+}
+
+// CalculatePitra returns the Pitra dosha of d1
+func CalculatePitra(d1 *chart.Chart) (*Pitra, error) {
+	if err := checkChart(d1); err != nil {
+		return nil, err
+	}
+	ret := &Pitra{Reasons: []string{}}
+	signOf := func(pid pointid.PointID) sign.Sign {
+		return d1.MustGetPoint(pid).ZodiacalPos.Sign
+	}
+	lord9, err := d1.GetHouseLordFor(house.House9, chart.HouseLordPlacement_Traditional)
+	if err != nil {
+		return nil, fmt.Errorf("while getting lord of the 9th house: %v", err)
+	}
+	for _, malefic := range []pointid.PointID{pointid.Rahu, pointid.Ketu, pointid.Saturn} {
+		if signOf(pointid.Sun) == signOf(malefic) {
+			ret.Reasons = append(ret.Reasons, fmt.Sprintf("sun joined by %s", malefic))
+		}
+		if d1.MustGetPoint(malefic).House == house.House9 {
+			ret.Reasons = append(ret.Reasons, fmt.Sprintf("%s in the 9th house", malefic))
+		}
+		if lord9 != pointid.Sun && lord9 != malefic && signOf(lord9) == signOf(malefic) {
+			ret.Reasons = append(ret.Reasons, fmt.Sprintf(
+				"lord of the 9th house (%s) joined by %s",
+				lord9,
+				malefic,
+			))
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,167 @@
+package dosha
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func castD1(t *testing.T, swe *wrapper.SwissEph, date time.Time, lon, lat float64) *chart.Chart {
+	d1, err := chart.NewChartFromUTC(swe, date, lon, lat, chart.D1ChartType, pointid.VedicPlanets)
+	require.NoError(t, err)
+	return d1
+}
+
+func TestCalculateManglik(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+
+	// Virgo lagna, Mars in Sagittarius, the Moon in Leo and Venus in Scorpio
+	d1 := castD1(t, swe, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), -0.1278, 51.5074)
+	m, err := CalculateManglik(d1)
+	require.NoError(t, err)
+	require.Equal(t, []ManglikReference{
+		{From: pointid.ASC, House: house.House4, IsManglik: true},
+		{From: pointid.Moon, House: house.House5, IsManglik: false},
+		{From: pointid.Venus, House: house.House2, IsManglik: true},
+	}, m.References)
+	require.Equal(t, 2, m.Count())
+	// Jupiter in Aries aspects Mars with its 9th house drishti
+	require.Equal(t, []string{"mars joined or aspected by jupiter"}, m.Cancellations)
+	require.True(t, m.IsCancelled())
+	require.False(t, m.IsPresent())
+
+	// Only D1 charts are supported
+	d9, err := chart.NewChartFromUTC(
+		swe,
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		-0.1278, 51.5074,
+		chart.D9ChartType,
+		pointid.VedicPlanets,
+	)
+	require.NoError(t, err)
+	_, err = CalculateManglik(d9)
+	require.Error(t, err)
+}
+
+func TestCalculateKaalSarp(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+
+	d1 := castD1(t, swe, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), -0.1278, 51.5074)
+	ks, err := CalculateKaalSarp(d1)
+	require.NoError(t, err)
+	require.False(t, ks.IsPresent)
+	require.False(t, ks.IsPartial)
+	// Rahu in Pisces is in the 7th house of a Virgo lagna
+	require.Equal(t, KaalSarpType_Takshak, ks.Type)
+	require.Equal(t, house.House7, ks.RahuHouse)
+	require.Equal(t, pointid.Ketu, ks.From)
+	require.Equal(t, []pointid.PointID{pointid.Moon, pointid.Jupiter}, ks.Outside)
+
+	// In mid-March 2000, every planet was between Ketu and Rahu
+	d1 = castD1(t, swe, time.Date(2000, 3, 16, 0, 0, 0, 0, time.UTC), -0.1278, 51.5074)
+	ks, err = CalculateKaalSarp(d1)
+	require.NoError(t, err)
+	require.True(t, ks.IsPresent)
+	require.Empty(t, ks.Outside)
+	require.Equal(t, KaalSarpType_Shankhchud, ks.Type)
+	require.Equal(t, house.House9, ks.RahuHouse)
+	require.Equal(t, pointid.Ketu, ks.From)
+}
+
+func TestCalculatePitra(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+
+	d1 := castD1(t, swe, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), -0.1278, 51.5074)
+	p, err := CalculatePitra(d1)
+	require.NoError(t, err)
+	require.False(t, p.IsPresent())
+
+	// Rahu in the 9th house, with the Moon, its lord
+	d1 = castD1(t, swe, time.Date(2000, 3, 16, 0, 0, 0, 0, time.UTC), -0.1278, 51.5074)
+	p, err = CalculatePitra(d1)
+	require.NoError(t, err)
+	require.True(t, p.IsPresent())
+	require.Equal(t, []string{
+		"rahu in the 9th house",
+		"lord of the 9th house (moon) joined by rahu",
+	}, p.Reasons)
+}
+
+func TestSaturnTransits(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+
+	// Scorpio Moon
+	d1 := castD1(t, swe, time.Date(1992, 6, 13, 4, 40, 0, 0, time.UTC), 36.3, 33.5)
+	require.Equal(t, sign.Scorpio, d1.MustGetPoint(pointid.Moon).ZodiacalPos.Sign)
+	periods, err := CalculateSadeSati(
+		swe,
+		d1,
+		time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+	)
+	require.NoError(t, err)
+	require.Len(t, periods, 3)
+
+	// The end of Sade Sati, which started before the range
+	require.Equal(t, SaturnPhase_SadeSatiSetting, periods[0].Phase)
+	require.True(t, periods[0].Phase.IsSadeSati())
+	require.Equal(t, sign.Sagittarius, periods[0].Sign)
+	require.Equal(t, time.Date(2017, 10, 26, 9, 58, 0, 0, time.UTC), periods[0].Interval.Start.Time)
+	require.Equal(t, time.Date(2020, 1, 24, 4, 27, 0, 0, time.UTC), periods[0].Interval.End.Time)
+
+	// Saturn enters Aquarius, retrogrades back into Capricorn, then comes
+	// back
+	for _, p := range periods[1:] {
+		require.Equal(t, SaturnPhase_DhaiyaKantaka, p.Phase)
+		require.True(t, p.Phase.IsDhaiya())
+		require.Equal(t, sign.Aquarius, p.Sign)
+	}
+	require.Equal(t, time.Date(2022, 4, 29, 2, 24, 0, 0, time.UTC), periods[1].Interval.Start.Time)
+	require.Equal(t, time.Date(2022, 7, 12, 9, 18, 0, 0, time.UTC), periods[1].Interval.End.Time)
+	require.Equal(t, time.Date(2023, 1, 17, 12, 34, 0, 0, time.UTC), periods[2].Interval.Start.Time)
+	require.Equal(t, time.Date(2025, 3, 29, 16, 15, 0, 0, time.UTC), periods[2].Interval.End.Time)
+
+	// Fagan/Bradley is about a degree more than Lahiri, so in a chart cast
+	// with it, Saturn leaves Sagittarius a week later
+	cfg := chart.NewDefaultConfig()
+	cfg.Ayanamsa = wrapper.Ayanamsa_FaganBradley
+	fagan, err := chart.NewChartFromUTCWithConfig(
+		swe,
+		d1.Time.Time,
+		36.3, 33.5,
+		chart.D1ChartType,
+		pointid.VedicPlanets,
+		cfg,
+	)
+	require.NoError(t, err)
+	require.Equal(t, sign.Scorpio, fagan.MustGetPoint(pointid.Moon).ZodiacalPos.Sign)
+	periods, err = CalculateSadeSati(
+		swe,
+		fagan,
+		time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC),
+	)
+	require.NoError(t, err)
+	require.Len(t, periods, 1)
+	require.Equal(t, sign.Sagittarius, periods[0].Sign)
+	require.Equal(t, time.Date(2020, 1, 31, 18, 21, 0, 0, time.UTC), periods[0].Interval.End.Time)
+
+	_, err = SaturnTransits(
+		swe,
+		sign.Scorpio,
+		wrapper.DefaultAyanamsa,
+		time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	)
+	require.Error(t, err)
+}
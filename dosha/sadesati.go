@@ -0,0 +1,225 @@
+package dosha
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+)
+
+// SaturnPhase is a phase of Saturn's transit relative to the natal Moon sign
+type SaturnPhase string
+
+const (
+	// The 7.5 years of Sade Sati: Saturn in the 12th, 1st and 2nd signs from
+	// the natal Moon
+	SaturnPhase_SadeSatiRising  SaturnPhase = "sade-sati-rising"
+	SaturnPhase_SadeSatiPeak    SaturnPhase = "sade-sati-peak"
+	SaturnPhase_SadeSatiSetting SaturnPhase = "sade-sati-setting"
+	// The 2.5 years of Dhaiya (or small panoti): Saturn in the 4th (Kantaka
+	// Shani) or the 8th (Ashtama Shani) sign from the natal Moon
+	SaturnPhase_DhaiyaKantaka SaturnPhase = "dhaiya-kantaka"
+	SaturnPhase_DhaiyaAshtama SaturnPhase = "dhaiya-ashtama"
+)
+
+func (sp SaturnPhase) String() string {
+	return string(sp)
+}
+
+func (sp SaturnPhase) IsSadeSati() bool {
+	switch sp {
+	case SaturnPhase_SadeSatiRising,
+		SaturnPhase_SadeSatiPeak,
+		SaturnPhase_SadeSatiSetting:
+		return true
+	}
+	return false
+}
+
+func (sp SaturnPhase) IsDhaiya() bool {
+	return sp == SaturnPhase_DhaiyaKantaka || sp == SaturnPhase_DhaiyaAshtama
+}
+
+// saturnPhases are the phases by the house of Saturn from the natal Moon
+var saturnPhases = map[int]SaturnPhase{
+	12: SaturnPhase_SadeSatiRising,
+	1:  SaturnPhase_SadeSatiPeak,
+	2:  SaturnPhase_SadeSatiSetting,
+	4:  SaturnPhase_DhaiyaKantaka,
+	8:  SaturnPhase_DhaiyaAshtama,
+}
+
+// SaturnTransitPeriod is a stay of Saturn in a sign that's a Sade Sati or
+// Dhaiya phase
+type SaturnTransitPeriod struct {
+	Phase SaturnPhase `json:"phase"`
+	// Sign is the sidereal sign Saturn is transiting
+	Sign sign.Sign `json:"sign"`
+	// Interval is from Saturn's ingress into Sign to its egress, even if they
+	// fall outside of the requested range
+	Interval chart.Interval `json:"interval"`
+}
+
+func (stp *SaturnTransitPeriod) String() string {
+	return fmt.Sprintf(
+		"SaturnTransitPeriod{Phase: %s, Sign: %s, Interval: %s}",
+		stp.Phase,
+		stp.Sign,
+		stp.Interval,
+	)
+}
+
+// saturnIngressPrecision is how close (in days) ingresses are searched for:
+// about a minute
+const saturnIngressPrecision = 1.0 / 24 / 60
+
+// maxSaturnStayDays bounds the search for the ingress or egress of a stay
+// that overlaps the edges of the range. Saturn stays in a sign for about
+// 2.5 years, a bit more with its retrogrades.
+const maxSaturnStayDays = 4 * 365
+
+// SaturnTransits returns Saturn's Sade Sati and Dhaiya periods relative to
+// moonSign (the natal Moon sign, in the ayanamsa a) that overlap start-end,
+// in order. Saturn re-entering a sign because of a retrograde starts a new
+// period.
+func SaturnTransits(
+	swe *wrapper.SwissEph,
+	moonSign sign.Sign,
+	a wrapper.Ayanamsa,
+	start, end time.Time,
+) ([]*SaturnTransitPeriod, error) {
+	if !start.Before(end) {
+		return nil, fmt.Errorf("start (%s) is not before end (%s)", start, end)
+	}
+	saturnSign := func(jd float64) (sign.Sign, error) {
+		lon, _, err := swe.CalcUTFor(jd, pointid.Saturn.SwissEphID(), a)
+		if err != nil {
+			return "", fmt.Errorf("while calculating saturn: %v", err)
+		}
+		return sign.DegreeToSign(lon), nil
+	}
+	// findChange returns when Saturn leaves the sign it has at a, knowing it
+	// has a different one at b. a can be after b.
+	findChange := func(a, b float64) (float64, error) {
+		sa, err := saturnSign(a)
+		if err != nil {
+			return 0, err
+		}
+		for b-a > saturnIngressPrecision || a-b > saturnIngressPrecision {
+			mid := (a + b) / 2
+			s, err := saturnSign(mid)
+			if err != nil {
+				return 0, err
+			}
+			if s == sa {
+				a = mid
+			} else {
+				b = mid
+			}
+		}
+		return b, nil
+	}
+	// findEdge steps a day at a time from jd in the direction of dir until
+	// Saturn leaves the sign it has at jd
+	findEdge := func(jd float64, dir float64) (float64, error) {
+		s, err := saturnSign(jd)
+		if err != nil {
+			return 0, err
+		}
+		for i := 0; i < maxSaturnStayDays; i++ {
+			next := jd + dir
+			ns, err := saturnSign(next)
+			if err != nil {
+				return 0, err
+			}
+			if ns != s {
+				return findChange(jd, next)
+			}
+			jd = next
+		}
+		return 0, fmt.Errorf("saturn did not leave %s in %d days", s, maxSaturnStayDays)
+	}
+
+	// Split start-end into Saturn's stays in each sign
+	type stay struct {
+		s          sign.Sign
+		start, end float64
+	}
+	startJD := swe.GoTimeToJulianDay(start.UTC())
+	endJD := swe.GoTimeToJulianDay(end.UTC())
+	stays := []*stay{}
+	curr, err := saturnSign(startJD)
+	if err != nil {
+		return nil, err
+	}
+	stays = append(stays, &stay{s: curr, start: startJD})
+	for jd := startJD; jd < endJD; jd++ {
+		next := min(jd+1, endJD)
+		s, err := saturnSign(next)
+		if err != nil {
+			return nil, err
+		}
+		if s == curr {
+			continue
+		}
+		change, err := findChange(jd, next)
+		if err != nil {
+			return nil, err
+		}
+		stays[len(stays)-1].end = change
+		stays = append(stays, &stay{s: s, start: change})
+		curr = s
+	}
+
+	ret := []*SaturnTransitPeriod{}
+	for i, st := range stays {
+		phase, ok := saturnPhases[house.NewHouseFromSign(st.s, moonSign).Int()]
+		if !ok {
+			continue
+		}
+		// The first and last stays are cut by the range: find their real
+		// edges
+		if i == 0 {
+			if st.start, err = findEdge(startJD, -1); err != nil {
+				return nil, fmt.Errorf("while finding ingress into %s: %v", st.s, err)
+			}
+		}
+		if i == len(stays)-1 {
+			if st.end, err = findEdge(endJD, 1); err != nil {
+				return nil, fmt.Errorf("while finding egress from %s: %v", st.s, err)
+			}
+		}
+		ret = append(ret, &SaturnTransitPeriod{
+			Phase: phase,
+			Sign:  st.s,
+			Interval: chart.NewInterval(
+				swe.JulianDayToGoTime(st.start),
+				swe.JulianDayToGoTime(st.end),
+			),
+		})
+	}
+	return ret, nil
+}
+
+// CalculateSadeSati is SaturnTransits for the natal Moon sign of d1, in its
+// ayanamsa
+func CalculateSadeSati(
+	swe *wrapper.SwissEph,
+	d1 *chart.Chart,
+	start, end time.Time,
+) ([]*SaturnTransitPeriod, error) {
+	if err := checkChart(d1); err != nil {
+		return nil, err
+	}
+	return SaturnTransits(
+		swe,
+		d1.MustGetPoint(pointid.Moon).ZodiacalPos.Sign,
+		d1.Ayanamsa,
+		start,
+		end,
+	)
+}
@@ -0,0 +1,420 @@
+// Package kp implements Krishnamurti Paddhati (KP): the 249 sub divisions of
+// the zodiac, the star, sub and sub-sub lords of planets and Placidus cusps,
+// the four levels of house significators, and ruling planets.
+package kp
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/panchanga"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+)
+
+// TotalSubDivisions is the number of KP subs: 27 nakshatras of 9 subs, with
+// the subs that cross a sign split in two
+const TotalSubDivisions = 249
+
+// NewConfig returns the config KP charts are cast with: the KP
+// (Krishnamurti) ayanamsa and Placidus cusps
+func NewConfig() *chart.Config {
+	cfg := chart.NewDefaultConfig()
+	cfg.Ayanamsa = wrapper.Ayanamsa_Krishnamurti
+	cfg.HouseSystem = wrapper.HouseSystem_Placidus
+	return cfg
+}
+
+// NewChart casts the D1 chart KP works on, at t and lon/lat
+func NewChart(
+	swe *wrapper.SwissEph,
+	t time.Time,
+	lon, lat float64,
+) (*chart.Chart, error) {
+	return chart.NewChartFromUTCWithConfig(
+		swe,
+		t,
+		lon, lat,
+		chart.D1ChartType,
+		pointid.VedicPlanets,
+		NewConfig(),
+	)
+}
+
+// subSpan returns the length of the sub of lord, in degrees: the nakshatra
+// divided in proportion to the Vimshottari years
+func subSpan(span float64, lord chart.DashaLord) float64 {
+	system := chart.DashaSystem_Vimshottari
+	return span * system.Years(lord) / system.TotalYears()
+}
+
+// divide returns the lord of the part of a span starting with lord that
+// offset (from 0 to span) is in, with the start and span of the part
+func divide(
+	lord chart.DashaLord,
+	span, offset float64,
+) (chart.DashaLord, float64, float64) {
+	start := 0.0
+	for i := 0; i < chart.TotalDashaLords-1; i++ {
+		s := subSpan(span, lord)
+		if offset < start+s {
+			return lord, start, s
+		}
+		start += s
+		lord = chart.DashaSystem_Vimshottari.Next(lord)
+	}
+	return lord, start, span - start
+}
+
+// Lords are the KP lords of a longitude
+type Lords struct {
+	// Longitude is sidereal, with the KP ayanamsa
+	Longitude  float64         `json:"longitude"`
+	SignLord   pointid.PointID `json:"signLord"`
+	StarLord   pointid.PointID `json:"starLord"`
+	SubLord    pointid.PointID `json:"subLord"`
+	SubSubLord pointid.PointID `json:"subSubLord"`
+}
+
+func (l *Lords) String() string {
+	return fmt.Sprintf(
+		"Lords{Longitude: %f, SignLord: %s, StarLord: %s, SubLord: %s, SubSubLord: %s}",
+		l.Longitude,
+		l.SignLord,
+		l.StarLord,
+		l.SubLord,
+		l.SubSubLord,
+	)
+}
+
+// NewLords returns the lords of the sidereal longitude lon
+func NewLords(lon float64) *Lords {
+	lon = math.Mod(lon+360, 360)
+	nakIdx := int(lon / chart.NakshatraSpan)
+	nak, _ := chart.NewNakshatraTypeFromInt(nakIdx)
+	offset := lon - float64(nakIdx)*chart.NakshatraSpan
+	sub, subStart, span := divide(nak.Lord(), chart.NakshatraSpan, offset)
+	subSub, _, _ := divide(sub, span, offset-subStart)
+	return &Lords{
+		Longitude:  lon,
+		SignLord:   sign.DegreeToSign(lon).TraditionalRuler(),
+		StarLord:   nak.Lord().ToPointID(),
+		SubLord:    sub.ToPointID(),
+		SubSubLord: subSub.ToPointID(),
+	}
+}
+
+// SubDivision is one of the 249 KP subs
+type SubDivision struct {
+	// Number is from 1 to TotalSubDivisions
+	Number    int                 `json:"number"`
+	Sign      sign.Sign           `json:"sign"`
+	Nakshatra chart.NakshatraType `json:"nakshatra"`
+	// From and To are sidereal longitudes, To excluded
+	From     float64         `json:"from"`
+	To       float64         `json:"to"`
+	SignLord pointid.PointID `json:"signLord"`
+	StarLord pointid.PointID `json:"starLord"`
+	SubLord  pointid.PointID `json:"subLord"`
+}
+
+func (sd SubDivision) String() string {
+	return fmt.Sprintf(
+		"SubDivision{Number: %d, Sign: %s, Nakshatra: %s, From: %f, To: %f, StarLord: %s, SubLord: %s}",
+		sd.Number,
+		sd.Sign,
+		sd.Nakshatra,
+		sd.From,
+		sd.To,
+		sd.StarLord,
+		sd.SubLord,
+	)
+}
+
+// subDivisions is the table of the 249 subs, in order
+var subDivisions = newSubDivisions()
+
+func newSubDivisions() []SubDivision {
+	// epsilon absorbs the rounding of the sub boundaries, which aren't
+	// round numbers
+	const epsilon = 1e-9
+	ret := []SubDivision{}
+	add := func(nak chart.NakshatraType, sub chart.DashaLord, from, to float64) {
+		s := sign.DegreeToSign(from + epsilon)
+		ret = append(ret, SubDivision{
+			Number:    len(ret) + 1,
+			Sign:      s,
+			Nakshatra: nak,
+			From:      from,
+			To:        to,
+			SignLord:  s.TraditionalRuler(),
+			StarLord:  nak.Lord().ToPointID(),
+			SubLord:   sub.ToPointID(),
+		})
+	}
+	for i := 0; i < 27; i++ {
+		nak, _ := chart.NewNakshatraTypeFromInt(i)
+		lord := nak.Lord()
+		from := float64(i) * chart.NakshatraSpan
+		for j := 0; j < chart.TotalDashaLords; j++ {
+			to := from + subSpan(chart.NakshatraSpan, lord)
+			if j == chart.TotalDashaLords-1 {
+				to = float64(i+1) * chart.NakshatraSpan
+			}
+			// Split the subs that cross into the next sign
+			signEnd := math.Floor((from+epsilon)/30)*30 + 30
+			if signEnd < to-epsilon {
+				add(nak, lord, from, signEnd)
+				add(nak, lord, signEnd, to)
+			} else {
+				add(nak, lord, from, to)
+			}
+			from = to
+			lord = chart.DashaSystem_Vimshottari.Next(lord)
+		}
+	}
+	return ret
+}
+
+// SubDivisions returns the 249 KP subs, in order
+func SubDivisions() []SubDivision {
+	return slices.Clone(subDivisions)
+}
+
+// GetSubDivision returns the sub the sidereal longitude lon is in
+func GetSubDivision(lon float64) SubDivision {
+	if lon < 0 || lon >= 360 {
+		// Only when needed: adding 360 would round lon
+		lon = math.Mod(math.Mod(lon, 360)+360, 360)
+	}
+	idx, _ := slices.BinarySearchFunc(subDivisions, lon, func(sd SubDivision, lon float64) int {
+		switch {
+		case sd.To <= lon:
+			return -1
+		case sd.From > lon:
+			return 1
+		}
+		return 0
+	})
+	return subDivisions[min(idx, len(subDivisions)-1)]
+}
+
+// PlanetLords are the KP lords of a planet
+type PlanetLords struct {
+	Planet pointid.PointID `json:"planet"`
+	// House is the house of Planet by the Placidus cusps
+	House house.House `json:"house"`
+	*Lords
+}
+
+// CuspLords are the KP lords of the cusp of a house
+type CuspLords struct {
+	House house.House `json:"house"`
+	*Lords
+}
+
+// Significators are the planets that signify a house, in four levels from
+// the strongest to the weakest
+type Significators struct {
+	House house.House `json:"house"`
+	// Level 1: the planets in the stars of the occupants of House
+	InStarOfOccupants []pointid.PointID `json:"inStarOfOccupants"`
+	// Level 2: the planets in House
+	Occupants []pointid.PointID `json:"occupants"`
+	// Level 3: the planets in the stars of Owner
+	InStarOfOwner []pointid.PointID `json:"inStarOfOwner"`
+	// Level 4: the lord of the sign of the cusp of House
+	Owner pointid.PointID `json:"owner"`
+}
+
+func (s *Significators) String() string {
+	return fmt.Sprintf(
+		"Significators{House: %s, InStarOfOccupants: %v, Occupants: %v, InStarOfOwner: %v, Owner: %s}",
+		s.House,
+		s.InStarOfOccupants,
+		s.Occupants,
+		s.InStarOfOwner,
+		s.Owner,
+	)
+}
+
+// All returns the significators of all levels, from the strongest, each
+// once
+func (s *Significators) All() []pointid.PointID {
+	ret := []pointid.PointID{}
+	for _, level := range [][]pointid.PointID{
+		s.InStarOfOccupants,
+		s.Occupants,
+		s.InStarOfOwner,
+		{s.Owner},
+	} {
+		for _, pid := range level {
+			if !slices.Contains(ret, pid) {
+				ret = append(ret, pid)
+			}
+		}
+	}
+	return ret
+}
+
+// Analysis is the KP analysis of a chart
+type Analysis struct {
+	Planets       []*PlanetLords   `json:"planets"`
+	Cusps         []*CuspLords     `json:"cusps"`
+	Significators []*Significators `json:"significators"`
+}
+
+// GetPlanet returns the lords of pid, or nil if it's not in the analysis
+func (a *Analysis) GetPlanet(pid pointid.PointID) *PlanetLords {
+	for _, p := range a.Planets {
+		if p.Planet == pid {
+			return p
+		}
+	}
+	return nil
+}
+
+// GetSignificators returns the significators of h
+func (a *Analysis) GetSignificators(h house.House) *Significators {
+	return a.Significators[h.Int()-1]
+}
+
+// Analyze returns the KP analysis of c. c has to be a D1 chart with cusps:
+// cast it with NewChart for the KP ayanamsa and Placidus cusps.
+//
+// XXX <19-10-2026, agent> KP also makes the nodes significators of what
+// their sign lords and the planets conjoined with them signify. We only
+// count them where they are and in whose star.
+func Analyze(c *chart.Chart) (*Analysis, error) {
+	if c.ChartType != chart.D1ChartType {
+		return nil, fmt.Errorf("KP needs a D1 chart, got %s", c.ChartType)
+	}
+	if len(c.Cusps) != 12 {
+		return nil, fmt.Errorf("KP needs a chart with cusps")
+	}
+	ret := &Analysis{
+		Planets:       []*PlanetLords{},
+		Cusps:         []*CuspLords{},
+		Significators: []*Significators{},
+	}
+	for _, pid := range pointid.VedicPlanets {
+		p := c.GetPoint(pid)
+		if p == nil {
+			continue
+		}
+		h, err := chart.HouseFromCusps(c.Cusps, p.Longitude)
+		if err != nil {
+			return nil, err
+		}
+		ret.Planets = append(ret.Planets, &PlanetLords{
+			Planet: pid,
+			House:  h,
+			Lords:  NewLords(p.Longitude),
+		})
+	}
+	inStarOf := func(lord pointid.PointID) []pointid.PointID {
+		planets := []pointid.PointID{}
+		for _, p := range ret.Planets {
+			if p.StarLord == lord {
+				planets = append(planets, p.Planet)
+			}
+		}
+		return planets
+	}
+	for i, cusp := range c.Cusps {
+		h, _ := house.HouseFromInt(i + 1)
+		lords := NewLords(cusp)
+		ret.Cusps = append(ret.Cusps, &CuspLords{House: h, Lords: lords})
+		s := &Significators{
+			House:             h,
+			InStarOfOccupants: []pointid.PointID{},
+			Occupants:         []pointid.PointID{},
+			InStarOfOwner:     inStarOf(lords.SignLord),
+			Owner:             lords.SignLord,
+		}
+		for _, p := range ret.Planets {
+			if p.House == h {
+				s.Occupants = append(s.Occupants, p.Planet)
+			}
+		}
+		for _, occupant := range s.Occupants {
+			for _, pid := range inStarOf(occupant) {
+				if !slices.Contains(s.InStarOfOccupants, pid) {
+					s.InStarOfOccupants = append(s.InStarOfOccupants, pid)
+				}
+			}
+		}
+		ret.Significators = append(ret.Significators, s)
+	}
+	return ret, nil
+}
+
+// RulingPlanets are the planets ruling a moment, at a place. KP uses them to
+// answer questions and to rectify birth times.
+type RulingPlanets struct {
+	// DayLord is the lord of the weekday, which starts at sunrise
+	DayLord       pointid.PointID `json:"dayLord"`
+	LagnaSignLord pointid.PointID `json:"lagnaSignLord"`
+	LagnaStarLord pointid.PointID `json:"lagnaStarLord"`
+	LagnaSubLord  pointid.PointID `json:"lagnaSubLord"`
+	MoonSignLord  pointid.PointID `json:"moonSignLord"`
+	MoonStarLord  pointid.PointID `json:"moonStarLord"`
+	MoonSubLord   pointid.PointID `json:"moonSubLord"`
+}
+
+func (rp *RulingPlanets) String() string {
+	return fmt.Sprintf("RulingPlanets%v", rp.Planets())
+}
+
+// Planets returns the ruling planets, each once, from the strongest: the
+// lords of the ascendant's star and sign, of the Moon's star and sign, and
+// the day lord, then the sub lords
+func (rp *RulingPlanets) Planets() []pointid.PointID {
+	ret := []pointid.PointID{}
+	for _, pid := range []pointid.PointID{
+		rp.LagnaStarLord,
+		rp.LagnaSignLord,
+		rp.MoonStarLord,
+		rp.MoonSignLord,
+		rp.DayLord,
+		rp.LagnaSubLord,
+		rp.MoonSubLord,
+	} {
+		if !slices.Contains(ret, pid) {
+			ret = append(ret, pid)
+		}
+	}
+	return ret
+}
+
+// CalculateRulingPlanets returns the ruling planets at t, at lon/lat
+func CalculateRulingPlanets(
+	swe *wrapper.SwissEph,
+	t time.Time,
+	lon, lat float64,
+) (*RulingPlanets, error) {
+	c, err := NewChart(swe, t, lon, lat)
+	if err != nil {
+		return nil, fmt.Errorf("while casting chart: %v", err)
+	}
+	pan, err := panchanga.Calculate(swe, t, lon, lat)
+	if err != nil {
+		return nil, fmt.Errorf("while calculating panchanga: %v", err)
+	}
+	asc := NewLords(c.MustGetPoint(pointid.ASC).Longitude)
+	moon := NewLords(c.MustGetPoint(pointid.Moon).Longitude)
+	return &RulingPlanets{
+		DayLord:       pan.Vara.Lord,
+		LagnaSignLord: asc.SignLord,
+		LagnaStarLord: asc.StarLord,
+		LagnaSubLord:  asc.SubLord,
+		MoonSignLord:  moon.SignLord,
+		MoonStarLord:  moon.StarLord,
+		MoonSubLord:   moon.SubLord,
+	}, nil
+}
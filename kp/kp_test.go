@@ -0,0 +1,121 @@
+package kp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubDivisions(t *testing.T) {
+	sds := SubDivisions()
+	require.Len(t, sds, TotalSubDivisions)
+	require.Equal(t, 0.0, sds[0].From)
+	require.Equal(t, 360.0, sds[len(sds)-1].To)
+	for i, sd := range sds {
+		require.Equal(t, i+1, sd.Number)
+		require.Less(t, sd.From, sd.To)
+		if i > 0 {
+			require.Equal(t, sds[i-1].To, sd.From)
+		}
+		require.Equal(t, sd, GetSubDivision(sd.From))
+	}
+	// The Venus sub of Ketu's Aswini goes from 0°46'40" to 3°
+	require.Equal(t, pointid.Ketu, sds[1].StarLord)
+	require.Equal(t, pointid.Venus, sds[1].SubLord)
+	require.InDelta(t, 46.0/60+40.0/3600, sds[1].From, 1e-9)
+	require.InDelta(t, 3, sds[1].To, 1e-9)
+	// Krittika's Rahu sub crosses from Aries into Taurus
+	sd := GetSubDivision(29.9)
+	require.Equal(t, sign.Aries, sd.Sign)
+	require.Equal(t, 30.0, sd.To)
+	next := GetSubDivision(30)
+	require.Equal(t, sign.Taurus, next.Sign)
+	require.Equal(t, sd.SubLord, next.SubLord)
+	require.Equal(t, pointid.Rahu, next.SubLord)
+
+	lords := NewLords(2)
+	require.Equal(t, pointid.Mars, lords.SignLord)
+	require.Equal(t, pointid.Ketu, lords.StarLord)
+	require.Equal(t, pointid.Venus, lords.SubLord)
+	// The Venus sub is divided again, from Venus: Jupiter's part is from
+	// 1°54'27" to 2°12'13"
+	require.Equal(t, pointid.Jupiter, lords.SubSubLord)
+}
+
+func TestAnalyze(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// London coordinates
+	lon, lat := -0.1278, 51.5074
+	c, err := NewChart(swe, date, lon, lat)
+	require.NoError(t, err)
+	// The KP ayanamsa is about 6' more than Lahiri's
+	lahiri, err := chart.NewChartFromUTC(swe, date, lon, lat, chart.D1ChartType, pointid.VedicPlanets)
+	require.NoError(t, err)
+	require.InDelta(t, -0.097,
+		lahiri.MustGetPoint(pointid.Sun).Longitude-c.MustGetPoint(pointid.Sun).Longitude,
+		0.001,
+	)
+	require.Equal(t, wrapper.Ayanamsa_Krishnamurti, c.Ayanamsa)
+	require.Equal(t, wrapper.Ayanamsa_Lahiri, lahiri.Ayanamsa)
+	// Placidus cusps start at the ascendant, unlike whole sign ones
+	require.Len(t, c.Cusps, 12)
+	require.InDelta(t, c.MustGetPoint(pointid.ASC).Longitude, c.Cusps[0], 1e-9)
+	require.Equal(t, 150.0, lahiri.Cusps[0])
+
+	a, err := Analyze(c)
+	require.NoError(t, err)
+	require.Len(t, a.Planets, len(pointid.VedicPlanets))
+	require.Len(t, a.Cusps, 12)
+	// The Moon is in Leo 11°54', in Magha (Ketu)
+	moon := a.GetPlanet(pointid.Moon)
+	require.Equal(t, house.House11, moon.House)
+	require.Equal(t, pointid.Sun, moon.SignLord)
+	require.Equal(t, pointid.Ketu, moon.StarLord)
+	require.Equal(t, pointid.Mercury, moon.SubLord)
+
+	// Ketu is in the 1st, and the Moon, Mars and Jupiter in its star
+	s := a.GetSignificators(house.House1)
+	require.Equal(t, []pointid.PointID{pointid.Moon, pointid.Mars, pointid.Jupiter}, s.InStarOfOccupants)
+	require.Equal(t, []pointid.PointID{pointid.Ketu}, s.Occupants)
+	require.Equal(t, []pointid.PointID{pointid.Mercury, pointid.Rahu}, s.InStarOfOwner)
+	require.Equal(t, pointid.Mercury, s.Owner)
+	require.Equal(t,
+		[]pointid.PointID{pointid.Moon, pointid.Mars, pointid.Jupiter, pointid.Ketu, pointid.Mercury, pointid.Rahu},
+		s.All(),
+	)
+
+	_, err = Analyze(lahiri)
+	require.NoError(t, err)
+	d9, err := chart.NewChartFromUTC(swe, date, lon, lat, chart.D9ChartType, pointid.VedicPlanets)
+	require.NoError(t, err)
+	_, err = Analyze(d9)
+	require.Error(t, err)
+}
+
+func TestCalculateRulingPlanets(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+	// Before sunrise, so it's still Sunday
+	rp, err := CalculateRulingPlanets(
+		swe,
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		-0.1278, 51.5074,
+	)
+	require.NoError(t, err)
+	require.Equal(t, pointid.Sun, rp.DayLord)
+	require.Equal(t, pointid.Mercury, rp.LagnaSignLord)
+	require.Equal(t, pointid.Moon, rp.LagnaStarLord)
+	require.Equal(t, pointid.Ketu, rp.MoonStarLord)
+	require.Equal(t,
+		[]pointid.PointID{pointid.Moon, pointid.Mercury, pointid.Ketu, pointid.Sun, pointid.Rahu},
+		rp.Planets(),
+	)
+}
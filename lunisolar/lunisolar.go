@@ -0,0 +1,367 @@
+// Package lunisolar converts Gregorian dates to the Hindu lunisolar calendar
+// and back.
+//
+// Lunar months are amanta (from new moon to new moon) and named after the
+// sign the sidereal Sun is in when they start: a month that starts with the
+// Sun in Pisces is Chaitra, since the Sun enters Aries (Mesha sankranti)
+// during it. A month without a sankranti is an adhika (extra) month and takes
+// the name of the month after it. Purnimanta months (from full moon to full
+// moon) start a fortnight earlier, so their Krishna paksha is named after the
+// next amanta month.
+//
+// Years are counted from Chaitra Shukla Pratipada in the Vikram and Shaka
+// eras.
+package lunisolar
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/panchanga"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+)
+
+const (
+	// SynodicMonth is the average time between two new moons, in days
+	SynodicMonth = 29.530588853
+
+	// VikramSamvatOffset and ShakaSamvatOffset are the differences between
+	// the era years and the Gregorian year in which they start
+	VikramSamvatOffset = 57
+	ShakaSamvatOffset  = -78
+)
+
+// Masa is a lunar month, from 1 (Chaitra) to 12 (Phalguna)
+type Masa int
+
+const (
+	Masa_Chaitra Masa = iota + 1
+	Masa_Vaishakha
+	Masa_Jyeshtha
+	Masa_Ashadha
+	Masa_Shravana
+	Masa_Bhadrapada
+	Masa_Ashwin
+	Masa_Kartika
+	Masa_Margashirsha
+	Masa_Pausha
+	Masa_Magha
+	Masa_Phalguna
+)
+
+func (m Masa) String() string {
+	switch m {
+	case Masa_Chaitra:
+		return "Chaitra"
+	case Masa_Vaishakha:
+		return "Vaishakha"
+	case Masa_Jyeshtha:
+		return "Jyeshtha"
+	case Masa_Ashadha:
+		return "Ashadha"
+	case Masa_Shravana:
+		return "Shravana"
+	case Masa_Bhadrapada:
+		return "Bhadrapada"
+	case Masa_Ashwin:
+		return "Ashwin"
+	case Masa_Kartika:
+		return "Kartika"
+	case Masa_Margashirsha:
+		return "Margashirsha"
+	case Masa_Pausha:
+		return "Pausha"
+	case Masa_Magha:
+		return "Magha"
+	case Masa_Phalguna:
+		return "Phalguna"
+	}
+	return "Unknown"
+}
+
+// Next returns the month after m
+func (m Masa) Next() Masa {
+	return Masa(int(m)%12 + 1)
+}
+
+// MasaForSunSign returns the amanta month that starts with the sidereal Sun
+// in s
+func MasaForSunSign(s sign.Sign) Masa {
+	return Masa(s.Int()%12 + 1)
+}
+
+// SolarMonth is the time the sidereal Sun spends in a sign, from one
+// sankranti to the next
+type SolarMonth struct {
+	Sign     sign.Sign      `json:"sign"`
+	Name     string         `json:"name"`
+	Interval chart.Interval `json:"interval"`
+}
+
+func (sm SolarMonth) String() string {
+	return sm.Name
+}
+
+// SolarMonthName returns the Sanskrit name of the solar month of s
+func SolarMonthName(s sign.Sign) string {
+	return []string{
+		"Mesha",
+		"Vrishabha",
+		"Mithuna",
+		"Karka",
+		"Simha",
+		"Kanya",
+		"Tula",
+		"Vrishchika",
+		"Dhanu",
+		"Makara",
+		"Kumbha",
+		"Meena",
+	}[s.Int()-1]
+}
+
+// Date is a moment in the Hindu lunisolar calendar
+type Date struct {
+	// Time is the moment that was converted (see At and FromGregorian)
+	Time  time.Time       `json:"time"`
+	Tithi panchanga.Tithi `json:"tithi"`
+	// AmantaMonth and PurnimantaMonth are the names of the lunar month in
+	// the two conventions. They only differ in Krishna paksha.
+	AmantaMonth     Masa `json:"amantaMonth"`
+	PurnimantaMonth Masa `json:"purnimantaMonth"`
+	// IsAdhika is true if the (amanta) month has no sankranti
+	IsAdhika bool `json:"isAdhika"`
+	// LunarMonth is the span of the amanta month
+	LunarMonth   chart.Interval `json:"lunarMonth"`
+	SolarMonth   SolarMonth     `json:"solarMonth"`
+	VikramSamvat int            `json:"vikramSamvat"`
+	ShakaSamvat  int            `json:"shakaSamvat"`
+}
+
+func (d *Date) String() string {
+	adhika := ""
+	if d.IsAdhika {
+		adhika = "Adhika "
+	}
+	return fmt.Sprintf(
+		"%s%s %s, Vikram Samvat %d",
+		adhika,
+		d.AmantaMonth,
+		d.Tithi,
+		d.VikramSamvat,
+	)
+}
+
+// siderealSunSign returns the sign of the sidereal Sun at t
+func siderealSunSign(swe *wrapper.SwissEph, t time.Time) (sign.Sign, error) {
+	lon, _, err := swe.CalcUT(
+		swe.GoTimeToJulianDay(t.UTC()),
+		pointid.Sun.SwissEphID(),
+		true,
+	)
+	if err != nil {
+		return "", fmt.Errorf("while calculating the Sun: %v", err)
+	}
+	return sign.DegreeToSign(lon), nil
+}
+
+// At converts the moment t to the lunisolar calendar. The lunar calendar
+// doesn't depend on the location, except through the date: see FromGregorian.
+func At(swe *wrapper.SwissEph, t time.Time) (*Date, error) {
+	tithi, err := panchanga.CalculateTithi(swe, t)
+	if err != nil {
+		return nil, err
+	}
+	monthStart, err := panchanga.FindElongation(swe, t, 0, false)
+	if err != nil {
+		return nil, fmt.Errorf("while finding start of lunar month: %v", err)
+	}
+	monthEnd, err := panchanga.FindElongation(swe, t, 0, true)
+	if err != nil {
+		return nil, fmt.Errorf("while finding end of lunar month: %v", err)
+	}
+	startSign, err := siderealSunSign(swe, monthStart)
+	if err != nil {
+		return nil, err
+	}
+	endSign, err := siderealSunSign(swe, monthEnd)
+	if err != nil {
+		return nil, err
+	}
+	amanta := MasaForSunSign(startSign)
+	purnimanta := amanta
+	if tithi.Paksha == panchanga.Paksha_Krishna {
+		purnimanta = amanta.Next()
+	}
+	// XXX <19-10-2026, agent> A month with two sankrantis (kshaya masa) is
+	// extremely rare (the last one was in 1983) and isn't detected: it's
+	// named after its first sankranti
+	isAdhika := startSign == endSign
+
+	sunSign, err := siderealSunSign(swe, t)
+	if err != nil {
+		return nil, err
+	}
+	sankranti, err := panchanga.FindSiderealSunLongitude(
+		swe, t, float64(sunSign.Int()-1)*30, false)
+	if err != nil {
+		return nil, fmt.Errorf("while finding sankranti: %v", err)
+	}
+	nextSankranti, err := panchanga.FindSiderealSunLongitude(
+		swe, t, math.Mod(float64(sunSign.Int())*30, 360), true)
+	if err != nil {
+		return nil, fmt.Errorf("while finding next sankranti: %v", err)
+	}
+
+	// Walk back to (roughly) the start of Chaitra to find the Gregorian year
+	// the lunar year started in. Adhika months make this off by a month,
+	// which is fine since Chaitra starts between March and April.
+	yearStart := monthStart.Add(
+		-time.Duration(float64(amanta-1) * SynodicMonth * float64(24*time.Hour)),
+	)
+	// Chaitra starts a fortnight or so before the Mesha sankranti: nudge
+	// it forward so it's not counted in the previous year when it's early
+	yearStart = yearStart.Add(15 * 24 * time.Hour)
+	return &Date{
+		Time:            t,
+		Tithi:           tithi,
+		AmantaMonth:     amanta,
+		PurnimantaMonth: purnimanta,
+		IsAdhika:        isAdhika,
+		LunarMonth:      chart.NewInterval(monthStart, monthEnd),
+		SolarMonth: SolarMonth{
+			Sign:     sunSign,
+			Name:     SolarMonthName(sunSign),
+			Interval: chart.NewInterval(sankranti, nextSankranti),
+		},
+		VikramSamvat: yearStart.Year() + VikramSamvatOffset,
+		ShakaSamvat:  yearStart.Year() + ShakaSamvatOffset,
+	}, nil
+}
+
+// FromGregorian converts the civil date of date (its year, month and day) at
+// lon/lat to the lunisolar calendar. Like the almanacs, it uses the tithi
+// running at sunrise on that date.
+func FromGregorian(
+	swe *wrapper.SwissEph,
+	date time.Time,
+	lon, lat float64,
+) (*Date, error) {
+	sunrise, err := sunriseOn(swe, date, lon, lat)
+	if err != nil {
+		return nil, err
+	}
+	return At(swe, sunrise)
+}
+
+// sunriseOn returns the sunrise on the civil date of date at lon/lat, using
+// the local mean time of lon
+func sunriseOn(
+	swe *wrapper.SwissEph,
+	date time.Time,
+	lon, lat float64,
+) (time.Time, error) {
+	localMidnight := time.Date(
+		date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC,
+	).Add(-time.Duration(lon / 15 * float64(time.Hour)))
+	return panchanga.NextSunrise(swe, localMidnight, lon, lat)
+}
+
+// TithiOccurrence is when a tithi of a lunar month happens
+type TithiOccurrence struct {
+	// Sunrise is the sunrise of the day the tithi is observed on: the one
+	// during the tithi or, if the tithi starts and ends between two sunrises
+	// (a kshaya tithi), the one before it
+	Sunrise time.Time `json:"sunrise"`
+	// Date is the lunisolar date at the start of the tithi
+	Date     *Date `json:"date"`
+	IsKshaya bool  `json:"isKshaya"`
+}
+
+// FindTithi returns when the tithi number (from 1 to 30) of the amanta month
+// masa of the Vikram Samvat year vikramSamvat happens, as seen from lon/lat
+func FindTithi(
+	swe *wrapper.SwissEph,
+	vikramSamvat int,
+	masa Masa,
+	isAdhika bool,
+	number int,
+	lon, lat float64,
+) (*TithiOccurrence, error) {
+	if number < 1 || number > 30 {
+		return nil, fmt.Errorf("invalid tithi number: %d", number)
+	}
+	if masa < Masa_Chaitra || masa > Masa_Phalguna {
+		return nil, fmt.Errorf("invalid masa: %d", masa)
+	}
+	// Chaitra starts around the end of March. Start a month and a half
+	// before the expected start of masa, to leave room for adhika months
+	gregorianYear := vikramSamvat - VikramSamvatOffset
+	guess := time.Date(gregorianYear, 3, 25, 0, 0, 0, 0, time.UTC).Add(
+		time.Duration(float64(masa-1)*SynodicMonth*float64(24*time.Hour)) -
+			45*24*time.Hour,
+	)
+	newMoon, err := panchanga.FindElongation(swe, guess, 0, true)
+	if err != nil {
+		return nil, err
+	}
+	var month *Date
+	for i := 0; i < 4; i++ {
+		d, err := At(swe, newMoon.Add(time.Hour))
+		if err != nil {
+			return nil, err
+		}
+		if d.AmantaMonth == masa &&
+			d.IsAdhika == isAdhika &&
+			d.VikramSamvat == vikramSamvat {
+			month = d
+			break
+		}
+		newMoon = d.LunarMonth.End.Time
+	}
+	if month == nil {
+		adhika := ""
+		if isAdhika {
+			adhika = "adhika "
+		}
+		return nil, fmt.Errorf(
+			"could not find %s%s in Vikram Samvat %d", adhika, masa, vikramSamvat)
+	}
+
+	start := month.LunarMonth.Start.Time
+	if number > 1 {
+		start, err = panchanga.FindElongation(
+			swe, start, float64(number-1)*panchanga.TithiSpan, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Convert a minute in, so that the previous tithi isn't picked up
+	d, err := At(swe, start.Add(time.Minute))
+	if err != nil {
+		return nil, err
+	}
+	end := d.Tithi.Interval.End.Time
+
+	// The day of the tithi is the one whose sunrise is during the tithi
+	sunrise, err := panchanga.NextSunrise(swe, start, lon, lat)
+	if err != nil {
+		return nil, err
+	}
+	isKshaya := !sunrise.Before(end)
+	if isKshaya {
+		sunrise, err = panchanga.NextSunrise(swe, start.Add(-24*time.Hour), lon, lat)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &TithiOccurrence{
+		Sunrise:  sunrise,
+		Date:     d,
+		IsKshaya: isKshaya,
+	}, nil
+}
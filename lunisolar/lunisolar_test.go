@@ -0,0 +1,103 @@
+package lunisolar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/panchanga"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+// Delhi
+const (
+	testLon = 77.2090
+	testLat = 28.6139
+)
+
+func TestFromGregorian(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+
+	type testCase struct {
+		title              string
+		date               time.Time
+		expectedAmanta     Masa
+		expectedPurnimanta Masa
+		expectedIsAdhika   bool
+		expectedTithi      int
+		expectedVikram     int
+		expectedSolarMonth sign.Sign
+	}
+	for _, tc := range []testCase{
+		{
+			title:              "new year's day 2024",
+			date:               time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			expectedAmanta:     Masa_Margashirsha,
+			expectedPurnimanta: Masa_Pausha,
+			expectedTithi:      20, // Krishna Panchami
+			expectedVikram:     2080,
+			expectedSolarMonth: sign.Sagittarius,
+		},
+		{
+			// Adhika Shravana ran from 2023-07-18 to 2023-08-16
+			title:              "adhika shravana",
+			date:               time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC),
+			expectedAmanta:     Masa_Shravana,
+			expectedPurnimanta: Masa_Shravana,
+			expectedIsAdhika:   true,
+			expectedTithi:      15, // Purnima
+			expectedVikram:     2080,
+			expectedSolarMonth: sign.Cancer,
+		},
+		{
+			title:              "ram navami 2024",
+			date:               time.Date(2024, 4, 17, 0, 0, 0, 0, time.UTC),
+			expectedAmanta:     Masa_Chaitra,
+			expectedPurnimanta: Masa_Chaitra,
+			expectedTithi:      9,
+			expectedVikram:     2081,
+			expectedSolarMonth: sign.Aries,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			d, err := FromGregorian(swe, tc.date, testLon, testLat)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedAmanta, d.AmantaMonth)
+			require.Equal(t, tc.expectedPurnimanta, d.PurnimantaMonth)
+			require.Equal(t, tc.expectedIsAdhika, d.IsAdhika)
+			require.Equal(t, tc.expectedTithi, d.Tithi.Number)
+			require.Equal(t, tc.expectedVikram, d.VikramSamvat)
+			require.Equal(t, tc.expectedVikram-135, d.ShakaSamvat)
+			require.Equal(t, tc.expectedSolarMonth, d.SolarMonth.Sign)
+			require.True(t, d.LunarMonth.Start.Before(d.Time))
+			require.True(t, d.LunarMonth.End.After(d.Time))
+			require.True(t, d.SolarMonth.Interval.Start.Before(d.Time))
+			require.True(t, d.SolarMonth.Interval.End.After(d.Time))
+		})
+	}
+}
+
+func TestFindTithi(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+
+	// Ram Navami 2024: Chaitra Shukla Navami
+	occ, err := FindTithi(swe, 2081, Masa_Chaitra, false, 9, testLon, testLat)
+	require.NoError(t, err)
+	require.Equal(t, time.Month(4), occ.Sunrise.Month())
+	require.Equal(t, 17, occ.Sunrise.Day())
+	require.Equal(t, panchanga.Paksha_Shukla, occ.Date.Tithi.Paksha)
+	require.Equal(t, 9, occ.Date.Tithi.Number)
+
+	// Ganesh Chaturthi 2023: Bhadrapada Shukla Chaturthi, right after an
+	// adhika month
+	occ, err = FindTithi(swe, 2080, Masa_Bhadrapada, false, 4, testLon, testLat)
+	require.NoError(t, err)
+	require.Equal(t, time.Month(9), occ.Sunrise.Month())
+	require.Equal(t, 19, occ.Sunrise.Day())
+
+	_, err = FindTithi(swe, 2081, Masa_Chaitra, true, 9, testLon, testLat)
+	require.Error(t, err)
+}
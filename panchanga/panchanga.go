@@ -0,0 +1,421 @@
+// Package panchanga calculates the five limbs (panchanga) of the Hindu
+// almanac for a moment and a place:
+//   - Tithi: the lunar day, i.e., each 12 degrees of elongation of the Moon
+//     from the Sun
+//   - Vara: the weekday, which starts at sunrise
+//   - Nakshatra: the lunar mansion of the Moon
+//   - Yoga: each 13°20' of the sum of the sidereal longitudes of the Sun and
+//     the Moon
+//   - Karana: half a tithi
+//
+// Sunrise and sunset follow the Hindu convention: the center of the disc
+// touching the horizon, without refraction.
+package panchanga
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/wrapper"
+)
+
+const (
+	// TithiSpan is the elongation of the Moon from the Sun covered by a
+	// tithi, in degrees
+	TithiSpan = 12.0
+	// KaranaSpan is half a tithi, in degrees
+	KaranaSpan = TithiSpan / 2
+	// YogaSpan is the same as a nakshatra, in degrees
+	YogaSpan = chart.NakshatraSpan
+)
+
+type Paksha string
+
+const (
+	// Paksha_Shukla is the bright (waxing) half of the lunar month
+	Paksha_Shukla Paksha = "shukla"
+	// Paksha_Krishna is the dark (waning) half of the lunar month
+	Paksha_Krishna Paksha = "krishna"
+)
+
+func (p Paksha) String() string {
+	return string(p)
+}
+
+var tithiNames = []string{
+	"Pratipada",
+	"Dwitiya",
+	"Tritiya",
+	"Chaturthi",
+	"Panchami",
+	"Shashthi",
+	"Saptami",
+	"Ashtami",
+	"Navami",
+	"Dashami",
+	"Ekadashi",
+	"Dwadashi",
+	"Trayodashi",
+	"Chaturdashi",
+}
+
+var yogaNames = []string{
+	"Vishkambha",
+	"Priti",
+	"Ayushman",
+	"Saubhagya",
+	"Shobhana",
+	"Atiganda",
+	"Sukarma",
+	"Dhriti",
+	"Shula",
+	"Ganda",
+	"Vriddhi",
+	"Dhruva",
+	"Vyaghata",
+	"Harshana",
+	"Vajra",
+	"Siddhi",
+	"Vyatipata",
+	"Variyana",
+	"Parigha",
+	"Shiva",
+	"Siddha",
+	"Sadhya",
+	"Shubha",
+	"Shukla",
+	"Brahma",
+	"Indra",
+	"Vaidhriti",
+}
+
+// movableKaranaNames repeat 8 times from the 2nd to the 57th karana of the
+// lunar month
+var movableKaranaNames = []string{
+	"Bava",
+	"Balava",
+	"Kaulava",
+	"Taitila",
+	"Gara",
+	"Vanija",
+	"Vishti",
+}
+
+type Tithi struct {
+	// Number is the tithi of the lunar month, from 1 to 30. 1 to 15 are in
+	// Shukla paksha and 16 to 30 are in Krishna paksha
+	Number   int            `json:"number"`
+	Name     string         `json:"name"`
+	Paksha   Paksha         `json:"paksha"`
+	Interval chart.Interval `json:"interval"`
+}
+
+func (t Tithi) String() string {
+	return fmt.Sprintf("%s %s", t.Paksha, t.Name)
+}
+
+// TithiName returns the name of the tithi number (from 1 to 30)
+func TithiName(number int) string {
+	switch number {
+	case 15:
+		return "Purnima"
+	case 30:
+		return "Amavasya"
+	}
+	return tithiNames[(number-1)%15]
+}
+
+type Vara struct {
+	Weekday time.Weekday    `json:"weekday"`
+	Name    string          `json:"name"`
+	Lord    pointid.PointID `json:"lord"`
+}
+
+func (v Vara) String() string {
+	return v.Name
+}
+
+// NewVara returns the vara of the weekday
+func NewVara(wd time.Weekday) Vara {
+	switch wd {
+	case time.Sunday:
+		return Vara{wd, "Ravivara", pointid.Sun}
+	case time.Monday:
+		return Vara{wd, "Somavara", pointid.Moon}
+	case time.Tuesday:
+		return Vara{wd, "Mangalavara", pointid.Mars}
+	case time.Wednesday:
+		return Vara{wd, "Budhavara", pointid.Mercury}
+	case time.Thursday:
+		return Vara{wd, "Guruvara", pointid.Jupiter}
+	case time.Friday:
+		return Vara{wd, "Shukravara", pointid.Venus}
+	}
+	return Vara{time.Saturday, "Shanivara", pointid.Saturn}
+}
+
+type Nakshatra struct {
+	Nakshatra chart.NakshatraType `json:"nakshatra"`
+	// Interval is when the Moon enters and leaves the nakshatra
+	Interval chart.Interval `json:"interval"`
+}
+
+func (n Nakshatra) String() string {
+	return n.Nakshatra.String()
+}
+
+type Yoga struct {
+	// Number is from 1 to 27
+	Number   int            `json:"number"`
+	Name     string         `json:"name"`
+	Interval chart.Interval `json:"interval"`
+}
+
+func (y Yoga) String() string {
+	return y.Name
+}
+
+type Karana struct {
+	// Number is the karana of the lunar month, from 1 to 60
+	Number   int            `json:"number"`
+	Name     string         `json:"name"`
+	Interval chart.Interval `json:"interval"`
+}
+
+func (k Karana) String() string {
+	return k.Name
+}
+
+// KaranaName returns the name of the karana number (from 1 to 60). The first
+// and the last three karanas of the lunar month are fixed, and the 7 movable
+// ones cycle in between.
+func KaranaName(number int) string {
+	switch number {
+	case 1:
+		return "Kimstughna"
+	case 58:
+		return "Shakuni"
+	case 59:
+		return "Chatushpada"
+	case 60:
+		return "Naga"
+	}
+	return movableKaranaNames[(number-2)%len(movableKaranaNames)]
+}
+
+type Panchanga struct {
+	Time      time.Time `json:"time"`
+	Longitude float64   `json:"longitude"`
+	Latitude  float64   `json:"latitude"`
+	Tithi     Tithi     `json:"tithi"`
+	Vara      Vara      `json:"vara"`
+	Nakshatra Nakshatra `json:"nakshatra"`
+	Yoga      Yoga      `json:"yoga"`
+	Karana    Karana    `json:"karana"`
+	// Sunrise is the start of the Hindu day Time is in (i.e., the last
+	// sunrise at or before Time) and NextSunrise is its end
+	Sunrise     time.Time `json:"sunrise"`
+	Sunset      time.Time `json:"sunset"`
+	NextSunrise time.Time `json:"nextSunrise"`
+	// Moonrise and Moonset are the first ones after Sunrise. They're zero if
+	// the Moon doesn't rise or set at this latitude.
+	Moonrise time.Time `json:"moonrise"`
+	Moonset  time.Time `json:"moonset"`
+}
+
+func (p *Panchanga) String() string {
+	return fmt.Sprintf(
+		"Panchanga{Tithi: %s, Vara: %s, Nakshatra: %s, Yoga: %s, Karana: %s, Sunrise: %s, Sunset: %s}",
+		p.Tithi,
+		p.Vara,
+		p.Nakshatra,
+		p.Yoga,
+		p.Karana,
+		p.Sunrise.Format("2006-01-02 15:04"),
+		p.Sunset.Format("2006-01-02 15:04"),
+	)
+}
+
+// Calculate returns the panchanga at t, as seen from lon/lat
+func Calculate(
+	swe *wrapper.SwissEph,
+	t time.Time,
+	lon, lat float64,
+) (*Panchanga, error) {
+	jd := swe.GoTimeToJulianDay(t.UTC())
+	ret := &Panchanga{
+		Time:      t,
+		Longitude: lon,
+		Latitude:  lat,
+	}
+	interval := func(start, end float64) chart.Interval {
+		return chart.NewInterval(
+			swe.JulianDayToGoTime(start),
+			swe.JulianDayToGoTime(end),
+		)
+	}
+
+	// Tithi and karana
+	tithi, err := CalculateTithi(swe, t)
+	if err != nil {
+		return nil, err
+	}
+	ret.Tithi = tithi
+	idx, start, end, err := angleElement(elongation(swe), jd, KaranaSpan)
+	if err != nil {
+		return nil, fmt.Errorf("while calculating karana: %v", err)
+	}
+	ret.Karana = Karana{
+		Number:   idx + 1,
+		Name:     KaranaName(idx + 1),
+		Interval: interval(start, end),
+	}
+
+	// Nakshatra and yoga
+	idx, start, end, err = angleElement(siderealMoon(swe), jd, chart.NakshatraSpan)
+	if err != nil {
+		return nil, fmt.Errorf("while calculating nakshatra: %v", err)
+	}
+	nak, err := chart.NewNakshatraTypeFromInt(idx)
+	if err != nil {
+		return nil, fmt.Errorf("while calculating nakshatra: %v", err)
+	}
+	ret.Nakshatra = Nakshatra{
+		Nakshatra: nak,
+		Interval:  interval(start, end),
+	}
+	idx, start, end, err = angleElement(siderealSunMoonSum(swe), jd, YogaSpan)
+	if err != nil {
+		return nil, fmt.Errorf("while calculating yoga: %v", err)
+	}
+	ret.Yoga = Yoga{
+		Number:   idx + 1,
+		Name:     yogaNames[idx],
+		Interval: interval(start, end),
+	}
+
+	// Sunrise, sunset and vara
+	sunrise, err := previousSunrise(swe, jd, lon, lat)
+	if err != nil {
+		return nil, fmt.Errorf("while finding sunrise: %v", err)
+	}
+	ret.Sunrise = swe.JulianDayToGoTime(sunrise)
+	// The weekday is the one of the local date at sunrise, using the local
+	// mean time of lon
+	localSunrise := ret.Sunrise.Add(time.Duration(lon / 15 * float64(time.Hour)))
+	ret.Vara = NewVara(localSunrise.Weekday())
+	for _, e := range []struct {
+		pid   pointid.PointID
+		event wrapper.RiseTransEvent
+		out   *time.Time
+	}{
+		{pointid.Sun, wrapper.RiseTransEvent_Set, &ret.Sunset},
+		{pointid.Sun, wrapper.RiseTransEvent_Rise, &ret.NextSunrise},
+		{pointid.Moon, wrapper.RiseTransEvent_Rise, &ret.Moonrise},
+		{pointid.Moon, wrapper.RiseTransEvent_Set, &ret.Moonset},
+	} {
+		// Start a minute after sunrise so that it's not found again
+		eventJd, err := nextRiseTrans(swe, sunrise+1.0/24/60, e.pid, lon, lat, e.event)
+		if err != nil {
+			return nil, fmt.Errorf("while finding rise/set of %s: %v", e.pid, err)
+		}
+		if eventJd != 0 {
+			*e.out = swe.JulianDayToGoTime(eventJd)
+		}
+	}
+	return ret, nil
+}
+
+// CalculateTithi returns the tithi running at t
+func CalculateTithi(swe *wrapper.SwissEph, t time.Time) (Tithi, error) {
+	jd := swe.GoTimeToJulianDay(t.UTC())
+	idx, start, end, err := angleElement(elongation(swe), jd, TithiSpan)
+	if err != nil {
+		return Tithi{}, fmt.Errorf("while calculating tithi: %v", err)
+	}
+	paksha := Paksha_Shukla
+	if idx >= 15 {
+		paksha = Paksha_Krishna
+	}
+	return Tithi{
+		Number: idx + 1,
+		Name:   TithiName(idx + 1),
+		Paksha: paksha,
+		Interval: chart.NewInterval(
+			swe.JulianDayToGoTime(start),
+			swe.JulianDayToGoTime(end),
+		),
+	}, nil
+}
+
+// FindElongation returns when the elongation of the Moon from the Sun next
+// reaches angle (in degrees) after t, or last reached it before t if forward
+// is false. For example, 0 is a new moon and 180 a full moon.
+func FindElongation(
+	swe *wrapper.SwissEph,
+	t time.Time,
+	angle float64,
+	forward bool,
+) (time.Time, error) {
+	// A synodic month is ~29.5 days, so look up to 35 days away
+	jd, err := findCrossingWithMaxSteps(
+		elongation(swe),
+		swe.GoTimeToJulianDay(t.UTC()),
+		angle,
+		forward,
+		4*35,
+	)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("while finding elongation %f: %v", angle, err)
+	}
+	return swe.JulianDayToGoTime(jd), nil
+}
+
+// FindSiderealSunLongitude returns when the sidereal longitude of the Sun
+// next reaches lon (in degrees) after t, or last reached it before t if
+// forward is false. For example, 0 is the Mesha sankranti.
+func FindSiderealSunLongitude(
+	swe *wrapper.SwissEph,
+	t time.Time,
+	lon float64,
+	forward bool,
+) (time.Time, error) {
+	sun := func(jd float64) (float64, error) {
+		ret, _, err := swe.CalcUT(jd, pointid.Sun.SwissEphID(), true)
+		return ret, err
+	}
+	// The Sun moves a degree a day, so a step of a quarter of a day is
+	// slow but safe
+	jd, err := findCrossingWithMaxSteps(
+		sun,
+		swe.GoTimeToJulianDay(t.UTC()),
+		lon,
+		forward,
+		4*400,
+	)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("while finding Sun at %f: %v", lon, err)
+	}
+	return swe.JulianDayToGoTime(jd), nil
+}
+
+// NextSunrise returns the first sunrise at or after t, as seen from lon/lat
+func NextSunrise(
+	swe *wrapper.SwissEph,
+	t time.Time,
+	lon, lat float64,
+) (time.Time, error) {
+	jd, err := swe.RiseTrans(
+		swe.GoTimeToJulianDay(t.UTC()),
+		pointid.Sun.SwissEphID(),
+		lon, lat,
+		wrapper.RiseTransEvent_Rise,
+		true,
+	)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("while finding sunrise: %v", err)
+	}
+	return swe.JulianDayToGoTime(jd), nil
+}
@@ -0,0 +1,73 @@
+package panchanga
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculate(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+
+	// London, 2024-01-01 00:00 UTC: five days after the full moon of
+	// 2023-12-27, with the Moon in Magha
+	tm := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	p, err := Calculate(swe, tm, -0.1278, 51.5074)
+	require.NoError(t, err)
+
+	require.Equal(t, 20, p.Tithi.Number)
+	require.Equal(t, "Panchami", p.Tithi.Name)
+	require.Equal(t, Paksha_Krishna, p.Tithi.Paksha)
+	require.Equal(t, chart.Magha, p.Nakshatra.Nakshatra)
+	require.Equal(t, "Ayushman", p.Yoga.Name)
+	require.Equal(t, 40, p.Karana.Number)
+	require.Equal(t, "Taitila", p.Karana.Name)
+
+	// Every element is running at tm, and the karana is within the tithi
+	for _, it := range []chart.Interval{
+		p.Tithi.Interval,
+		p.Nakshatra.Interval,
+		p.Yoga.Interval,
+		p.Karana.Interval,
+	} {
+		require.False(t, it.Start.After(tm), it)
+		require.True(t, it.End.After(tm), it)
+		require.Less(t, it.End.Sub(it.Start.Time), 30*time.Hour, it)
+	}
+	require.False(t, p.Karana.Interval.Start.Before(p.Tithi.Interval.Start.Time))
+	require.False(t, p.Karana.Interval.End.After(p.Tithi.Interval.End.Time))
+
+	// It's just after midnight, so the Hindu day is still Sunday's, which
+	// started at sunrise on 2023-12-31
+	require.Equal(t, time.Sunday, p.Vara.Weekday)
+	require.Equal(t, pointid.Sun, p.Vara.Lord)
+	require.Equal(t, 31, p.Sunrise.Day())
+	require.True(t, p.Sunrise.Hour() == 8, p.Sunrise)
+	require.True(t, p.Sunset.After(p.Sunrise))
+	require.True(t, p.Sunset.Before(p.NextSunrise))
+	require.True(t, p.NextSunrise.After(tm))
+	require.False(t, p.Moonrise.IsZero())
+	require.False(t, p.Moonset.IsZero())
+
+	// The tithi ends when the next one starts
+	next, err := Calculate(swe, p.Tithi.Interval.End.Add(time.Minute), -0.1278, 51.5074)
+	require.NoError(t, err)
+	require.Equal(t, 21, next.Tithi.Number)
+	require.Equal(t, p.Tithi.Interval.End, next.Tithi.Interval.Start)
+}
+
+func TestNames(t *testing.T) {
+	require.Equal(t, "Pratipada", TithiName(1))
+	require.Equal(t, "Purnima", TithiName(15))
+	require.Equal(t, "Pratipada", TithiName(16))
+	require.Equal(t, "Amavasya", TithiName(30))
+	require.Equal(t, "Kimstughna", KaranaName(1))
+	require.Equal(t, "Bava", KaranaName(2))
+	require.Equal(t, "Vishti", KaranaName(57))
+	require.Equal(t, "Naga", KaranaName(60))
+}
@@ -0,0 +1,210 @@
+package panchanga
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/wrapper"
+)
+
+const (
+	// searchStep is how far (in days) findCrossing moves while bracketing a
+	// crossing. None of the angles we track moves more than ~30 degrees per
+	// day, so a quarter of a day never skips a whole element.
+	searchStep = 0.25
+	// searchMaxSteps bounds the bracketing to 10 days, which is enough for
+	// the angles that involve the Moon
+	searchMaxSteps = 40
+	// searchPrecision is half a minute, in days: the times are rounded to
+	// the minute anyway
+	searchPrecision = 1.0 / 24 / 60 / 2
+)
+
+// angleFunc returns an angle (in degrees, from 0 to 360) that increases with
+// time, e.g., the elongation of the Moon from the Sun
+type angleFunc func(jd float64) (float64, error)
+
+// findCrossing returns the Julian day at which f reaches target, searching
+// forward or backward from jd
+func findCrossing(
+	f angleFunc,
+	jd float64,
+	target float64,
+	forward bool,
+) (float64, error) {
+	return findCrossingWithMaxSteps(f, jd, target, forward, searchMaxSteps)
+}
+
+// findCrossingWithMaxSteps is findCrossing for angles slower than the Moon's,
+// which need more than searchMaxSteps to be bracketed
+func findCrossingWithMaxSteps(
+	f angleFunc,
+	jd float64,
+	target float64,
+	forward bool,
+	maxSteps int,
+) (float64, error) {
+	// delta returns how far f is past target, from -180 to 180
+	delta := func(jd float64) (float64, error) {
+		a, err := f(jd)
+		if err != nil {
+			return 0, err
+		}
+		return math.Mod(a-target+540, 360) - 180, nil
+	}
+
+	step := searchStep
+	if !forward {
+		step = -step
+	}
+	// Bracket the crossing: lo is before it and hi is at or after it
+	var lo, hi float64
+	didFind := false
+	for i := 0; i < maxSteps; i++ {
+		from := jd + float64(i)*step
+		to := from + step
+		fromDelta, err := delta(from)
+		if err != nil {
+			return 0, err
+		}
+		toDelta, err := delta(to)
+		if err != nil {
+			return 0, err
+		}
+		if forward && fromDelta < 0 && toDelta >= 0 {
+			lo, hi = from, to
+			didFind = true
+			break
+		}
+		if !forward && fromDelta >= 0 && toDelta < 0 {
+			lo, hi = to, from
+			didFind = true
+			break
+		}
+	}
+	if !didFind {
+		return 0, fmt.Errorf("could not find when the angle reaches %f", target)
+	}
+
+	for hi-lo > searchPrecision {
+		mid := (lo + hi) / 2
+		d, err := delta(mid)
+		if err != nil {
+			return 0, err
+		}
+		if d >= 0 {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return hi, nil
+}
+
+// elongation returns the tropical longitude of the Moon minus the Sun's. It's
+// the same in the sidereal zodiac.
+func elongation(swe *wrapper.SwissEph) angleFunc {
+	return func(jd float64) (float64, error) {
+		sun, _, err := swe.CalcUT(jd, pointid.Sun.SwissEphID(), false)
+		if err != nil {
+			return 0, fmt.Errorf("while calculating the Sun: %v", err)
+		}
+		moon, _, err := swe.CalcUT(jd, pointid.Moon.SwissEphID(), false)
+		if err != nil {
+			return 0, fmt.Errorf("while calculating the Moon: %v", err)
+		}
+		return math.Mod(moon-sun+360, 360), nil
+	}
+}
+
+// siderealMoon returns the sidereal longitude of the Moon
+func siderealMoon(swe *wrapper.SwissEph) angleFunc {
+	return func(jd float64) (float64, error) {
+		moon, _, err := swe.CalcUT(jd, pointid.Moon.SwissEphID(), true)
+		if err != nil {
+			return 0, fmt.Errorf("while calculating the Moon: %v", err)
+		}
+		return moon, nil
+	}
+}
+
+// siderealSunMoonSum returns the sum of the sidereal longitudes of the Sun
+// and the Moon
+func siderealSunMoonSum(swe *wrapper.SwissEph) angleFunc {
+	return func(jd float64) (float64, error) {
+		sun, _, err := swe.CalcUT(jd, pointid.Sun.SwissEphID(), true)
+		if err != nil {
+			return 0, fmt.Errorf("while calculating the Sun: %v", err)
+		}
+		moon, _, err := swe.CalcUT(jd, pointid.Moon.SwissEphID(), true)
+		if err != nil {
+			return 0, fmt.Errorf("while calculating the Moon: %v", err)
+		}
+		return math.Mod(sun+moon, 360), nil
+	}
+}
+
+// angleElement finds which of the equal segments (of span degrees) f is in at
+// jd, and when f entered and will leave it
+func angleElement(
+	f angleFunc,
+	jd float64,
+	span float64,
+) (idx int, start float64, end float64, err error) {
+	a, err := f(jd)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	idx = int(a / span)
+	start, err = findCrossing(f, jd, float64(idx)*span, false)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("while finding start: %v", err)
+	}
+	end, err = findCrossing(f, jd, math.Mod(float64(idx+1)*span, 360), true)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("while finding end: %v", err)
+	}
+	return idx, start, end, nil
+}
+
+// previousSunrise returns the last sunrise at or before jd
+func previousSunrise(
+	swe *wrapper.SwissEph,
+	jd float64,
+	lon, lat float64,
+) (float64, error) {
+	for daysBack := 1.0; daysBack <= 3; daysBack++ {
+		sr, err := swe.RiseTrans(
+			jd-daysBack,
+			pointid.Sun.SwissEphID(),
+			lon, lat,
+			wrapper.RiseTransEvent_Rise,
+			true,
+		)
+		if err != nil {
+			return 0, err
+		}
+		if sr <= jd {
+			return sr, nil
+		}
+	}
+	return 0, fmt.Errorf("could not find sunrise before %f", jd)
+}
+
+// nextRiseTrans is like SwissEph.RiseTrans, but it returns 0 (instead of an
+// error) if the body doesn't rise or set
+func nextRiseTrans(
+	swe *wrapper.SwissEph,
+	jd float64,
+	pid pointid.PointID,
+	lon, lat float64,
+	event wrapper.RiseTransEvent,
+) (float64, error) {
+	ret, err := swe.RiseTrans(jd, pid.SwissEphID(), lon, lat, event, true)
+	if errors.Is(err, wrapper.ErrNoRiseTrans) {
+		return 0, nil
+	}
+	return ret, err
+}
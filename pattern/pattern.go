@@ -0,0 +1,450 @@
+// Package pattern detects multi-planet configurations (e.g., grand trines,
+// T-squares and stelliums) in a chart.
+//
+// Aspect-based patterns are built on chart.Chart.Aspects, so a pattern is
+// only found if the chart was cast with an aspect config that includes the
+// aspects the pattern needs. For example, a yod needs quincunxes, which the
+// default config doesn't look for (see aspect.NewModernConfig).
+package pattern
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/afjoseph/sacredstar/aspect"
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+)
+
+// StelliumMinPoints is the minimum number of points in the same sign (or
+// house) to count as a stellium
+const StelliumMinPoints = 3
+
+// NoConjunctionOrb is the TightestOrb of a stellium whose points aren't
+// conjunct at all
+const NoConjunctionOrb = -1.0
+
+type PatternType string
+
+const (
+	PatternType_GrandTrine      PatternType = "grand-trine"
+	PatternType_TSquare         PatternType = "t-square"
+	PatternType_GrandCross      PatternType = "grand-cross"
+	PatternType_Yod             PatternType = "yod"
+	PatternType_Kite            PatternType = "kite"
+	PatternType_MysticRectangle PatternType = "mystic-rectangle"
+	PatternType_StelliumBySign  PatternType = "stellium-by-sign"
+	PatternType_StelliumByHouse PatternType = "stellium-by-house"
+	PatternType_GrandSextile    PatternType = "grand-sextile"
+)
+
+func (pt PatternType) String() string {
+	return string(pt)
+}
+
+type Pattern struct {
+	Type   PatternType       `json:"type"`
+	Points []pointid.PointID `json:"points"`
+	// Focus is the point the pattern "points to": the apex of a T-square or
+	// a yod, or the tail of a kite. It's pointid.None for other patterns.
+	Focus pointid.PointID `json:"focus"`
+	// TightestOrb is the smallest deviation from exact (in degrees) among the
+	// aspects that make up the pattern. For stelliums, it's the tightest
+	// conjunction between their points, or NoConjunctionOrb if none of them
+	// are conjunct.
+	TightestOrb float64 `json:"tightestOrb"`
+	// Sign and House are only set for stelliums
+	Sign  sign.Sign   `json:"sign,omitempty"`
+	House house.House `json:"house,omitempty"`
+}
+
+func (p *Pattern) String() string {
+	points := []string{}
+	for _, pid := range p.Points {
+		points = append(points, pid.String())
+	}
+	return fmt.Sprintf(
+		"Pattern{Type: %s, Points: [%s], Focus: %s, TightestOrb: %f}",
+		p.Type,
+		strings.Join(points, ", "),
+		p.Focus,
+		p.TightestOrb,
+	)
+}
+
+// Detect finds all the patterns in a chart
+func Detect(chrt *chart.Chart) []*Pattern {
+	d := newDetector(chrt)
+	ret := []*Pattern{}
+	ret = append(ret, d.grandTrines()...)
+	ret = append(ret, d.tSquares()...)
+	ret = append(ret, d.grandCrosses()...)
+	ret = append(ret, d.yods()...)
+	ret = append(ret, d.kites()...)
+	ret = append(ret, d.mysticRectangles()...)
+	ret = append(ret, d.grandSextiles()...)
+	ret = append(ret, d.stelliumsBySign()...)
+	ret = append(ret, d.stelliumsByHouse()...)
+	return ret
+}
+
+// DetectType finds all the patterns of one type in a chart
+func DetectType(chrt *chart.Chart, pt PatternType) []*Pattern {
+	ret := []*Pattern{}
+	for _, p := range Detect(chrt) {
+		if p.Type == pt {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
+
+type detector struct {
+	chrt   *chart.Chart
+	points []pointid.PointID
+}
+
+func newDetector(chrt *chart.Chart) *detector {
+	points := []pointid.PointID{}
+	for _, p := range chrt.Points {
+		points = append(points, p.ID)
+	}
+	return &detector{chrt: chrt, points: points}
+}
+
+// aspect returns the aspect of type at between p1 and p2, or nil
+func (d *detector) aspect(
+	p1, p2 pointid.PointID,
+	at aspect.AspectType,
+) *aspect.Aspect {
+	asp := d.chrt.GetAspect(p1, p2)
+	if asp == nil || asp.Type != at {
+		return nil
+	}
+	return asp
+}
+
+// tightest returns the smallest deviation of the given aspects. All of them
+// must be non-nil.
+func tightest(asps ...*aspect.Aspect) float64 {
+	ret := math.MaxFloat64
+	for _, a := range asps {
+		ret = math.Min(ret, a.Deviation)
+	}
+	return ret
+}
+
+// pairs returns all the pairs of points with an aspect of type at
+func (d *detector) pairs(at aspect.AspectType) [][2]pointid.PointID {
+	ret := [][2]pointid.PointID{}
+	for i, p1 := range d.points {
+		for _, p2 := range d.points[i+1:] {
+			if d.aspect(p1, p2, at) != nil {
+				ret = append(ret, [2]pointid.PointID{p1, p2})
+			}
+		}
+	}
+	return ret
+}
+
+func (d *detector) grandTrines() []*Pattern {
+	ret := []*Pattern{}
+	for i, a := range d.points {
+		for j := i + 1; j < len(d.points); j++ {
+			b := d.points[j]
+			ab := d.aspect(a, b, aspect.AspectType_Trine)
+			if ab == nil {
+				continue
+			}
+			for _, c := range d.points[j+1:] {
+				bc := d.aspect(b, c, aspect.AspectType_Trine)
+				ca := d.aspect(c, a, aspect.AspectType_Trine)
+				if bc == nil || ca == nil {
+					continue
+				}
+				ret = append(ret, &Pattern{
+					Type:        PatternType_GrandTrine,
+					Points:      []pointid.PointID{a, b, c},
+					Focus:       pointid.None,
+					TightestOrb: tightest(ab, bc, ca),
+				})
+			}
+		}
+	}
+	return ret
+}
+
+func (d *detector) tSquares() []*Pattern {
+	ret := []*Pattern{}
+	for _, opp := range d.pairs(aspect.AspectType_Opposition) {
+		a, b := opp[0], opp[1]
+		for _, apex := range d.points {
+			sqA := d.aspect(apex, a, aspect.AspectType_Square)
+			sqB := d.aspect(apex, b, aspect.AspectType_Square)
+			if sqA == nil || sqB == nil {
+				continue
+			}
+			ret = append(ret, &Pattern{
+				Type:   PatternType_TSquare,
+				Points: []pointid.PointID{a, b, apex},
+				Focus:  apex,
+				TightestOrb: tightest(
+					d.aspect(a, b, aspect.AspectType_Opposition),
+					sqA,
+					sqB,
+				),
+			})
+		}
+	}
+	return ret
+}
+
+// rectangles finds two oppositions (a, c) and (b, d) where a-b, b-c, c-d and
+// d-a are linked with the given aspects (in that order)
+func (d *detector) rectangles(
+	pt PatternType,
+	sides [4]aspect.AspectType,
+) []*Pattern {
+	ret := []*Pattern{}
+	seen := map[string]bool{}
+	opps := d.pairs(aspect.AspectType_Opposition)
+	for i, opp1 := range opps {
+		for _, opp2 := range opps[i+1:] {
+			for _, o2 := range [][2]pointid.PointID{
+				{opp2[0], opp2[1]},
+				{opp2[1], opp2[0]},
+			} {
+				a, c := opp1[0], opp1[1]
+				b, dd := o2[0], o2[1]
+				if a == b || a == dd || c == b || c == dd {
+					continue
+				}
+				asps := []*aspect.Aspect{
+					d.aspect(a, b, sides[0]),
+					d.aspect(b, c, sides[1]),
+					d.aspect(c, dd, sides[2]),
+					d.aspect(dd, a, sides[3]),
+				}
+				if slices.Contains(asps, nil) {
+					continue
+				}
+				points := []pointid.PointID{a, b, c, dd}
+				key := pointsKey(points)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				asps = append(
+					asps,
+					d.aspect(a, c, aspect.AspectType_Opposition),
+					d.aspect(b, dd, aspect.AspectType_Opposition),
+				)
+				ret = append(ret, &Pattern{
+					Type:        pt,
+					Points:      points,
+					Focus:       pointid.None,
+					TightestOrb: tightest(asps...),
+				})
+			}
+		}
+	}
+	return ret
+}
+
+func (d *detector) grandCrosses() []*Pattern {
+	return d.rectangles(PatternType_GrandCross, [4]aspect.AspectType{
+		aspect.AspectType_Square,
+		aspect.AspectType_Square,
+		aspect.AspectType_Square,
+		aspect.AspectType_Square,
+	})
+}
+
+func (d *detector) mysticRectangles() []*Pattern {
+	// Both orientations (sextile first or trine first) are covered since
+	// rectangles() tries both orders of the second opposition
+	return d.rectangles(PatternType_MysticRectangle, [4]aspect.AspectType{
+		aspect.AspectType_Sextile,
+		aspect.AspectType_Trine,
+		aspect.AspectType_Sextile,
+		aspect.AspectType_Trine,
+	})
+}
+
+func (d *detector) yods() []*Pattern {
+	ret := []*Pattern{}
+	for _, sxt := range d.pairs(aspect.AspectType_Sextile) {
+		a, b := sxt[0], sxt[1]
+		for _, apex := range d.points {
+			qA := d.aspect(apex, a, aspect.AspectType_Quincunx)
+			qB := d.aspect(apex, b, aspect.AspectType_Quincunx)
+			if qA == nil || qB == nil {
+				continue
+			}
+			ret = append(ret, &Pattern{
+				Type:   PatternType_Yod,
+				Points: []pointid.PointID{a, b, apex},
+				Focus:  apex,
+				TightestOrb: tightest(
+					d.aspect(a, b, aspect.AspectType_Sextile),
+					qA,
+					qB,
+				),
+			})
+		}
+	}
+	return ret
+}
+
+func (d *detector) kites() []*Pattern {
+	ret := []*Pattern{}
+	for _, gt := range d.grandTrines() {
+		for i, head := range gt.Points {
+			wing1 := gt.Points[(i+1)%3]
+			wing2 := gt.Points[(i+2)%3]
+			for _, tail := range d.points {
+				opp := d.aspect(tail, head, aspect.AspectType_Opposition)
+				sxt1 := d.aspect(tail, wing1, aspect.AspectType_Sextile)
+				sxt2 := d.aspect(tail, wing2, aspect.AspectType_Sextile)
+				if opp == nil || sxt1 == nil || sxt2 == nil {
+					continue
+				}
+				ret = append(ret, &Pattern{
+					Type:   PatternType_Kite,
+					Points: []pointid.PointID{head, wing1, wing2, tail},
+					Focus:  tail,
+					TightestOrb: math.Min(
+						gt.TightestOrb,
+						tightest(opp, sxt1, sxt2),
+					),
+				})
+			}
+		}
+	}
+	return ret
+}
+
+// grandSextiles finds two grand trines where each point of one is opposite a
+// point of the other. The six points then form a hexagon of sextiles.
+func (d *detector) grandSextiles() []*Pattern {
+	ret := []*Pattern{}
+	gts := d.grandTrines()
+	for i, gt1 := range gts {
+		for _, gt2 := range gts[i+1:] {
+			asps := []*aspect.Aspect{}
+			for _, p1 := range gt1.Points {
+				for _, p2 := range gt2.Points {
+					if opp := d.aspect(p1, p2, aspect.AspectType_Opposition); opp != nil {
+						asps = append(asps, opp)
+					}
+				}
+			}
+			if len(asps) != 3 {
+				continue
+			}
+			points := append([]pointid.PointID{}, gt1.Points...)
+			points = append(points, gt2.Points...)
+			ret = append(ret, &Pattern{
+				Type:   PatternType_GrandSextile,
+				Points: points,
+				Focus:  pointid.None,
+				TightestOrb: math.Min(
+					tightest(asps...),
+					math.Min(gt1.TightestOrb, gt2.TightestOrb),
+				),
+			})
+		}
+	}
+	return ret
+}
+
+func (d *detector) stelliumsBySign() []*Pattern {
+	ret := []*Pattern{}
+	for i := 1; i <= 12; i++ {
+		s, err := sign.NewSignFromInt(i)
+		if err != nil {
+			panic(err)
+		}
+		points := []pointid.PointID{}
+		for _, p := range d.chrt.Points {
+			if p.ID == pointid.ASC || chart.IsSpecialPoint(p.ID) {
+				continue
+			}
+			if p.ZodiacalPos.Sign == s {
+				points = append(points, p.ID)
+			}
+		}
+		if len(points) < StelliumMinPoints {
+			continue
+		}
+		ret = append(ret, &Pattern{
+			Type:        PatternType_StelliumBySign,
+			Points:      points,
+			Focus:       pointid.None,
+			TightestOrb: d.tightestConjunction(points),
+			Sign:        s,
+			House:       house.HouseNone,
+		})
+	}
+	return ret
+}
+
+func (d *detector) stelliumsByHouse() []*Pattern {
+	ret := []*Pattern{}
+	for i := 1; i <= 12; i++ {
+		h, err := house.HouseFromInt(i)
+		if err != nil {
+			panic(err)
+		}
+		points := []pointid.PointID{}
+		for _, p := range d.chrt.Points {
+			if p.ID == pointid.ASC || chart.IsSpecialPoint(p.ID) {
+				continue
+			}
+			if p.House == h {
+				points = append(points, p.ID)
+			}
+		}
+		if len(points) < StelliumMinPoints {
+			continue
+		}
+		ret = append(ret, &Pattern{
+			Type:        PatternType_StelliumByHouse,
+			Points:      points,
+			Focus:       pointid.None,
+			TightestOrb: d.tightestConjunction(points),
+			House:       h,
+		})
+	}
+	return ret
+}
+
+func (d *detector) tightestConjunction(points []pointid.PointID) float64 {
+	ret := math.MaxFloat64
+	for i, p1 := range points {
+		for _, p2 := range points[i+1:] {
+			if asp := d.aspect(p1, p2, aspect.AspectType_Conjunction); asp != nil {
+				ret = math.Min(ret, asp.Deviation)
+			}
+		}
+	}
+	if ret == math.MaxFloat64 {
+		return NoConjunctionOrb
+	}
+	return ret
+}
+
+// pointsKey returns a key that's the same for the same set of points,
+// regardless of their order
+func pointsKey(points []pointid.PointID) string {
+	s := []string{}
+	for _, p := range points {
+		s = append(s, p.String())
+	}
+	slices.Sort(s)
+	return strings.Join(s, ",")
+}
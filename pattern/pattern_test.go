@@ -0,0 +1,275 @@
+package pattern
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/aspect"
+	"github.com/afjoseph/sacredstar/astropoint"
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/afjoseph/sacredstar/zodiacalpos"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestChart builds a chart by hand from the longitudes of its points.
+// Houses are counted from Aries but the ascendant itself is left out so it
+// doesn't take part in the patterns
+func newTestChart(longitudes map[pointid.PointID]float64) *chart.Chart {
+	cfg := aspect.NewModernConfig()
+	points := []*astropoint.AstroPoint{}
+	// Iterate in a fixed order so results are deterministic
+	for _, pid := range []pointid.PointID{
+		pointid.Sun, pointid.Moon, pointid.Mercury, pointid.Venus,
+		pointid.Mars, pointid.Jupiter, pointid.Saturn, pointid.Uranus,
+		pointid.Neptune, pointid.Pluto,
+	} {
+		lon, ok := longitudes[pid]
+		if !ok {
+			continue
+		}
+		zp := zodiacalpos.NewZodiacalPosFromLongitude(lon)
+		points = append(points, &astropoint.AstroPoint{
+			ID:          pid,
+			Longitude:   lon,
+			ZodiacalPos: zp,
+			House:       house.NewHouseFromSign(zp.Sign, sign.Aries),
+		})
+	}
+	aspects := []*aspect.Aspect{}
+	for i, p1 := range points {
+		for _, p2 := range points[i+1:] {
+			asp := aspect.NewAspect(p1.ID, p1.ZodiacalPos, p2.ID, p2.ZodiacalPos, cfg)
+			if asp == nil || asp.Type == aspect.AspectType_None {
+				continue
+			}
+			aspects = append(aspects, asp)
+		}
+	}
+	return &chart.Chart{
+		ChartType: chart.TropicalChartType,
+		Points:    points,
+		Aspects:   aspects,
+	}
+}
+
+func TestDetect(t *testing.T) {
+	type testCase struct {
+		title       string
+		longitudes  map[pointid.PointID]float64
+		patternType PatternType
+		expected    []*Pattern
+	}
+	for _, tc := range []testCase{
+		{
+			title: "grand trine",
+			longitudes: map[pointid.PointID]float64{
+				pointid.Sun:     15,
+				pointid.Moon:    137,
+				pointid.Jupiter: 255,
+			},
+			patternType: PatternType_GrandTrine,
+			expected: []*Pattern{{
+				Type:        PatternType_GrandTrine,
+				Points:      []pointid.PointID{pointid.Sun, pointid.Moon, pointid.Jupiter},
+				Focus:       pointid.None,
+				TightestOrb: 0,
+			}},
+		},
+		{
+			title: "t-square",
+			longitudes: map[pointid.PointID]float64{
+				pointid.Mars:   100,
+				pointid.Saturn: 282,
+				pointid.Venus:  190,
+			},
+			patternType: PatternType_TSquare,
+			expected: []*Pattern{{
+				Type:        PatternType_TSquare,
+				Points:      []pointid.PointID{pointid.Mars, pointid.Saturn, pointid.Venus},
+				Focus:       pointid.Venus,
+				TightestOrb: 0,
+			}},
+		},
+		{
+			title: "grand cross",
+			longitudes: map[pointid.PointID]float64{
+				pointid.Sun:     10,
+				pointid.Moon:    101,
+				pointid.Mars:    190,
+				pointid.Jupiter: 281,
+			},
+			patternType: PatternType_GrandCross,
+			expected: []*Pattern{{
+				Type: PatternType_GrandCross,
+				Points: []pointid.PointID{
+					pointid.Sun, pointid.Moon, pointid.Mars, pointid.Jupiter,
+				},
+				Focus:       pointid.None,
+				TightestOrb: 0,
+			}},
+		},
+		{
+			title: "yod",
+			longitudes: map[pointid.PointID]float64{
+				pointid.Venus:  10,
+				pointid.Saturn: 71,
+				pointid.Pluto:  220,
+			},
+			patternType: PatternType_Yod,
+			expected: []*Pattern{{
+				Type:        PatternType_Yod,
+				Points:      []pointid.PointID{pointid.Venus, pointid.Saturn, pointid.Pluto},
+				Focus:       pointid.Pluto,
+				TightestOrb: 0,
+			}},
+		},
+		{
+			title: "kite",
+			longitudes: map[pointid.PointID]float64{
+				pointid.Sun:     0.5,
+				pointid.Moon:    120.5,
+				pointid.Jupiter: 240.5,
+				pointid.Saturn:  182,
+			},
+			patternType: PatternType_Kite,
+			expected: []*Pattern{{
+				Type: PatternType_Kite,
+				Points: []pointid.PointID{
+					pointid.Sun, pointid.Moon, pointid.Jupiter, pointid.Saturn,
+				},
+				Focus:       pointid.Saturn,
+				TightestOrb: 0,
+			}},
+		},
+		{
+			title: "mystic rectangle",
+			longitudes: map[pointid.PointID]float64{
+				pointid.Sun:     10,
+				pointid.Moon:    70,
+				pointid.Mars:    190,
+				pointid.Jupiter: 250,
+			},
+			patternType: PatternType_MysticRectangle,
+			expected: []*Pattern{{
+				Type: PatternType_MysticRectangle,
+				Points: []pointid.PointID{
+					pointid.Sun, pointid.Moon, pointid.Mars, pointid.Jupiter,
+				},
+				Focus:       pointid.None,
+				TightestOrb: 0,
+			}},
+		},
+		{
+			title: "grand sextile",
+			longitudes: map[pointid.PointID]float64{
+				pointid.Sun:     5,
+				pointid.Moon:    65,
+				pointid.Mercury: 125,
+				pointid.Venus:   185,
+				pointid.Mars:    245,
+				pointid.Jupiter: 305,
+			},
+			patternType: PatternType_GrandSextile,
+			expected: []*Pattern{{
+				Type: PatternType_GrandSextile,
+				Points: []pointid.PointID{
+					pointid.Sun, pointid.Mercury, pointid.Mars,
+					pointid.Moon, pointid.Venus, pointid.Jupiter,
+				},
+				Focus:       pointid.None,
+				TightestOrb: 0,
+			}},
+		},
+		{
+			title: "stellium by sign",
+			longitudes: map[pointid.PointID]float64{
+				pointid.Sun:     32,
+				pointid.Mercury: 40,
+				pointid.Venus:   58,
+				pointid.Mars:    200,
+			},
+			patternType: PatternType_StelliumBySign,
+			expected: []*Pattern{{
+				Type: PatternType_StelliumBySign,
+				Points: []pointid.PointID{
+					pointid.Sun, pointid.Mercury, pointid.Venus,
+				},
+				Focus:       pointid.None,
+				TightestOrb: 8,
+				Sign:        sign.Taurus,
+				House:       house.HouseNone,
+			}},
+		},
+		{
+			title: "stellium without conjunctions",
+			longitudes: map[pointid.PointID]float64{
+				pointid.Sun:     30.5,
+				pointid.Mercury: 44.5,
+				pointid.Venus:   59.5,
+			},
+			patternType: PatternType_StelliumBySign,
+			expected: []*Pattern{{
+				Type: PatternType_StelliumBySign,
+				Points: []pointid.PointID{
+					pointid.Sun, pointid.Mercury, pointid.Venus,
+				},
+				Focus:       pointid.None,
+				TightestOrb: NoConjunctionOrb,
+				Sign:        sign.Taurus,
+				House:       house.HouseNone,
+			}},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			chrt := newTestChart(tc.longitudes)
+			actual := DetectType(chrt, tc.patternType)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestDetect_StelliumByHouse(t *testing.T) {
+	chrt := newTestChart(map[pointid.PointID]float64{
+		pointid.Sun:     32,
+		pointid.Mercury: 40,
+		pointid.Venus:   58,
+	})
+	actual := DetectType(chrt, PatternType_StelliumByHouse)
+	require.Len(t, actual, 1)
+	require.Equal(t, house.House2, actual[0].House)
+	require.Equal(t, 3, len(actual[0].Points))
+}
+
+func TestDetect_SpecialPoints(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+	pids := append([]pointid.PointID{}, pointid.VedicPlanets...)
+	pids = append(pids, pointid.SpecialLagnas...)
+	pids = append(pids, pointid.Upagrahas...)
+	chrt, err := chart.NewChartFromUTC(
+		swe,
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		// London coordinates
+		-0.1278, 51.5074,
+		chart.D1ChartType,
+		pids,
+	)
+	require.NoError(t, err)
+
+	// Hora lagna and Dhuma are in Aries with Jupiter, but they aren't
+	// bodies: there's no stellium
+	require.Empty(t, DetectType(chrt, PatternType_StelliumBySign))
+	require.Empty(t, DetectType(chrt, PatternType_StelliumByHouse))
+}
+
+func TestDetect_NoPatterns(t *testing.T) {
+	chrt := newTestChart(map[pointid.PointID]float64{
+		pointid.Sun:  10,
+		pointid.Moon: 47,
+	})
+	require.Empty(t, Detect(chrt))
+}
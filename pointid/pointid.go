@@ -44,6 +44,31 @@ func (p PointID) SwissEphID() int {
 	}
 }
 
+// IsLuminary reports whether p is the Sun or the Moon
+func (p PointID) IsLuminary() bool {
+	return p == Sun || p == Moon
+}
+
+// IsSpecialLagna reports whether p is one of SpecialLagnas
+func (p PointID) IsSpecialLagna() bool {
+	for _, sl := range SpecialLagnas {
+		if p == sl {
+			return true
+		}
+	}
+	return false
+}
+
+// IsUpagraha reports whether p is one of Upagrahas
+func (p PointID) IsUpagraha() bool {
+	for _, u := range Upagrahas {
+		if p == u {
+			return true
+		}
+	}
+	return false
+}
+
 func NewPointID(s string) (PointID, error) {
 	switch strings.ToLower(s) {
 	case "mercury":
@@ -72,9 +97,15 @@ func NewPointID(s string) (PointID, error) {
 		return Ketu, nil
 	case "rahu":
 		return Rahu, nil
-	default:
-		return None, fmt.Errorf("Unknown planet: %s", s)
 	}
+	for _, pids := range [][]PointID{SpecialLagnas, Upagrahas} {
+		for _, p := range pids {
+			if strings.ToLower(s) == p.String() {
+				return p, nil
+			}
+		}
+	}
+	return None, fmt.Errorf("Unknown planet: %s", s)
 }
 
 var TraditionalPlanets = []PointID{
@@ -112,6 +143,27 @@ var VedicPlanets = []PointID{
 	Ketu,
 }
 
+// SpecialLagnas are the special ascendants of Parashari and Jaimini astrology
+var SpecialLagnas = []PointID{
+	HoraLagna,
+	GhatiLagna,
+	BhavaLagna,
+	SreeLagna,
+	InduLagna,
+}
+
+// Upagrahas are the shadowy sub-planets: Gulika and Mandi come from
+// Saturn's part of the day or night, and the rest from the Sun
+var Upagrahas = []PointID{
+	Gulika,
+	Mandi,
+	Dhuma,
+	Vyatipata,
+	Parivesha,
+	Indrachapa,
+	Upaketu,
+}
+
 var (
 	ASC     = PointID("asc")
 	Sun     = PointID("sun")
@@ -127,5 +179,19 @@ var (
 	Rahu    = PointID("rahu") // North Node: equal to C.SE_TRUE_NODE
 	Ketu    = PointID("ketu")
 
+	HoraLagna  = PointID("hora-lagna")
+	GhatiLagna = PointID("ghati-lagna")
+	BhavaLagna = PointID("bhava-lagna")
+	SreeLagna  = PointID("sree-lagna")
+	InduLagna  = PointID("indu-lagna")
+
+	Gulika     = PointID("gulika")
+	Mandi      = PointID("mandi")
+	Dhuma      = PointID("dhuma")
+	Vyatipata  = PointID("vyatipata")
+	Parivesha  = PointID("parivesha")
+	Indrachapa = PointID("indrachapa")
+	Upaketu    = PointID("upaketu")
+
 	None = PointID("")
 )
@@ -93,14 +93,21 @@ func (e Event) analyze(
 	swe *wrapper.SwissEph,
 	birthTime time.Time,
 	lon, lat float64,
+	opts *Options,
 ) (*EventAnalysis, error) {
+	if opts == nil {
+		opts = NewDefaultOptions()
+	}
 	birthTimeInJulian := swe.GoTimeToJulianDay(birthTime.UTC())
-	rashiChart, err := chart.NewChartFromJulianDay(
+	rashiChartConfig := chart.NewDefaultConfig()
+	rashiChartConfig.BhavaSystem = opts.BhavaSystem
+	rashiChart, err := chart.NewChartFromJulianDayWithConfig(
 		swe,
 		birthTimeInJulian,
 		lon, lat,
 		chart.D1ChartType,
 		pointid.VedicPlanets,
+		rashiChartConfig,
 	)
 	if err != nil {
 		return nil, fmt.Errorf(
@@ -109,17 +116,10 @@ func (e Event) analyze(
 			err,
 		)
 	}
-	moonAstroPoint := rashiChart.GetPoint(pointid.Moon)
-	if moonAstroPoint == nil {
-		return nil, fmt.Errorf(
-			"moon not found in rashi chart for birth time %v",
-			birthTime,
-		)
-	}
 	dashaTree, err := chart.NewDashaTree(
 		swe,
 		birthTime,
-		moonAstroPoint,
+		rashiChart,
 	)
 	if err != nil {
 		return nil, fmt.Errorf(
@@ -229,6 +229,41 @@ func (e Event) analyze(
 		}
 
 		impHouseLord := vargaChart.GetPoint(impHouseLordID)
+		if opts.UseDrishti {
+			if d := vargaChart.GetDrishti(
+				dashaLord.ID,
+				impHouseLordID,
+				opts.NodeDrishti,
+			); d != nil {
+				reasons = append(reasons, NewReason(
+					ReasonType_HouseLordDrishti,
+					fmt.Sprintf(
+						"Dasha %s lord is aspecting lord of an important house (%s %s house lord) with drishti (%s)",
+						dasha.Mahadasha,
+						impHouseLord,
+						impHouse,
+						d,
+					),
+				))
+			}
+			if d := vargaChart.GetDrishti(
+				subdashaLord.ID,
+				impHouseLordID,
+				opts.NodeDrishti,
+			); d != nil {
+				reasons = append(reasons, NewReason(
+					ReasonType_HouseLordDrishti,
+					fmt.Sprintf(
+						"Subdasha %s lord is aspecting lord of an important house (%s %s house lord) with drishti (%s)",
+						dasha.Antardasha,
+						impHouseLord,
+						impHouse,
+						d,
+					),
+				))
+			}
+			continue
+		}
 		if asp := dashaLord.GetAspect(impHouseLord); asp != nil {
 			if asp.IsHard() {
 				reasons = append(reasons, NewReason(
@@ -282,6 +317,39 @@ func (e Event) analyze(
 
 	// Rule 3: The karaka is aspecting a dasha or subdasha lord
 	for _, kl := range karakaLords {
+		if opts.UseDrishti {
+			if d := vargaChart.GetDrishti(
+				dashaLord.ID,
+				kl.ID,
+				opts.NodeDrishti,
+			); d != nil {
+				reasons = append(reasons, NewReason(
+					ReasonType_KarakaDrishti,
+					fmt.Sprintf(
+						"Dasha %s lord is aspecting the karaka lord %s with drishti (%s)",
+						dasha.Mahadasha,
+						kl,
+						d,
+					),
+				))
+			}
+			if d := vargaChart.GetDrishti(
+				subdashaLord.ID,
+				kl.ID,
+				opts.NodeDrishti,
+			); d != nil {
+				reasons = append(reasons, NewReason(
+					ReasonType_KarakaDrishti,
+					fmt.Sprintf(
+						"Subdasha %s lord is aspecting the karaka lord %s with drishti (%s)",
+						dasha.Antardasha,
+						kl,
+						d,
+					),
+				))
+			}
+			continue
+		}
 		if asp := dashaLord.GetAspect(kl); asp != nil {
 			if asp.IsHard() {
 				reasons = append(reasons, NewReason(
@@ -344,8 +412,12 @@ func (e Event) analyze(
 	}
 
 	// Rule 5: The Rashi chart house lord becomes a dasha or sub-dasha lord
+	getHouseLord := rashiChart.GetHouseLordFor
+	if opts.BhavaSystem != chart.BhavaSystem_None {
+		getHouseLord = rashiChart.GetBhavaLordFor
+	}
 	for _, impHouse := range impHouses {
-		rashiChartImpHouseLord, err := rashiChart.GetHouseLordFor(
+		rashiChartImpHouseLord, err := getHouseLord(
 			impHouse,
 			chart.HouseLordPlacement_Traditional,
 		)
@@ -0,0 +1,97 @@
+package rectification
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/timeandzone"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventAnalyze_UseDrishti(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+
+	birthTime := time.Date(1992, 6, 13, 4, 40, 0, 0, time.UTC)
+	// Syria coordinates
+	lon, lat := 36.3, 33.5
+	events := []Event{
+		{
+			EventType: EventType_Marriage,
+			Time:      timeandzone.New(time.Date(2018, 5, 1, 0, 0, 0, 0, time.UTC)),
+		},
+		{
+			EventType: EventType_Career,
+			Time:      timeandzone.New(time.Date(2015, 9, 1, 0, 0, 0, 0, time.UTC)),
+		},
+		{
+			EventType: EventType_ChildBirth,
+			Time:      timeandzone.New(time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)),
+		},
+	}
+
+	sawDrishti := false
+	for _, e := range events {
+		ea, err := e.analyze(swe, birthTime, lon, lat, &Options{
+			UseDrishti:  true,
+			NodeDrishti: chart.NodeDrishti_Jupiter,
+		})
+		require.NoError(t, err)
+		for _, r := range ea.Reasons {
+			// Western aspects are replaced, not added to
+			require.NotEqual(t, ReasonType_HouseLordAspect_Hard, r.Type)
+			require.NotEqual(t, ReasonType_KarakaAspect_Hard, r.Type)
+			if r.Type == ReasonType_HouseLordDrishti ||
+				r.Type == ReasonType_KarakaDrishti {
+				sawDrishti = true
+			}
+		}
+
+		// The default options are the same as passing nil
+		withNil, err := e.analyze(swe, birthTime, lon, lat, nil)
+		require.NoError(t, err)
+		withDefault, err := e.analyze(swe, birthTime, lon, lat, NewDefaultOptions())
+		require.NoError(t, err)
+		require.Equal(t, withNil.Reasons, withDefault.Reasons)
+	}
+	require.True(t, sawDrishti)
+}
+
+func TestEventAnalyze_BhavaSystem(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+
+	// The ascendant is at Gemini 14°55'. The 10th house is Pisces (ruled by
+	// Jupiter) by whole signs, but the 10th Sripati bhava madhya is in
+	// Aquarius (ruled by Saturn)
+	birthTime := time.Date(1992, 6, 13, 3, 40, 0, 0, time.UTC)
+	// Syria coordinates
+	lon, lat := 36.3, 33.5
+	// Saturn/Rahu is running
+	e := Event{
+		EventType: EventType_Career,
+		Time:      timeandzone.New(time.Date(2005, 1, 1, 0, 0, 0, 0, time.UTC)),
+	}
+	rule5Reasons := func(opts *Options) []Reason {
+		ea, err := e.analyze(swe, birthTime, lon, lat, opts)
+		require.NoError(t, err)
+		require.Equal(t, chart.DashaLordSaturn, ea.ActiveDasha.Mahadasha)
+		ret := []Reason{}
+		for _, r := range ea.Reasons {
+			if r.Type == ReasonType_RashiChartHouseLord {
+				ret = append(ret, r)
+			}
+		}
+		return ret
+	}
+
+	require.Empty(t, rule5Reasons(nil))
+	opts := NewDefaultOptions()
+	opts.BhavaSystem = chart.BhavaSystem_Sripati
+	reasons := rule5Reasons(opts)
+	require.Len(t, reasons, 1)
+	require.Contains(t, reasons[0].Description, "Dasha lord Saturn")
+	require.Contains(t, reasons[0].Description, "10th")
+}
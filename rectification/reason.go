@@ -19,6 +19,8 @@ const (
 	ReasonType_Ascendant
 	ReasonType_RashiChartHouseLord
 	ReasonType_BhavatBhavam
+	ReasonType_HouseLordDrishti
+	ReasonType_KarakaDrishti
 )
 
 type Reason struct {
@@ -68,6 +70,10 @@ func (b ReasonType) String() string {
 		return "Rashi Chart House Lord"
 	case ReasonType_BhavatBhavam:
 		return "Bhavat Bhavam"
+	case ReasonType_HouseLordDrishti:
+		return "House Lord Drishti"
+	case ReasonType_KarakaDrishti:
+		return "Karaka Drishti"
 	default:
 		return "Unknown"
 	}
@@ -247,19 +247,65 @@ func (r *Rectification) Checksum() (string, error) {
 	return hex.EncodeToString(h32.Sum(nil)), nil
 }
 
-// NewRectification returns a new Rectification
+// Options tweak how events are analyzed during rectification
+type Options struct {
+	// UseDrishti makes the aspect rules (i.e., dasha lords aspecting house
+	// lords or karakas) use Vedic sign-based drishti instead of Western
+	// degree-based aspects
+	UseDrishti bool `json:"useDrishti"`
+	// NodeDrishti decides which aspects Rahu and Ketu cast. Only used if
+	// UseDrishti is set
+	NodeDrishti chart.NodeDrishti `json:"nodeDrishti"`
+	// BhavaSystem, if set, makes the Rashi chart house lords (i.e., the
+	// lords of the important houses in the D1 chart) the lords of the Bhava
+	// Chalit bhavas in that system, instead of the lords of whole signs
+	BhavaSystem chart.BhavaSystem `json:"bhavaSystem"`
+}
+
+// NewDefaultOptions returns the options NewRectification uses
+func NewDefaultOptions() *Options {
+	return &Options{
+		UseDrishti:  false,
+		NodeDrishti: chart.NodeDrishti_Jupiter,
+		BhavaSystem: chart.BhavaSystem_None,
+	}
+}
+
+// NewRectification returns a new Rectification with the default options.
+// See NewRectificationWithOptions.
+func NewRectification(
+	swe *wrapper.SwissEph,
+	birthTime time.Time,
+	lon, lat float64,
+	_range time.Duration,
+	step time.Duration,
+	events []Event,
+) (*Rectification, error) {
+	return NewRectificationWithOptions(
+		swe,
+		birthTime,
+		lon, lat,
+		_range,
+		step,
+		events,
+		NewDefaultOptions(),
+	)
+}
+
+// NewRectificationWithOptions returns a new Rectification
 // The function loops over each step in the range and analyzes the birth time.
 // It calculates a checksum per event analysis and checks if the checksum for a
 // time interval is the same. If it is, it extends the interval. If it isn't,
 // it creates a new IntervalAnalysis with the data we've collected from
 // the previous iterations and starts a new interval.
-func NewRectification(
+func NewRectificationWithOptions(
 	swe *wrapper.SwissEph,
 	birthTime time.Time,
 	lon, lat float64,
 	_range time.Duration,
 	step time.Duration,
 	events []Event,
+	opts *Options,
 ) (*Rectification, error) {
 	// Every 'step' minutes, starting from '_range' minutes before and after
 	// the birth time
@@ -274,7 +320,7 @@ func NewRectification(
 		totalScore := 0
 		analyses := []*EventAnalysis{}
 		for _, e := range events {
-			ret, err := e.analyze(swe, t, lon, lat)
+			ret, err := e.analyze(swe, t, lon, lat, opts)
 			if err != nil {
 				return nil, fmt.Errorf(
 					"while analyzing birth time %v: %w",
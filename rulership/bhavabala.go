@@ -0,0 +1,138 @@
+package rulership
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+)
+
+// BhavaBala is the strength of a house, in virupas
+type BhavaBala struct {
+	House house.House     `json:"house"`
+	Sign  sign.Sign       `json:"sign"`
+	Lord  pointid.PointID `json:"lord"`
+	// Adhipati is the Shadbala of the lord of the house
+	Adhipati float64 `json:"adhipati"`
+	// Dig is the directional strength of the house's sign
+	Dig float64 `json:"dig"`
+	// Drishti is the strength from the aspects the middle of the house
+	// receives
+	Drishti float64 `json:"drishti"`
+	Total   float64 `json:"total"`
+}
+
+func (bb *BhavaBala) String() string {
+	return fmt.Sprintf(
+		"BhavaBala{House: %s, Sign: %s, Lord: %s, Adhipati: %f, Dig: %f, Drishti: %f, Rupas: %f}",
+		bb.House,
+		bb.Sign,
+		bb.Lord,
+		bb.Adhipati,
+		bb.Dig,
+		bb.Drishti,
+		bb.Rupas(),
+	)
+}
+
+// Rupas returns Total in rupas
+func (bb *BhavaBala) Rupas() float64 {
+	return bb.Total / VirupasPerRupa
+}
+
+// CalculateBhavaBala returns the strength of the 12 houses of d1, in order.
+// shadbalas is the output of CalculateShadbala for the same chart. The middle
+// of each house is the ascendant degree in the house's sign.
+func CalculateBhavaBala(
+	d1 *chart.Chart,
+	shadbalas []*Shadbala,
+) ([]*BhavaBala, error) {
+	shadbalaOf := map[pointid.PointID]*Shadbala{}
+	lons := map[pointid.PointID]float64{}
+	for _, sb := range shadbalas {
+		shadbalaOf[sb.Planet] = sb
+		p := d1.GetPoint(sb.Planet)
+		if p == nil {
+			return nil, fmt.Errorf("%s not found in chart", sb.Planet)
+		}
+		lons[sb.Planet] = p.Longitude
+	}
+	asc := d1.MustGetPoint(pointid.ASC)
+
+	ret := []*BhavaBala{}
+	for i := 1; i <= 12; i++ {
+		h, err := house.HouseFromInt(i)
+		if err != nil {
+			return nil, err
+		}
+		s := d1.GetSignOfHouse(h)
+		lord := s.TraditionalRuler()
+		lordBala, didFind := shadbalaOf[lord]
+		if !didFind {
+			return nil, fmt.Errorf("no Shadbala for %s, the lord of the %s house", lord, h)
+		}
+		madhya := math.Mod(asc.Longitude+30*float64(i-1), 360)
+
+		// Dig: 60 virupas in the house where the sign is strongest, and 10
+		// less for each house away from it
+		strongest := bhavaDigStrongestHouse(s, math.Mod(madhya, 30))
+		dist := (i - strongest + 12) % 12
+		dist = min(dist, 12-dist)
+
+		// Drishti: a quarter of the aspects from the benefics minus those
+		// from the malefics, but Jupiter and Mercury count in full
+		drishti := 0.0
+		for _, from := range ShadbalaPlanets {
+			v := chart.DrishtiVirupas(from, lons[from], madhya)
+			switch {
+			case from == pointid.Jupiter || from == pointid.Mercury:
+				drishti += v
+			case isShadbalaBenefic(from, lons):
+				drishti += v / 4
+			default:
+				drishti -= v / 4
+			}
+		}
+
+		bb := &BhavaBala{
+			House:    h,
+			Sign:     s,
+			Lord:     lord,
+			Adhipati: lordBala.Total,
+			Dig:      60 - 10*float64(dist),
+			Drishti:  drishti,
+		}
+		bb.Total = bb.Adhipati + bb.Dig + bb.Drishti
+		ret = append(ret, bb)
+	}
+	return ret, nil
+}
+
+// bhavaDigStrongestHouse returns the house a sign is strongest in: human
+// signs in the 1st, watery signs in the 4th, Scorpio in the 7th and
+// quadrupeds in the 10th. Sagittarius and Capricorn change nature halfway.
+func bhavaDigStrongestHouse(s sign.Sign, signDeg float64) int {
+	switch s {
+	case sign.Gemini, sign.Virgo, sign.Libra, sign.Aquarius:
+		return 1
+	case sign.Cancer, sign.Pisces:
+		return 4
+	case sign.Scorpio:
+		return 7
+	case sign.Sagittarius:
+		if signDeg < 15 {
+			return 1
+		}
+		return 10
+	case sign.Capricorn:
+		if signDeg < 15 {
+			return 10
+		}
+		return 4
+	}
+	// Aries, Taurus and Leo
+	return 10
+}
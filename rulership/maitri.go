@@ -0,0 +1,74 @@
+package rulership
+
+import (
+	"fmt"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/pointid"
+)
+
+// Maitri is the relationship of a planet with another one
+type Maitri struct {
+	Planet pointid.PointID `json:"planet"`
+	Other  pointid.PointID `json:"other"`
+	// Natural is how Planet sees Other by nature (naisargika)
+	Natural chart.Relationship `json:"natural"`
+	// Temporal is from their signs in the chart (tatkalika)
+	Temporal chart.Relationship `json:"temporal"`
+	// Panchadha is the five-fold relationship: Natural and Temporal combined
+	Panchadha chart.Relationship `json:"panchadha"`
+}
+
+func (m *Maitri) String() string {
+	return fmt.Sprintf(
+		"Maitri{Planet: %s, Other: %s, Natural: %s, Temporal: %s, Panchadha: %s}",
+		m.Planet,
+		m.Other,
+		m.Natural,
+		m.Temporal,
+		m.Panchadha,
+	)
+}
+
+// MaitriTable has the relationships of every planet of ShadbalaPlanets with
+// every other one in a chart
+type MaitriTable []*Maitri
+
+// Get returns the relationship of pid with other, or nil if either isn't one
+// of ShadbalaPlanets or they're the same
+func (mt MaitriTable) Get(pid, other pointid.PointID) *Maitri {
+	for _, m := range mt {
+		if m.Planet == pid && m.Other == other {
+			return m
+		}
+	}
+	return nil
+}
+
+// CalculateMaitri returns the relationships of the seven planets in chrt, for
+// every ordered pair of different planets. The temporal relationships come
+// from the signs of chrt, so a varga chart gives the relationships in that
+// varga.
+func CalculateMaitri(chrt *chart.Chart) (MaitriTable, error) {
+	ret := MaitriTable{}
+	for _, pid := range ShadbalaPlanets {
+		for _, other := range ShadbalaPlanets {
+			if pid == other {
+				continue
+			}
+			temporal, err := chrt.TemporalRelationship(pid, other)
+			if err != nil {
+				return nil, fmt.Errorf("while getting temporal relationship: %v", err)
+			}
+			natural := chart.NaturalRelationship(pid, other)
+			ret = append(ret, &Maitri{
+				Planet:    pid,
+				Other:     other,
+				Natural:   natural,
+				Temporal:  temporal,
+				Panchadha: chart.CombineRelationships(natural, temporal),
+			})
+		}
+	}
+	return ret, nil
+}
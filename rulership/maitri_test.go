@@ -0,0 +1,39 @@
+package rulership
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateMaitri(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+	d1, err := chart.NewChartFromUTC(
+		swe,
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		-0.1278, 51.5074,
+		chart.D1ChartType,
+		pointid.VedicPlanets,
+	)
+	require.NoError(t, err)
+
+	table, err := CalculateMaitri(d1)
+	require.NoError(t, err)
+	require.Len(t, table, 7*6)
+	require.Nil(t, table.Get(pointid.Sun, pointid.Sun))
+	// Jupiter in Aries is a natural enemy of Venus, which is 8th from it in
+	// Scorpio
+	m := table.Get(pointid.Jupiter, pointid.Venus)
+	require.Equal(t, chart.Relationship_Enemy, m.Natural)
+	require.Equal(t, chart.Relationship_Enemy, m.Temporal)
+	require.Equal(t, chart.Relationship_GreatEnemy, m.Panchadha)
+	for _, m := range table {
+		require.Equal(t, m.Temporal, table.Get(m.Other, m.Planet).Temporal)
+		require.Equal(t, m.Natural+m.Temporal, m.Panchadha)
+	}
+}
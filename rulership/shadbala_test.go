@@ -0,0 +1,141 @@
+package rulership
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShadbala(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+	// London coordinates
+	lon, lat := -0.1278, 51.5074
+	d1, err := chart.NewChartFromUTC(
+		swe,
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		lon, lat,
+		chart.D1ChartType,
+		pointid.VedicPlanets,
+	)
+	require.NoError(t, err)
+
+	shadbalas, err := CalculateShadbala(swe, d1, lon, lat)
+	require.NoError(t, err)
+	require.Len(t, shadbalas, len(ShadbalaPlanets))
+	balaOf := map[pointid.PointID]*Shadbala{}
+	for _, sb := range shadbalas {
+		balaOf[sb.Planet] = sb
+		require.InDelta(t,
+			sb.Sthana.Total()+sb.Dig+sb.Kala.Total()+sb.Cheshta+sb.Naisargika+sb.Drik,
+			sb.Total,
+			1e-9,
+		)
+		require.InDelta(t, sb.Total/60, sb.Rupas(), 1e-9)
+		require.True(t, sb.IshtaPhala >= 0 && sb.IshtaPhala <= 60, sb.String())
+		require.True(t, sb.KashtaPhala >= 0 && sb.KashtaPhala <= 60, sb.String())
+	}
+
+	sun := balaOf[pointid.Sun]
+	// The Sun is at Sagittarius 15°50, 65°50' from its debilitation point
+	// (Libra 10°)
+	require.InDelta(t, 21.95, sun.Sthana.Uchcha, 0.01)
+	// It's in the 4th house, a kendra
+	require.Equal(t, 60.0, sun.Sthana.Kendradi)
+	// It's at the bottom of the chart at midnight, right where it has no
+	// directional strength
+	require.Less(t, sun.Dig, 1.0)
+	require.Equal(t, 60.0, sun.Naisargika)
+	// The Hindu day started at Sunday's sunrise
+	require.Equal(t, 45.0, sun.Kala.Vara)
+
+	// Saturn is in its moolatrikona in D1
+	require.GreaterOrEqual(t, balaOf[pointid.Saturn].Sthana.Saptavargaja, 45.0)
+	// The 16th hour from Sunday's sunrise is Venus's, and the middle third of
+	// the night is too
+	require.Equal(t, 60.0, balaOf[pointid.Venus].Kala.Hora)
+	require.Equal(t, 60.0, balaOf[pointid.Venus].Kala.Tribhaga)
+	// Jupiter always has tribhaga bala, and it was stationary
+	require.Equal(t, 60.0, balaOf[pointid.Jupiter].Kala.Tribhaga)
+	require.Equal(t, 15.0, balaOf[pointid.Jupiter].Cheshta)
+	// The Moon's cheshta bala is its (undoubled) paksha bala
+	moon := balaOf[pointid.Moon]
+	require.InDelta(t, moon.Kala.Paksha/2, moon.Cheshta, 1e-9)
+
+	// Only D1 charts
+	d9, err := chart.NewChartFromUTC(
+		swe,
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		lon, lat,
+		chart.D9ChartType,
+		pointid.VedicPlanets,
+	)
+	require.NoError(t, err)
+	_, err = CalculateShadbala(swe, d9, lon, lat)
+	require.Error(t, err)
+
+	// In another ayanamsa, the planets and the angles move together, so the
+	// directional strengths don't change
+	cfg := chart.NewDefaultConfig()
+	cfg.Ayanamsa = wrapper.Ayanamsa_Raman
+	raman, err := chart.NewChartFromUTCWithConfig(
+		swe,
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		lon, lat,
+		chart.D1ChartType,
+		pointid.VedicPlanets,
+		cfg,
+	)
+	require.NoError(t, err)
+	ramanBalas, err := CalculateShadbala(swe, raman, lon, lat)
+	require.NoError(t, err)
+	for i, sb := range ramanBalas {
+		require.InDelta(t, shadbalas[i].Dig, sb.Dig, 0.01, sb.Planet)
+	}
+
+	bhavas, err := CalculateBhavaBala(d1, shadbalas)
+	require.NoError(t, err)
+	require.Len(t, bhavas, 12)
+	first := bhavas[0]
+	require.Equal(t, house.House1, first.House)
+	require.Equal(t, sign.Virgo, first.Sign)
+	require.Equal(t, pointid.Mercury, first.Lord)
+	require.Equal(t, balaOf[pointid.Mercury].Total, first.Adhipati)
+	// Virgo is a human sign, strongest in the 1st house
+	require.Equal(t, 60.0, first.Dig)
+	// Pisces is watery, strongest in the 4th, 3 houses from the 7th
+	require.Equal(t, 30.0, bhavas[6].Dig)
+	for _, bb := range bhavas {
+		require.InDelta(t, bb.Adhipati+bb.Dig+bb.Drishti, bb.Total, 1e-9)
+	}
+
+	// Mercury and Venus are 16' apart: the winner of their war is the same
+	// as in their dignities
+	d1, err = chart.NewChartFromUTC(
+		swe,
+		time.Date(2000, 3, 16, 0, 0, 0, 0, time.UTC),
+		lon, lat,
+		chart.D1ChartType,
+		pointid.VedicPlanets,
+	)
+	require.NoError(t, err)
+	shadbalas, err = CalculateShadbala(swe, d1, lon, lat)
+	require.NoError(t, err)
+	for _, sb := range shadbalas {
+		balaOf[sb.Planet] = sb
+	}
+	mercury, err := d1.GetDignity(swe, pointid.Mercury)
+	require.NoError(t, err)
+	winner, loser := pointid.Mercury, pointid.Venus
+	if !mercury.IsWarWinner {
+		winner, loser = loser, winner
+	}
+	require.Greater(t, balaOf[winner].Kala.Yuddha, 0.0)
+	require.InDelta(t, -balaOf[winner].Kala.Yuddha, balaOf[loser].Kala.Yuddha, 1e-9)
+}
@@ -0,0 +1,213 @@
+package rulership
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+)
+
+// MaxVimshopaka is the highest Vimshopaka bala a planet can have, in any
+// varga group
+const MaxVimshopaka = 20.0
+
+// VargaGroup is a group of vargas Vimshopaka bala is computed over
+type VargaGroup string
+
+const (
+	VargaGroup_Shadvarga     VargaGroup = "shadvarga"
+	VargaGroup_Saptavarga    VargaGroup = "saptavarga"
+	VargaGroup_Dashavarga    VargaGroup = "dashavarga"
+	VargaGroup_Shodashavarga VargaGroup = "shodashavarga"
+)
+
+func (vg VargaGroup) String() string {
+	return string(vg)
+}
+
+// VargaWeight is the weight of a varga in a group
+type VargaWeight struct {
+	ChartType chart.ChartType `json:"chartType"`
+	Weight    float64         `json:"weight"`
+}
+
+// vargaWeights are the weights of the vargas of every group (BPHS 7). They
+// add up to MaxVimshopaka.
+var vargaWeights = map[VargaGroup][]VargaWeight{
+	VargaGroup_Shadvarga: {
+		{chart.D1ChartType, 6},
+		{chart.D2ChartType, 2},
+		{chart.D3ChartType, 4},
+		{chart.D9ChartType, 5},
+		{chart.D12ChartType, 2},
+		{chart.D30ChartType, 1},
+	},
+	VargaGroup_Saptavarga: {
+		{chart.D1ChartType, 5},
+		{chart.D2ChartType, 2},
+		{chart.D3ChartType, 3},
+		{chart.D7ChartType, 2.5},
+		{chart.D9ChartType, 4.5},
+		{chart.D12ChartType, 2},
+		{chart.D30ChartType, 1},
+	},
+	VargaGroup_Dashavarga: {
+		{chart.D1ChartType, 3},
+		{chart.D2ChartType, 1.5},
+		{chart.D3ChartType, 1.5},
+		{chart.D7ChartType, 1.5},
+		{chart.D9ChartType, 1.5},
+		{chart.D10ChartType, 1.5},
+		{chart.D12ChartType, 1.5},
+		{chart.D16ChartType, 1.5},
+		{chart.D30ChartType, 1.5},
+		{chart.D60ChartType, 5},
+	},
+	VargaGroup_Shodashavarga: {
+		{chart.D1ChartType, 3.5},
+		{chart.D2ChartType, 1},
+		{chart.D3ChartType, 1},
+		{chart.D4ChartType, 0.5},
+		{chart.D7ChartType, 0.5},
+		{chart.D9ChartType, 3},
+		{chart.D10ChartType, 0.5},
+		{chart.D12ChartType, 0.5},
+		{chart.D16ChartType, 2},
+		{chart.D20ChartType, 0.5},
+		{chart.D24ChartType, 0.5},
+		{chart.D27ChartType, 0.5},
+		{chart.D30ChartType, 1},
+		{chart.D40ChartType, 0.5},
+		{chart.D45ChartType, 0.5},
+		{chart.D60ChartType, 4},
+	},
+}
+
+// Weights returns the vargas of the group with their weights
+func (vg VargaGroup) Weights() []VargaWeight {
+	return slices.Clone(vargaWeights[vg])
+}
+
+// VargaDignity is the dignity of a planet in one varga
+type VargaDignity struct {
+	ChartType chart.ChartType `json:"chartType"`
+	Sign      sign.Sign       `json:"sign"`
+	Lord      pointid.PointID `json:"lord"`
+	// IsOwn is true if the planet is in its own or exaltation sign
+	IsOwn bool `json:"isOwn"`
+	// Relationship is the five-fold relationship of the planet with Lord, if
+	// it's not in its own sign
+	Relationship chart.Relationship `json:"relationship"`
+	// Points are from 5 (great enemy's sign) to 20 (own sign)
+	Points float64 `json:"points"`
+	Weight float64 `json:"weight"`
+}
+
+// Vimshopaka is the Vimshopaka bala of a planet in a varga group
+type Vimshopaka struct {
+	Planet    pointid.PointID `json:"planet"`
+	Group     VargaGroup      `json:"group"`
+	Dignities []VargaDignity  `json:"dignities"`
+	// Score is out of MaxVimshopaka
+	Score float64 `json:"score"`
+}
+
+func (v *Vimshopaka) String() string {
+	return fmt.Sprintf(
+		"Vimshopaka{Planet: %s, Group: %s, Score: %f}",
+		v.Planet,
+		v.Group,
+		v.Score,
+	)
+}
+
+// dignityPoints returns the points of a planet in a sign it has rel with the
+// lord of
+func dignityPoints(rel chart.Relationship) float64 {
+	switch rel {
+	case chart.Relationship_GreatFriend:
+		return 18
+	case chart.Relationship_Friend:
+		return 15
+	case chart.Relationship_Neutral:
+		return 10
+	case chart.Relationship_Enemy:
+		return 7
+	}
+	return 5
+}
+
+// CalculateVimshopaka returns the Vimshopaka bala of ShadbalaPlanets in
+// group, in that order. d1 must be a D1 chart with all of them. The
+// relationships with the lords of the varga signs are the five-fold ones,
+// with the temporal part from d1.
+func CalculateVimshopaka(
+	d1 *chart.Chart,
+	group VargaGroup,
+) ([]*Vimshopaka, error) {
+	if d1.ChartType != chart.D1ChartType {
+		return nil, fmt.Errorf("Vimshopaka needs a D1 chart, got %s", d1.ChartType)
+	}
+	weights, ok := vargaWeights[group]
+	if !ok {
+		return nil, fmt.Errorf("unknown varga group %s", group)
+	}
+	for _, pid := range ShadbalaPlanets {
+		if d1.GetPoint(pid) == nil {
+			return nil, fmt.Errorf("%s not found in chart", pid)
+		}
+	}
+	ret := []*Vimshopaka{}
+	for _, pid := range ShadbalaPlanets {
+		v := &Vimshopaka{
+			Planet:    pid,
+			Group:     group,
+			Dignities: []VargaDignity{},
+		}
+		for _, w := range weights {
+			zp, err := d1.GetVargaPos(pid, w.ChartType)
+			if err != nil {
+				return nil, fmt.Errorf("while getting %s position of %s: %v", w.ChartType, pid, err)
+			}
+			d := VargaDignity{
+				ChartType: w.ChartType,
+				Sign:      zp.Sign,
+				Lord:      zp.Sign.TraditionalRuler(),
+				Weight:    w.Weight,
+			}
+			exalted, _ := chart.ExaltationSign(pid)
+			if d.Lord == pid || exalted == zp.Sign {
+				d.IsOwn = true
+				d.Points = 20
+			} else {
+				// The temporal relationship is the same in every varga
+				d.Relationship, err = d1.PanchadhaRelationship(pid, d.Lord)
+				if err != nil {
+					return nil, fmt.Errorf("while getting relationship of %s with %s: %v", pid, d.Lord, err)
+				}
+				d.Points = dignityPoints(d.Relationship)
+			}
+			v.Score += d.Points * d.Weight / MaxVimshopaka
+			v.Dignities = append(v.Dignities, d)
+		}
+		ret = append(ret, v)
+	}
+	return ret, nil
+}
+
+// RankVimshopaka returns vs sorted from the strongest planet to the weakest
+func RankVimshopaka(vs []*Vimshopaka) []*Vimshopaka {
+	ret := slices.Clone(vs)
+	slices.SortStableFunc(ret, func(a, b *Vimshopaka) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return 0
+	})
+	return ret
+}
@@ -0,0 +1,69 @@
+package rulership
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateVimshopaka(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+	d1, err := chart.NewChartFromUTC(
+		swe,
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		-0.1278, 51.5074,
+		chart.D1ChartType,
+		pointid.VedicPlanets,
+	)
+	require.NoError(t, err)
+
+	for _, group := range []VargaGroup{
+		VargaGroup_Shadvarga,
+		VargaGroup_Saptavarga,
+		VargaGroup_Dashavarga,
+		VargaGroup_Shodashavarga,
+	} {
+		total := 0.0
+		for _, w := range group.Weights() {
+			total += w.Weight
+		}
+		require.Equal(t, MaxVimshopaka, total, group)
+
+		vs, err := CalculateVimshopaka(d1, group)
+		require.NoError(t, err)
+		require.Len(t, vs, len(ShadbalaPlanets))
+		for _, v := range vs {
+			require.Len(t, v.Dignities, len(group.Weights()))
+			require.Greater(t, v.Score, 0.0)
+			require.LessOrEqual(t, v.Score, MaxVimshopaka)
+		}
+		// Saturn, in its own sign in D1, is the strongest in every group
+		require.Equal(t, pointid.Saturn, RankVimshopaka(vs)[0].Planet, group)
+	}
+
+	vs, err := CalculateVimshopaka(d1, VargaGroup_Shadvarga)
+	require.NoError(t, err)
+	saturn := vs[6]
+	require.Equal(t, pointid.Saturn, saturn.Planet)
+	require.InDelta(t, 17.55, saturn.Score, 1e-9)
+	// Saturn in Aquarius in D1
+	require.Equal(t, chart.D1ChartType, saturn.Dignities[0].ChartType)
+	require.True(t, saturn.Dignities[0].IsOwn)
+	require.Equal(t, 20.0, saturn.Dignities[0].Points)
+	// The Sun in Sagittarius in D1: Jupiter is its friend by nature but, in
+	// Aries, 5th from it, so a temporal enemy
+	sun := vs[0]
+	require.Equal(t, sign.Sagittarius, sun.Dignities[0].Sign)
+	require.Equal(t, pointid.Jupiter, sun.Dignities[0].Lord)
+	require.Equal(t, chart.Relationship_Neutral, sun.Dignities[0].Relationship)
+	require.Equal(t, 10.0, sun.Dignities[0].Points)
+
+	_, err = CalculateVimshopaka(d1, VargaGroup("unknown"))
+	require.Error(t, err)
+}
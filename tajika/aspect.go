@@ -0,0 +1,209 @@
+package tajika
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+)
+
+// AspectType is a Tajika aspect. Aspects are between signs: their strength
+// comes from the degrees of the planets in them.
+type AspectType string
+
+const (
+	AspectType_None        AspectType = "none"
+	AspectType_Conjunction AspectType = "conjunction"
+	// 3rd and 11th signs: secretly friendly
+	AspectType_Sextile AspectType = "sextile"
+	// 4th and 10th signs: secretly inimical
+	AspectType_Square AspectType = "square"
+	// 5th and 9th signs: openly friendly
+	AspectType_Trine AspectType = "trine"
+	// 7th sign: openly inimical
+	AspectType_Opposition AspectType = "opposition"
+)
+
+func (at AspectType) String() string {
+	return string(at)
+}
+
+func (at AspectType) IsFriendly() bool {
+	return at == AspectType_Sextile || at == AspectType_Trine
+}
+
+func (at AspectType) IsInimical() bool {
+	return at == AspectType_Square || at == AspectType_Opposition
+}
+
+// NewAspectTypeFromHouse returns the aspect between a sign and the sign h
+// houses from it. The 2nd, 6th, 8th and 12th have none.
+func NewAspectTypeFromHouse(h house.House) AspectType {
+	switch h.Int() {
+	case 1:
+		return AspectType_Conjunction
+	case 3, 11:
+		return AspectType_Sextile
+	case 4, 10:
+		return AspectType_Square
+	case 5, 9:
+		return AspectType_Trine
+	case 7:
+		return AspectType_Opposition
+	}
+	return AspectType_None
+}
+
+// NewAspectTypeFromSigns returns the aspect between planets in s1 and s2
+func NewAspectTypeFromSigns(s1, s2 sign.Sign) AspectType {
+	return NewAspectTypeFromHouse(house.NewHouseFromSign(s2, s1))
+}
+
+// deeptamsas are the orbs of light of the planets
+var deeptamsas = map[pointid.PointID]float64{
+	pointid.Sun:     15,
+	pointid.Moon:    12,
+	pointid.Mars:    8,
+	pointid.Mercury: 7,
+	pointid.Jupiter: 9,
+	pointid.Venus:   7,
+	pointid.Saturn:  9,
+}
+
+// Deeptamsa returns the orb of light of pid
+func Deeptamsa(pid pointid.PointID) float64 {
+	return deeptamsas[pid]
+}
+
+// speedOrder are the planets from the fastest to the slowest. Tajika uses
+// their mean speeds, not the ones at the time of the chart.
+var speedOrder = []pointid.PointID{
+	pointid.Moon,
+	pointid.Mercury,
+	pointid.Venus,
+	pointid.Sun,
+	pointid.Mars,
+	pointid.Jupiter,
+	pointid.Saturn,
+}
+
+// IsFaster reports whether pid is faster than other
+func IsFaster(pid, other pointid.PointID) bool {
+	return slices.Index(speedOrder, pid) < slices.Index(speedOrder, other)
+}
+
+// Aspect is a Tajika aspect between two planets of a chart
+type Aspect struct {
+	// Faster and Slower are the two planets, by mean speed
+	Faster pointid.PointID `json:"faster"`
+	Slower pointid.PointID `json:"slower"`
+	Type   AspectType      `json:"type"`
+	// Orb is the mean of the deeptamsas of the two planets
+	Orb float64 `json:"orb"`
+	// Distance is how far apart the two planets are in their signs, in
+	// degrees. It's positive when Faster is behind Slower.
+	Distance float64 `json:"distance"`
+}
+
+func (a *Aspect) String() string {
+	return fmt.Sprintf(
+		"Aspect{Faster: %s, Slower: %s, Type: %s, Orb: %f, Distance: %f}",
+		a.Faster,
+		a.Slower,
+		a.Type,
+		a.Orb,
+		a.Distance,
+	)
+}
+
+// Has reports whether pid is one of the planets of the aspect
+func (a *Aspect) Has(pid pointid.PointID) bool {
+	return a.Faster == pid || a.Slower == pid
+}
+
+// Other returns the planet of the aspect that's not pid
+func (a *Aspect) Other(pid pointid.PointID) pointid.PointID {
+	if a.Faster == pid {
+		return a.Slower
+	}
+	return a.Faster
+}
+
+// IsWithinOrb reports whether the planets are within their orbs
+func (a *Aspect) IsWithinOrb() bool {
+	return math.Abs(a.Distance) <= a.Orb
+}
+
+// IsIthasala reports whether the aspect is applying within orbs: the faster
+// planet is behind the slower one
+func (a *Aspect) IsIthasala() bool {
+	return a.IsWithinOrb() && a.Distance >= 0
+}
+
+// IsPoornaIthasala reports whether the aspect is an Ithasala within a degree
+func (a *Aspect) IsPoornaIthasala() bool {
+	return a.IsIthasala() && a.Distance <= 1
+}
+
+// IsIshrafa reports whether the aspect is separating within orbs: the
+// faster planet has gone past the slower one
+func (a *Aspect) IsIshrafa() bool {
+	return a.IsWithinOrb() && a.Distance < 0
+}
+
+// NewAspect returns the Tajika aspect between pid and other in c, or nil if
+// their signs don't aspect each other
+func NewAspect(c *chart.Chart, pid, other pointid.PointID) *Aspect {
+	p, o := c.GetPoint(pid), c.GetPoint(other)
+	if p == nil || o == nil || pid == other {
+		return nil
+	}
+	if IsFaster(other, pid) {
+		p, o = o, p
+	}
+	return newAspect(
+		p.ID, p.ZodiacalPos.Sign, p.ZodiacalPos.SignDegrees(),
+		o.ID, o.ZodiacalPos.Sign, o.ZodiacalPos.SignDegrees(),
+	)
+}
+
+// newAspect returns the aspect between faster, at fasterDeg in fasterSign,
+// and slower, or nil if there's none
+func newAspect(
+	faster pointid.PointID,
+	fasterSign sign.Sign,
+	fasterDeg float64,
+	slower pointid.PointID,
+	slowerSign sign.Sign,
+	slowerDeg float64,
+) *Aspect {
+	at := NewAspectTypeFromSigns(fasterSign, slowerSign)
+	if at == AspectType_None {
+		return nil
+	}
+	return &Aspect{
+		Faster:   faster,
+		Slower:   slower,
+		Type:     at,
+		Orb:      (Deeptamsa(faster) + Deeptamsa(slower)) / 2,
+		Distance: slowerDeg - fasterDeg,
+	}
+}
+
+// CalculateAspects returns the Tajika aspects between the seven planets of c,
+// each pair once
+func CalculateAspects(c *chart.Chart) []*Aspect {
+	ret := []*Aspect{}
+	for i, pid := range Planets {
+		for _, other := range Planets[i+1:] {
+			if asp := NewAspect(c, pid, other); asp != nil {
+				ret = append(ret, asp)
+			}
+		}
+	}
+	return ret
+}
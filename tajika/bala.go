@@ -0,0 +1,121 @@
+package tajika
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+)
+
+// MaxPanchaVargiyaBala is the highest Pancha-vargiya bala a planet can have
+const MaxPanchaVargiyaBala = 20.0
+
+// hadda is a part of a sign ruled by a planet, up to (and excluding) the
+// degree end
+type hadda struct {
+	lord pointid.PointID
+	end  float64
+}
+
+// haddas are the Tajika haddas (the Egyptian terms) of each sign
+var haddas = map[sign.Sign][]hadda{
+	sign.Aries:       {{pointid.Jupiter, 6}, {pointid.Venus, 12}, {pointid.Mercury, 20}, {pointid.Mars, 25}, {pointid.Saturn, 30}},
+	sign.Taurus:      {{pointid.Venus, 8}, {pointid.Mercury, 14}, {pointid.Jupiter, 22}, {pointid.Saturn, 27}, {pointid.Mars, 30}},
+	sign.Gemini:      {{pointid.Mercury, 6}, {pointid.Jupiter, 12}, {pointid.Venus, 17}, {pointid.Mars, 24}, {pointid.Saturn, 30}},
+	sign.Cancer:      {{pointid.Mars, 7}, {pointid.Venus, 13}, {pointid.Mercury, 19}, {pointid.Jupiter, 26}, {pointid.Saturn, 30}},
+	sign.Leo:         {{pointid.Jupiter, 6}, {pointid.Venus, 11}, {pointid.Saturn, 18}, {pointid.Mercury, 24}, {pointid.Mars, 30}},
+	sign.Virgo:       {{pointid.Mercury, 7}, {pointid.Venus, 17}, {pointid.Jupiter, 21}, {pointid.Mars, 28}, {pointid.Saturn, 30}},
+	sign.Libra:       {{pointid.Saturn, 6}, {pointid.Mercury, 14}, {pointid.Jupiter, 21}, {pointid.Venus, 28}, {pointid.Mars, 30}},
+	sign.Scorpio:     {{pointid.Mars, 7}, {pointid.Venus, 11}, {pointid.Mercury, 19}, {pointid.Jupiter, 24}, {pointid.Saturn, 30}},
+	sign.Sagittarius: {{pointid.Jupiter, 12}, {pointid.Venus, 17}, {pointid.Mercury, 21}, {pointid.Saturn, 26}, {pointid.Mars, 30}},
+	sign.Capricorn:   {{pointid.Mercury, 7}, {pointid.Jupiter, 14}, {pointid.Venus, 22}, {pointid.Saturn, 26}, {pointid.Mars, 30}},
+	sign.Aquarius:    {{pointid.Mercury, 7}, {pointid.Venus, 13}, {pointid.Jupiter, 20}, {pointid.Mars, 25}, {pointid.Saturn, 30}},
+	sign.Pisces:      {{pointid.Venus, 12}, {pointid.Jupiter, 16}, {pointid.Mercury, 19}, {pointid.Mars, 28}, {pointid.Saturn, 30}},
+}
+
+// HaddaLord returns the lord of the hadda the sidereal longitude lon is in
+func HaddaLord(lon float64) pointid.PointID {
+	lon = math.Mod(lon+360, 360)
+	signDeg := math.Mod(lon, 30)
+	for _, h := range haddas[sign.DegreeToSign(lon)] {
+		if signDeg < h.end {
+			return h.lord
+		}
+	}
+	panic("unreachable")
+}
+
+// relationshipFraction is the part of a varga's full strength a planet gets
+// in a sign of lord
+//
+// Tajika texts have their own friendships. We use the five-fold ones of the
+// annual chart.
+func relationshipFraction(
+	c *chart.Chart,
+	pid, lord pointid.PointID,
+) (float64, error) {
+	if pid == lord {
+		return 1, nil
+	}
+	rel, err := c.PanchadhaRelationship(pid, lord)
+	if err != nil {
+		return 0, err
+	}
+	switch rel {
+	case chart.Relationship_GreatFriend:
+		return 3.0 / 4, nil
+	case chart.Relationship_Friend:
+		return 1.0 / 2, nil
+	case chart.Relationship_Neutral:
+		return 1.0 / 4, nil
+	case chart.Relationship_Enemy:
+		return 1.0 / 8, nil
+	}
+	return 1.0 / 16, nil
+}
+
+// PanchaVargiyaBala returns the five-fold strength of pid in c (a D1 chart),
+// out of MaxPanchaVargiyaBala: its sign (30), exaltation (20), hadda (15),
+// drekkana (10) and navamsa (5) strengths, divided by 4
+func PanchaVargiyaBala(
+	swe *wrapper.SwissEph,
+	c *chart.Chart,
+	pid pointid.PointID,
+) (float64, error) {
+	p := c.GetPoint(pid)
+	if p == nil {
+		return 0, fmt.Errorf("%s not found in chart", pid)
+	}
+	dignity, err := c.GetDignity(swe, pid)
+	if err != nil {
+		return 0, err
+	}
+	d3, err := c.GetVargaPos(pid, chart.D3ChartType)
+	if err != nil {
+		return 0, err
+	}
+	d9, err := c.GetVargaPos(pid, chart.D9ChartType)
+	if err != nil {
+		return 0, err
+	}
+	ret := 20 * dignity.ExaltationStrength
+	for _, v := range []struct {
+		lord pointid.PointID
+		max  float64
+	}{
+		{p.ZodiacalPos.Sign.TraditionalRuler(), 30},
+		{HaddaLord(p.Longitude), 15},
+		{d3.Sign.TraditionalRuler(), 10},
+		{d9.Sign.TraditionalRuler(), 5},
+	} {
+		f, err := relationshipFraction(c, pid, v.lord)
+		if err != nil {
+			return 0, fmt.Errorf("while getting relationship of %s with %s: %v", pid, v.lord, err)
+		}
+		ret += v.max * f
+	}
+	return ret / 4, nil
+}
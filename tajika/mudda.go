@@ -0,0 +1,117 @@
+package tajika
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/wrapper"
+)
+
+// MuddaPeriod is a period of Mudda dasha: Vimshottari dasha compressed into
+// a year
+type MuddaPeriod struct {
+	// Lords are the lords of this period and its parent, starting from the
+	// mahadasha lord
+	Lords    []chart.DashaLord `json:"lords"`
+	Interval chart.Interval    `json:"interval"`
+}
+
+func (mp *MuddaPeriod) String() string {
+	lords := []string{}
+	for _, l := range mp.Lords {
+		lords = append(lords, l.String())
+	}
+	return fmt.Sprintf(
+		"MuddaPeriod{Lords: %s, Interval: %s}",
+		strings.Join(lords, "/"),
+		mp.Interval,
+	)
+}
+
+// Lord returns the lord of this period (i.e., the last of Lords)
+func (mp *MuddaPeriod) Lord() chart.DashaLord {
+	return mp.Lords[len(mp.Lords)-1]
+}
+
+// SubPeriods returns the sub-periods of mp, in proportion to the Vimshottari
+// years of their lords, starting from the lord of mp
+func (mp *MuddaPeriod) SubPeriods() []*MuddaPeriod {
+	return muddaPeriods(mp.Lords, mp.Lord(), mp.Interval)
+}
+
+// muddaPeriods divides interval between the nine Vimshottari lords,
+// starting from lord, in proportion to their years
+func muddaPeriods(
+	parents []chart.DashaLord,
+	lord chart.DashaLord,
+	interval chart.Interval,
+) []*MuddaPeriod {
+	system := chart.DashaSystem_Vimshottari
+	length := interval.End.Sub(interval.Start.Time)
+	ret := []*MuddaPeriod{}
+	start := interval.Start.Time
+	for i := 0; i < chart.TotalDashaLords; i++ {
+		end := interval.End.Time
+		if i < chart.TotalDashaLords-1 {
+			end = start.Add(time.Duration(
+				float64(length) * system.Years(lord) / system.TotalYears(),
+			))
+		}
+		ret = append(ret, &MuddaPeriod{
+			Lords:    append(slices.Clone(parents), lord),
+			Interval: chart.NewInterval(start, end),
+		})
+		start = end
+		lord = system.Next(lord)
+	}
+	return ret
+}
+
+// MuddaLord returns the first lord of the Mudda dasha of the year after age
+// completed years: the lord of the natal Moon's nakshatra, moved on by a
+// lord a year
+func MuddaLord(
+	swe *wrapper.SwissEph,
+	natal *chart.Chart,
+	age int,
+) (chart.DashaLord, error) {
+	nd, err := natal.GetNakshatraDetails(swe, pointid.Moon)
+	if err != nil {
+		return chart.DashaLordNone, fmt.Errorf("while getting nakshatra of Moon: %v", err)
+	}
+	lord := nd.Lord
+	for i := 0; i < age%chart.TotalDashaLords; i++ {
+		lord = chart.DashaSystem_Vimshottari.Next(lord)
+	}
+	return lord, nil
+}
+
+// NewMuddaDasha returns the Mudda dasha of the year after age completed
+// years, over interval (from a solar return to the next one). The first
+// period runs in full, from the solar return.
+func NewMuddaDasha(
+	swe *wrapper.SwissEph,
+	natal *chart.Chart,
+	age int,
+	interval chart.Interval,
+) ([]*MuddaPeriod, error) {
+	lord, err := MuddaLord(swe, natal, age)
+	if err != nil {
+		return nil, err
+	}
+	return muddaPeriods(nil, lord, interval), nil
+}
+
+// GetMuddaPeriodForTime returns the period of ps active at t
+func GetMuddaPeriodForTime(ps []*MuddaPeriod, t time.Time) (*MuddaPeriod, bool) {
+	for _, p := range ps {
+		if !t.Before(p.Interval.Start.Time) && t.Before(p.Interval.End.Time) {
+			return p, true
+		}
+	}
+	return nil, false
+}
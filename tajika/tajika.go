@@ -0,0 +1,314 @@
+// Package tajika casts Tajika annual charts (varshaphala): the sidereal solar
+// return of a natal chart for a given year, with its Muntha, the five office
+// bearers and the year lord, Tajika aspects and yogas, and Mudda dasha.
+package tajika
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+)
+
+// Planets are the seven planets Tajika works with: the nodes are ignored
+var Planets = []pointid.PointID{
+	pointid.Sun,
+	pointid.Moon,
+	pointid.Mars,
+	pointid.Mercury,
+	pointid.Jupiter,
+	pointid.Venus,
+	pointid.Saturn,
+}
+
+// OfficeType is one of the five offices (pancha-adhikari) the year lord is
+// chosen from
+type OfficeType string
+
+const (
+	// The lord of the natal ascendant
+	OfficeType_JanmaLagnesh OfficeType = "janma-lagnesh"
+	// The lord of the annual ascendant
+	OfficeType_VarshaLagnesh OfficeType = "varsha-lagnesh"
+	// The lord of the Muntha sign
+	OfficeType_MunthaLord OfficeType = "muntha-lord"
+	// The lord of the annual ascendant's triplicity, by day or night
+	OfficeType_TriRashiPati OfficeType = "tri-rashi-pati"
+	// The lord of the Sun's sign in a day return, or the Moon's in a night one
+	OfficeType_DinRatriPati OfficeType = "din-ratri-pati"
+)
+
+func (ot OfficeType) String() string {
+	return string(ot)
+}
+
+// triRashiPatis are the lords of the annual ascendant's sign by day and by
+// night (Tajika Neelakanthi 1)
+var triRashiPatis = map[sign.Sign]struct{ day, night pointid.PointID }{
+	sign.Aries:       {pointid.Sun, pointid.Jupiter},
+	sign.Taurus:      {pointid.Venus, pointid.Moon},
+	sign.Gemini:      {pointid.Saturn, pointid.Mercury},
+	sign.Cancer:      {pointid.Venus, pointid.Mars},
+	sign.Leo:         {pointid.Jupiter, pointid.Sun},
+	sign.Virgo:       {pointid.Moon, pointid.Venus},
+	sign.Libra:       {pointid.Mercury, pointid.Saturn},
+	sign.Scorpio:     {pointid.Mars, pointid.Venus},
+	sign.Sagittarius: {pointid.Saturn, pointid.Saturn},
+	sign.Capricorn:   {pointid.Mars, pointid.Mars},
+	sign.Aquarius:    {pointid.Jupiter, pointid.Jupiter},
+	sign.Pisces:      {pointid.Moon, pointid.Moon},
+}
+
+// Muntha is the progressed natal ascendant: it moves a sign a year
+type Muntha struct {
+	Sign sign.Sign `json:"sign"`
+	// House is the house of Sign in the annual chart
+	House house.House     `json:"house"`
+	Lord  pointid.PointID `json:"lord"`
+}
+
+func (m *Muntha) String() string {
+	return fmt.Sprintf(
+		"Muntha{Sign: %s, House: %s, Lord: %s}",
+		m.Sign,
+		m.House,
+		m.Lord,
+	)
+}
+
+// OfficeBearer is the planet holding one of the five offices of the year
+type OfficeBearer struct {
+	Office OfficeType      `json:"office"`
+	Planet pointid.PointID `json:"planet"`
+	// Strength is the Pancha-vargiya bala of Planet in the annual chart, out
+	// of 20
+	Strength float64 `json:"strength"`
+	// AspectsLagna is true if Planet has a Tajika aspect on the annual
+	// ascendant
+	AspectsLagna bool `json:"aspectsLagna"`
+}
+
+func (ob *OfficeBearer) String() string {
+	return fmt.Sprintf(
+		"OfficeBearer{Office: %s, Planet: %s, Strength: %f, AspectsLagna: %t}",
+		ob.Office,
+		ob.Planet,
+		ob.Strength,
+		ob.AspectsLagna,
+	)
+}
+
+// Varshaphala is the Tajika annual chart of a year of life
+type Varshaphala struct {
+	Year int `json:"year"`
+	// Age is the number of years completed at the solar return
+	Age   int          `json:"age"`
+	Natal *chart.Chart `json:"-"`
+	// Chart is the D1 chart cast at the solar return
+	Chart *chart.Chart `json:"chart"`
+	// Interval is from this solar return to the next one
+	Interval chart.Interval `json:"interval"`
+	// IsDay is true if the Sun is above the horizon at the solar return
+	IsDay         bool            `json:"isDay"`
+	Muntha        *Muntha         `json:"muntha"`
+	OfficeBearers []*OfficeBearer `json:"officeBearers"`
+	// YearLord (Varsheshvara) is the strongest office bearer that aspects
+	// the annual ascendant
+	YearLord   pointid.PointID `json:"yearLord"`
+	Aspects    []*Aspect       `json:"aspects"`
+	MuddaDasha []*MuddaPeriod  `json:"muddaDasha"`
+}
+
+func (v *Varshaphala) String() string {
+	return fmt.Sprintf(
+		"Varshaphala{Year: %d, Age: %d, Interval: %s, Muntha: %s, YearLord: %s}",
+		v.Year,
+		v.Age,
+		v.Interval,
+		v.Muntha,
+		v.YearLord,
+	)
+}
+
+// solarReturnPrecision is how close (in degrees) the Sun has to be to its
+// natal longitude: about a tenth of a second of time
+const solarReturnPrecision = 1e-6
+
+// FindSolarReturn returns the time, in year, the sidereal Sun comes back to
+// sunLon (its sidereal longitude at birth, in the ayanamsa a). birthTime is
+// used to know where in the year to look.
+func FindSolarReturn(
+	swe *wrapper.SwissEph,
+	birthTime time.Time,
+	sunLon float64,
+	a wrapper.Ayanamsa,
+	year int,
+) (time.Time, error) {
+	birthTime = birthTime.UTC()
+	jd := swe.GoTimeToJulianDay(time.Date(
+		year,
+		birthTime.Month(),
+		birthTime.Day(),
+		birthTime.Hour(),
+		birthTime.Minute(),
+		0, 0, time.UTC,
+	))
+	// Newton's method: the Sun moves about a degree a day, so it only
+	// takes a few steps
+	const maxIterations = 20
+	for i := 0; i < maxIterations; i++ {
+		lon, speed, err := swe.CalcUTFor(jd, pointid.Sun.SwissEphID(), a)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("while calculating Sun: %v", err)
+		}
+		diff := math.Mod(sunLon-lon+540, 360) - 180
+		if math.Abs(diff) < solarReturnPrecision {
+			return swe.JulianDayToGoTime(jd), nil
+		}
+		jd += diff / speed
+	}
+	return time.Time{}, fmt.Errorf("could not find solar return of %d", year)
+}
+
+// NewVarshaphala casts the annual chart of natal (a D1 chart) for year, at
+// lon/lat: where the native lives that year, which may not be the birth
+// place.
+func NewVarshaphala(
+	swe *wrapper.SwissEph,
+	natal *chart.Chart,
+	year int,
+	lon, lat float64,
+) (*Varshaphala, error) {
+	if natal.ChartType != chart.D1ChartType {
+		return nil, fmt.Errorf("Varshaphala needs a D1 chart, got %s", natal.ChartType)
+	}
+	for _, pid := range append([]pointid.PointID{pointid.ASC}, Planets...) {
+		if natal.GetPoint(pid) == nil {
+			return nil, fmt.Errorf("%s not found in natal chart", pid)
+		}
+	}
+	birthTime := natal.Time.Time.UTC()
+	age := year - birthTime.Year()
+	if age < 0 {
+		return nil, fmt.Errorf("year %d is before birth", year)
+	}
+	sunLon := natal.MustGetPoint(pointid.Sun).Longitude
+	start, err := FindSolarReturn(swe, birthTime, sunLon, natal.Ayanamsa, year)
+	if err != nil {
+		return nil, err
+	}
+	end, err := FindSolarReturn(swe, birthTime, sunLon, natal.Ayanamsa, year+1)
+	if err != nil {
+		return nil, err
+	}
+	// The annual chart is cast in the natal chart's ayanamsa, like the
+	// solar return
+	cfg := chart.NewDefaultConfig()
+	cfg.Ayanamsa = natal.Ayanamsa
+	annual, err := chart.NewChartFromUTCWithConfig(
+		swe,
+		start,
+		lon, lat,
+		chart.D1ChartType,
+		pointid.VedicPlanets,
+		cfg,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("while casting annual chart: %v", err)
+	}
+	ret := &Varshaphala{
+		Year:     year,
+		Age:      age,
+		Natal:    natal,
+		Chart:    annual,
+		Interval: chart.NewInterval(start, end),
+		IsDay:    isDay(annual),
+		Aspects:  CalculateAspects(annual),
+	}
+
+	// Muntha moves a sign a year from the natal ascendant
+	munthaSign, _ := sign.NewSignFromInt(
+		natal.MustGetPoint(pointid.ASC).ZodiacalPos.Sign.Int() + age,
+	)
+	annualAsc := annual.MustGetPoint(pointid.ASC).ZodiacalPos.Sign
+	ret.Muntha = &Muntha{
+		Sign:  munthaSign,
+		House: house.NewHouseFromSign(munthaSign, annualAsc),
+		Lord:  munthaSign.TraditionalRuler(),
+	}
+
+	ret.OfficeBearers, err = officeBearers(swe, ret)
+	if err != nil {
+		return nil, err
+	}
+	ret.YearLord = yearLord(ret.OfficeBearers)
+
+	ret.MuddaDasha, err = NewMuddaDasha(swe, natal, age, ret.Interval)
+	if err != nil {
+		return nil, fmt.Errorf("while casting Mudda dasha: %v", err)
+	}
+	return ret, nil
+}
+
+// isDay reports whether the Sun is above the horizon in c: between the
+// descendant and the ascendant, through the midheaven
+func isDay(c *chart.Chart) bool {
+	sun := c.MustGetPoint(pointid.Sun).Longitude
+	asc := c.MustGetPoint(pointid.ASC).Longitude
+	return math.Mod(sun-asc+360, 360) >= 180
+}
+
+// officeBearers returns the five office bearers of v, in the order of
+// OfficeType
+func officeBearers(
+	swe *wrapper.SwissEph,
+	v *Varshaphala,
+) ([]*OfficeBearer, error) {
+	annualAsc := v.Chart.MustGetPoint(pointid.ASC).ZodiacalPos.Sign
+	triRashiPati := triRashiPatis[annualAsc].night
+	dinRatriPati := v.Chart.MustGetPoint(pointid.Moon).ZodiacalPos.Sign.TraditionalRuler()
+	if v.IsDay {
+		triRashiPati = triRashiPatis[annualAsc].day
+		dinRatriPati = v.Chart.MustGetPoint(pointid.Sun).ZodiacalPos.Sign.TraditionalRuler()
+	}
+	ret := []*OfficeBearer{
+		{Office: OfficeType_JanmaLagnesh, Planet: v.Natal.MustGetPoint(pointid.ASC).ZodiacalPos.Sign.TraditionalRuler()},
+		{Office: OfficeType_VarshaLagnesh, Planet: annualAsc.TraditionalRuler()},
+		{Office: OfficeType_MunthaLord, Planet: v.Muntha.Lord},
+		{Office: OfficeType_TriRashiPati, Planet: triRashiPati},
+		{Office: OfficeType_DinRatriPati, Planet: dinRatriPati},
+	}
+	for _, ob := range ret {
+		var err error
+		ob.Strength, err = PanchaVargiyaBala(swe, v.Chart, ob.Planet)
+		if err != nil {
+			return nil, fmt.Errorf("while getting strength of %s: %v", ob.Planet, err)
+		}
+		h := v.Chart.MustGetPoint(ob.Planet).House
+		ob.AspectsLagna = NewAspectTypeFromHouse(h) != AspectType_None
+	}
+	return ret, nil
+}
+
+// yearLord returns the strongest of obs that aspects the annual ascendant.
+// If none does, it's the strongest of them all.
+//
+// Some texts don't let the Moon be the year lord and pass its office on to
+// the lord of its sign. We don't.
+func yearLord(obs []*OfficeBearer) pointid.PointID {
+	var best *OfficeBearer
+	for _, ob := range obs {
+		switch {
+		case best == nil,
+			ob.AspectsLagna && !best.AspectsLagna,
+			ob.AspectsLagna == best.AspectsLagna && ob.Strength > best.Strength:
+			best = ob
+		}
+	}
+	return best.Planet
+}
@@ -0,0 +1,169 @@
+package tajika
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVarshaphala(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+	// London coordinates
+	lon, lat := -0.1278, 51.5074
+	natal, err := chart.NewChartFromUTC(
+		swe,
+		time.Date(1990, 5, 10, 6, 30, 0, 0, time.UTC),
+		lon, lat,
+		chart.D1ChartType,
+		pointid.VedicPlanets,
+	)
+	require.NoError(t, err)
+	v, err := NewVarshaphala(swe, natal, 2024, lon, lat)
+	require.NoError(t, err)
+	require.Equal(t, 34, v.Age)
+
+	// The Sun is back to Aries 25°37', in the evening before the birthday
+	require.InDelta(t,
+		natal.MustGetPoint(pointid.Sun).Longitude,
+		v.Chart.MustGetPoint(pointid.Sun).Longitude,
+		0.001,
+	)
+	require.Equal(t,
+		time.Date(2024, 5, 9, 23, 39, 0, 0, time.UTC),
+		v.Interval.Start.Time.UTC(),
+	)
+	require.InDelta(t, 365.25, v.Interval.End.Sub(v.Interval.Start.Time).Hours()/24, 0.1)
+	require.False(t, v.IsDay)
+
+	// Gemini + 34 signs is Aries, in the 5th from the Sagittarius ascendant
+	require.Equal(t, sign.Aries, v.Muntha.Sign)
+	require.Equal(t, house.House5, v.Muntha.House)
+	require.Equal(t, pointid.Mars, v.Muntha.Lord)
+
+	expected := map[OfficeType]pointid.PointID{
+		OfficeType_JanmaLagnesh:  pointid.Mercury,
+		OfficeType_VarshaLagnesh: pointid.Jupiter,
+		OfficeType_MunthaLord:    pointid.Mars,
+		// Sagittarius at night
+		OfficeType_TriRashiPati: pointid.Saturn,
+		// The Moon is in Taurus
+		OfficeType_DinRatriPati: pointid.Venus,
+	}
+	require.Len(t, v.OfficeBearers, len(expected))
+	for _, ob := range v.OfficeBearers {
+		require.Equal(t, expected[ob.Office], ob.Planet, ob.Office)
+		require.True(t, ob.Strength > 0 && ob.Strength <= MaxPanchaVargiyaBala)
+	}
+	// Jupiter is in the 6th, so it has no aspect on the ascendant
+	require.False(t, v.OfficeBearers[1].AspectsLagna)
+	require.Equal(t, pointid.Mars, v.YearLord)
+
+	// The natal Moon is in Vishakha (Jupiter): 34 lords on is Mars
+	require.Len(t, v.MuddaDasha, chart.TotalDashaLords)
+	require.Equal(t, chart.DashaLordMars, v.MuddaDasha[0].Lord())
+	require.Equal(t, v.Interval.Start, v.MuddaDasha[0].Interval.Start)
+	require.Equal(t, v.Interval.End, v.MuddaDasha[len(v.MuddaDasha)-1].Interval.End)
+	// Mars has 7 of the 120 years
+	require.InDelta(t, 365.25*7/120,
+		v.MuddaDasha[0].Interval.End.Sub(v.MuddaDasha[0].Interval.Start.Time).Hours()/24,
+		0.1,
+	)
+	subs := v.MuddaDasha[1].SubPeriods()
+	require.Equal(t, []chart.DashaLord{chart.DashaLordRahu, chart.DashaLordRahu}, subs[0].Lords)
+	require.Equal(t, v.MuddaDasha[1].Interval.End, subs[len(subs)-1].Interval.End)
+	p, ok := GetMuddaPeriodForTime(v.MuddaDasha, time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC))
+	require.True(t, ok)
+	require.Equal(t, chart.DashaLordJupiter, p.Lord())
+
+	_, err = NewVarshaphala(swe, natal, 1989, lon, lat)
+	require.Error(t, err)
+
+	// With another ayanamsa, the Sun comes back to its Raman longitude, and
+	// the annual chart is cast in Raman too
+	cfg := chart.NewDefaultConfig()
+	cfg.Ayanamsa = wrapper.Ayanamsa_Raman
+	raman, err := chart.NewChartFromUTCWithConfig(
+		swe,
+		natal.Time.Time,
+		lon, lat,
+		chart.D1ChartType,
+		pointid.VedicPlanets,
+		cfg,
+	)
+	require.NoError(t, err)
+	v, err = NewVarshaphala(swe, raman, 2024, lon, lat)
+	require.NoError(t, err)
+	require.Equal(t, wrapper.Ayanamsa_Raman, v.Chart.Ayanamsa)
+	require.InDelta(t,
+		raman.MustGetPoint(pointid.Sun).Longitude,
+		v.Chart.MustGetPoint(pointid.Sun).Longitude,
+		0.001,
+	)
+	// Raman and Lahiri move together, so it's the same return. Comparing
+	// the Raman natal Sun with a Lahiri one would be off by more than a day.
+	require.InDelta(t, 0,
+		v.Interval.Start.Sub(time.Date(2024, 5, 9, 23, 39, 0, 0, time.UTC)).Minutes(),
+		1,
+	)
+}
+
+func TestFindYogas(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+	// The 2024 annual chart of TestNewVarshaphala
+	c, err := chart.NewChartFromUTC(
+		swe,
+		time.Date(2024, 5, 9, 23, 39, 0, 0, time.UTC),
+		-0.1278, 51.5074,
+		chart.D1ChartType,
+		pointid.VedicPlanets,
+	)
+	require.NoError(t, err)
+
+	// Venus (Aries 18°44') applies to Saturn (Aquarius 23°10') by sextile,
+	// and the Sun (Aries 25°37') has gone past Saturn
+	venusSaturn := NewAspect(c, pointid.Saturn, pointid.Venus)
+	require.Equal(t, pointid.Venus, venusSaturn.Faster)
+	require.Equal(t, AspectType_Sextile, venusSaturn.Type)
+	require.Equal(t, 8.0, venusSaturn.Orb)
+	require.True(t, venusSaturn.IsIthasala())
+	require.True(t, NewAspect(c, pointid.Sun, pointid.Saturn).IsIshrafa())
+	// Taurus and Aries have no aspect
+	require.Nil(t, NewAspect(c, pointid.Moon, pointid.Venus))
+
+	yogaTypes := func(p1, p2 pointid.PointID) []YogaType {
+		ys, err := FindYogas(swe, c, p1, p2)
+		require.NoError(t, err)
+		ret := []YogaType{}
+		for _, y := range ys {
+			ret = append(ret, y.Type)
+		}
+		return ret
+	}
+	// The Moon applies to Saturn by square too. Venus is combust, and
+	// Saturn in its moolatrikona.
+	require.Equal(t,
+		[]YogaType{
+			YogaType_Ithasala,
+			YogaType_Kamboola,
+			YogaType_Radda,
+			YogaType_DuphaliKuttha,
+		},
+		yogaTypes(pointid.Venus, pointid.Saturn),
+	)
+	// Venus, faster, brings the Sun and Saturn back together
+	require.Equal(t,
+		[]YogaType{YogaType_Ishrafa, YogaType_Nakta},
+		yogaTypes(pointid.Sun, pointid.Saturn),
+	)
+
+	_, err = FindYogas(swe, c, pointid.Rahu, pointid.Sun)
+	require.Error(t, err)
+}
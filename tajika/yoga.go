@@ -0,0 +1,347 @@
+package tajika
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/wrapper"
+)
+
+// YogaType is one of the 16 Tajika yogas. All but Ikkabala and Induvara are
+// about two significators: usually the lord of the ascendant and the lord
+// of the house of the matter.
+type YogaType string
+
+const (
+	YogaType_Ikkabala        YogaType = "ikkabala"
+	YogaType_Induvara        YogaType = "induvara"
+	YogaType_Ithasala        YogaType = "ithasala"
+	YogaType_Ishrafa         YogaType = "ishrafa"
+	YogaType_Nakta           YogaType = "nakta"
+	YogaType_Yamaya          YogaType = "yamaya"
+	YogaType_Manau           YogaType = "manau"
+	YogaType_Kamboola        YogaType = "kamboola"
+	YogaType_GairiKamboola   YogaType = "gairi-kamboola"
+	YogaType_Khallasara      YogaType = "khallasara"
+	YogaType_Radda           YogaType = "radda"
+	YogaType_DuphaliKuttha   YogaType = "duphali-kuttha"
+	YogaType_DutthothaDavira YogaType = "dutthotha-davira"
+	YogaType_Tambira         YogaType = "tambira"
+	YogaType_Kuttha          YogaType = "kuttha"
+	YogaType_Durupha         YogaType = "durupha"
+)
+
+func (yt YogaType) String() string {
+	return string(yt)
+}
+
+// Yoga is a Tajika yoga found in a chart
+type Yoga struct {
+	Type YogaType `json:"type"`
+	// Planets are the planets forming the yoga, starting from the
+	// significators
+	Planets []pointid.PointID `json:"planets"`
+	Rule    string            `json:"rule"`
+}
+
+func (y *Yoga) String() string {
+	return fmt.Sprintf(
+		"Yoga{Type: %s, Planets: %v, Rule: %s}",
+		y.Type,
+		y.Planets,
+		y.Rule,
+	)
+}
+
+// kendrasAndPanapharas are the angular houses and the ones after them
+var kendrasAndPanapharas = []int{1, 2, 4, 5, 7, 8, 10, 11}
+
+// apoklimas are the houses before the angular ones
+var apoklimas = []int{3, 6, 9, 12}
+
+// yogaContext holds what the yogas of a chart need
+type yogaContext struct {
+	c         *chart.Chart
+	dignities map[pointid.PointID]*chart.Dignity
+	aspects   []*Aspect
+}
+
+func (ctx *yogaContext) aspect(pid, other pointid.PointID) *Aspect {
+	for _, a := range ctx.aspects {
+		if a.Has(pid) && a.Has(other) {
+			return a
+		}
+	}
+	return nil
+}
+
+func (ctx *yogaContext) isIthasala(pid, other pointid.PointID) bool {
+	a := ctx.aspect(pid, other)
+	return a != nil && a.IsIthasala()
+}
+
+func (ctx *yogaContext) houseOf(pid pointid.PointID) int {
+	return ctx.c.MustGetPoint(pid).House.Int()
+}
+
+// isStrong reports whether pid is in its exaltation, moolatrikona or own
+// sign
+func (ctx *yogaContext) isStrong(pid pointid.PointID) bool {
+	switch ctx.dignities[pid].State {
+	case chart.DignityState_Exalted,
+		chart.DignityState_Moolatrikona,
+		chart.DignityState_Own:
+		return true
+	}
+	return false
+}
+
+// isWeak reports whether pid is debilitated, in an enemy's sign or combust
+func (ctx *yogaContext) isWeak(pid pointid.PointID) bool {
+	d := ctx.dignities[pid]
+	switch d.State {
+	case chart.DignityState_Debilitated,
+		chart.DignityState_Enemy,
+		chart.DignityState_GreatEnemy:
+		return true
+	}
+	return d.IsCombust
+}
+
+// isInLastDegree reports whether pid is in the last degree of its sign
+func (ctx *yogaContext) isInLastDegree(pid pointid.PointID) bool {
+	return ctx.c.MustGetPoint(pid).ZodiacalPos.SignDegrees() >= 29
+}
+
+// nextSignIthasala returns the planet pid would form an Ithasala with when
+// it enters the next sign, or pointid.None. pid has to be the faster one.
+func (ctx *yogaContext) nextSignIthasala(
+	pid pointid.PointID,
+	only func(pointid.PointID) bool,
+) pointid.PointID {
+	next := ctx.c.MustGetPoint(pid).ZodiacalPos.Sign.Next()
+	for _, other := range Planets {
+		if other == pid || !IsFaster(pid, other) || !only(other) {
+			continue
+		}
+		o := ctx.c.MustGetPoint(other)
+		a := newAspect(
+			pid, next, 0,
+			other, o.ZodiacalPos.Sign, o.ZodiacalPos.SignDegrees(),
+		)
+		if a != nil && a.IsIthasala() {
+			return other
+		}
+	}
+	return pointid.None
+}
+
+// FindYogas returns the Tajika yogas of c (a D1 chart) between the
+// significators p1 and p2, plus Ikkabala and Induvara, which are about the
+// whole chart
+func FindYogas(
+	swe *wrapper.SwissEph,
+	c *chart.Chart,
+	p1, p2 pointid.PointID,
+) ([]*Yoga, error) {
+	if c.ChartType != chart.D1ChartType {
+		return nil, fmt.Errorf("Tajika yogas need a D1 chart, got %s", c.ChartType)
+	}
+	ctx := &yogaContext{
+		c:         c,
+		dignities: map[pointid.PointID]*chart.Dignity{},
+		aspects:   CalculateAspects(c),
+	}
+	for _, pid := range Planets {
+		d, err := c.GetDignity(swe, pid)
+		if err != nil {
+			return nil, fmt.Errorf("while getting dignity of %s: %v", pid, err)
+		}
+		ctx.dignities[pid] = d
+	}
+	ret := []*Yoga{}
+	add := func(yt YogaType, rule string, planets ...pointid.PointID) {
+		ret = append(ret, &Yoga{Type: yt, Planets: planets, Rule: rule})
+	}
+
+	// Ikkabala and Induvara
+	inHouses := func(houses []int) bool {
+		for _, pid := range Planets {
+			if !slices.Contains(houses, ctx.houseOf(pid)) {
+				return false
+			}
+		}
+		return true
+	}
+	if inHouses(kendrasAndPanapharas) {
+		add(YogaType_Ikkabala, "all planets in kendras and panapharas", Planets...)
+	}
+	if inHouses(apoklimas) {
+		add(YogaType_Induvara, "all planets in apoklimas", Planets...)
+	}
+
+	if p1 == p2 {
+		return ret, nil
+	}
+	for _, pid := range []pointid.PointID{p1, p2} {
+		if !slices.Contains(Planets, pid) {
+			return nil, fmt.Errorf("%s can't be a significator", pid)
+		}
+	}
+	faster, slower := p1, p2
+	if IsFaster(p2, p1) {
+		faster, slower = p2, p1
+	}
+	asp := ctx.aspect(p1, p2)
+	isIthasala := asp != nil && asp.IsIthasala()
+	others := []pointid.PointID{}
+	for _, pid := range Planets {
+		if pid != p1 && pid != p2 {
+			others = append(others, pid)
+		}
+	}
+
+	// Ithasala and Ishrafa
+	switch {
+	case isIthasala:
+		add(YogaType_Ithasala, fmt.Sprintf("%s applies to %s within orbs", faster, slower), faster, slower)
+	case asp != nil && asp.IsIshrafa():
+		add(YogaType_Ishrafa, fmt.Sprintf("%s separates from %s within orbs", faster, slower), faster, slower)
+	}
+
+	// Nakta and Yamaya: a third planet brings together significators that
+	// are not in Ithasala
+	if !isIthasala {
+		for _, other := range others {
+			if !ctx.isIthasala(other, p1) || !ctx.isIthasala(other, p2) {
+				continue
+			}
+			switch {
+			case IsFaster(other, faster):
+				add(YogaType_Nakta, fmt.Sprintf("%s, faster, applies to both %s and %s", other, p1, p2), p1, p2, other)
+			case IsFaster(slower, other):
+				add(YogaType_Yamaya, fmt.Sprintf("%s, slower, receives both %s and %s", other, p1, p2), p1, p2, other)
+			}
+		}
+	}
+
+	if isIthasala {
+		// Manau: Mars or Saturn spoils the Ithasala with an inimical aspect
+		// on the faster planet
+		for _, malefic := range []pointid.PointID{pointid.Mars, pointid.Saturn} {
+			if malefic == p1 || malefic == p2 {
+				continue
+			}
+			a := ctx.aspect(malefic, faster)
+			if a != nil && a.Type.IsInimical() && a.IsWithinOrb() {
+				add(YogaType_Manau, fmt.Sprintf("%s has a %s on %s within orbs", malefic, a.Type, faster), faster, slower, malefic)
+			}
+		}
+
+		// Kamboola, Gairi-kamboola and Khallasara: the Moon supports the
+		// Ithasala, or doesn't
+		if p1 != pointid.Moon && p2 != pointid.Moon {
+			hasMoonAspect := false
+			for _, a := range ctx.aspects {
+				if a.Has(pointid.Moon) && a.IsWithinOrb() {
+					hasMoonAspect = true
+					break
+				}
+			}
+			nextSign := pointid.None
+			if ctx.isInLastDegree(pointid.Moon) {
+				nextSign = ctx.nextSignIthasala(pointid.Moon, ctx.isStrong)
+			}
+			switch {
+			case ctx.isIthasala(pointid.Moon, p1) || ctx.isIthasala(pointid.Moon, p2):
+				add(YogaType_Kamboola, "the Moon applies to a significator", faster, slower, pointid.Moon)
+			case nextSign != pointid.None:
+				add(YogaType_GairiKamboola, fmt.Sprintf("the Moon, in the last degree of its sign, will apply to %s in the next one", nextSign), faster, slower, pointid.Moon, nextSign)
+			case !hasMoonAspect:
+				add(YogaType_Khallasara, "the Moon has no aspect within orbs", faster, slower, pointid.Moon)
+			}
+		}
+
+		// Radda: a significator is retrograde, combust or debilitated
+		for _, pid := range []pointid.PointID{faster, slower} {
+			d := ctx.dignities[pid]
+			switch {
+			case ctx.c.MustGetPoint(pid).IsRetrograde:
+				add(YogaType_Radda, fmt.Sprintf("%s is retrograde", pid), faster, slower)
+			case d.IsCombust:
+				add(YogaType_Radda, fmt.Sprintf("%s is combust", pid), faster, slower)
+			case d.State == chart.DignityState_Debilitated:
+				add(YogaType_Radda, fmt.Sprintf("%s is debilitated", pid), faster, slower)
+			}
+		}
+
+		// Duphali-kuttha: the slower planet is strong and the faster one
+		// weak
+		if ctx.isStrong(slower) && ctx.isWeak(faster) {
+			add(YogaType_DuphaliKuttha, fmt.Sprintf("%s is strong and %s weak", slower, faster), faster, slower)
+		}
+	}
+
+	// Dutthotha-davira: both significators are weak, but one of them is in
+	// Ithasala with a strong planet
+	if ctx.isWeak(p1) && ctx.isWeak(p2) {
+		for _, other := range others {
+			if ctx.isStrong(other) && (ctx.isIthasala(other, p1) || ctx.isIthasala(other, p2)) {
+				add(YogaType_DutthothaDavira, fmt.Sprintf("both significators are weak, but in Ithasala with %s, which is strong", other), p1, p2, other)
+			}
+		}
+	}
+
+	// Tambira: the significators are not in Ithasala, but one of them is in
+	// the last degree of its sign and will form one with a strong planet in
+	// the next
+	if !isIthasala {
+		for _, pid := range []pointid.PointID{p1, p2} {
+			if !ctx.isInLastDegree(pid) {
+				continue
+			}
+			if other := ctx.nextSignIthasala(pid, ctx.isStrong); other != pointid.None {
+				add(YogaType_Tambira, fmt.Sprintf("%s, in the last degree of its sign, will apply to %s in the next one", pid, other), p1, p2, other)
+			}
+		}
+	}
+
+	// Kuttha and Durupha: the significators are both strong in kendras and
+	// panapharas, or both weak in apoklimas
+	inAll := func(houses []int, pids ...pointid.PointID) bool {
+		for _, pid := range pids {
+			if !slices.Contains(houses, ctx.houseOf(pid)) {
+				return false
+			}
+		}
+		return true
+	}
+	if ctx.isStrong(p1) && ctx.isStrong(p2) && inAll(kendrasAndPanapharas, p1, p2) {
+		add(YogaType_Kuttha, "both significators are strong, in kendras or panapharas", p1, p2)
+	}
+	if ctx.isWeak(p1) && ctx.isWeak(p2) && inAll(apoklimas, p1, p2) {
+		add(YogaType_Durupha, "both significators are weak, in apoklimas", p1, p2)
+	}
+	return ret, nil
+}
+
+// FindYogas returns the Tajika yogas of the annual chart for the matter of
+// house h: its significators are the lord of the annual ascendant and the
+// lord of h
+func (v *Varshaphala) FindYogas(
+	swe *wrapper.SwissEph,
+	h house.House,
+) ([]*Yoga, error) {
+	lagnaLord, err := v.Chart.GetHouseLordFor(house.House1, chart.HouseLordPlacement_Traditional)
+	if err != nil {
+		return nil, err
+	}
+	lord, err := v.Chart.GetHouseLordFor(h, chart.HouseLordPlacement_Traditional)
+	if err != nil {
+		return nil, err
+	}
+	return FindYogas(swe, v.Chart, lagnaLord, lord)
+}
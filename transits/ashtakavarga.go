@@ -0,0 +1,42 @@
+package transits
+
+import (
+	"time"
+
+	"github.com/afjoseph/sacredstar/ashtakavarga"
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/go-playground/errors/v5"
+)
+
+// ScoreAshtakavarga scores the sidereal transits of the seven planets at t
+// against the natal ashtakavarga natal, in the order of
+// ashtakavarga.Planets. The transits are in the ayanamsa of natal.
+func ScoreAshtakavarga(
+	swe *wrapper.SwissEph,
+	natal *ashtakavarga.Ashtakavarga,
+	t time.Time,
+) ([]*ashtakavarga.TransitScore, error) {
+	cfg := chart.NewDefaultConfig()
+	cfg.Ayanamsa = natal.Ayanamsa
+	chrt, err := chart.NewChartFromJulianDayWithConfig(
+		swe,
+		swe.GoTimeToJulianDay(t),
+		0, 0, // lon, lat: signs don't depend on the location
+		chart.D1ChartType,
+		ashtakavarga.Planets,
+		cfg,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "while calculating chart for %s", t)
+	}
+	ret := []*ashtakavarga.TransitScore{}
+	for _, pid := range ashtakavarga.Planets {
+		score, err := natal.ScoreTransit(pid, chrt.MustGetPoint(pid).Longitude)
+		if err != nil {
+			return nil, errors.Wrapf(err, "while scoring transit of %s", pid)
+		}
+		ret = append(ret, score)
+	}
+	return ret, nil
+}
@@ -0,0 +1,71 @@
+package transits
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/ashtakavarga"
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoreAshtakavarga(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+	natalChart, err := chart.NewChartFromUTC(
+		swe,
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		// London coordinates
+		-0.1278, 51.5074,
+		chart.D1ChartType,
+		pointid.VedicPlanets,
+	)
+	require.NoError(t, err)
+	natal, err := ashtakavarga.New(natalChart)
+	require.NoError(t, err)
+
+	scores, err := ScoreAshtakavarga(swe, natal, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, scores, len(ashtakavarga.Planets))
+	for i, score := range scores {
+		require.Equal(t, ashtakavarga.Planets[i], score.Planet)
+		require.Equal(t,
+			natal.Get(score.Planet).Bindus.In(score.Sign),
+			score.Bindus,
+		)
+		require.Equal(t, natal.Sarva.In(score.Sign), score.SarvaBindus)
+	}
+	// Saturn entered sidereal Pisces in March 2025
+	saturn := scores[len(scores)-1]
+	require.Equal(t, pointid.Saturn, saturn.Planet)
+	require.Equal(t, sign.Pisces, saturn.Sign)
+	require.Equal(t, 2, saturn.Bindus)
+	require.False(t, saturn.IsFavorable())
+
+	// The transits are in the natal chart's ayanamsa. Raman is about 1.4°
+	// less than Lahiri, so Saturn is already in Raman Pisces a week before
+	// it enters Lahiri Pisces.
+	cfg := chart.NewDefaultConfig()
+	cfg.Ayanamsa = wrapper.Ayanamsa_Raman
+	ramanChart, err := chart.NewChartFromUTCWithConfig(
+		swe,
+		natalChart.Time.Time,
+		-0.1278, 51.5074,
+		chart.D1ChartType,
+		pointid.VedicPlanets,
+		cfg,
+	)
+	require.NoError(t, err)
+	raman, err := ashtakavarga.New(ramanChart)
+	require.NoError(t, err)
+	beforeIngress := time.Date(2025, 3, 22, 0, 0, 0, 0, time.UTC)
+	scores, err = ScoreAshtakavarga(swe, natal, beforeIngress)
+	require.NoError(t, err)
+	require.Equal(t, sign.Aquarius, scores[len(scores)-1].Sign)
+	scores, err = ScoreAshtakavarga(swe, raman, beforeIngress)
+	require.NoError(t, err)
+	require.Equal(t, sign.Pisces, scores[len(scores)-1].Sign)
+}
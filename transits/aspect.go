@@ -172,8 +172,8 @@ func aspectBigStepSearch(
 	currentTime := targetAspectTime
 	LStepWithinOrb := time.Time{}
 	RStepWithinOrb := time.Time{}
-	doubleOrb := float64(targetAspect.Orb() * 2)
-	orb := float64(targetAspect.Orb())
+	doubleOrb := targetAspect.AllowedOrb * 2
+	orb := targetAspect.AllowedOrb
 
 	var asp *aspect.Aspect
 	var nextTime time.Time
@@ -255,7 +255,7 @@ func aspectBinSearch(
 	targetAspectTime time.Time,
 	calculateAspect func(t time.Time) *aspect.Aspect,
 ) (time.Time, *aspect.Aspect) {
-	orb := float64(targetAspect.Orb())
+	orb := targetAspect.AllowedOrb
 	aspectTypeDegree := float64(targetAspect.Type.Degree())
 	peek := func(L time.Time, R time.Time) (time.Time, *aspect.Aspect, float64) {
 		mid := L.Add(R.Sub(L) / 2)
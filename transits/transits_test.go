@@ -66,11 +66,6 @@ func TestNew_Measure(t *testing.T) {
 				"TransitAspect{Date: 2025-01-01, Aspect: Aspect{P1: mars, P2: neptune, Degree: 124.633333, Type: Trine}, Journey: 0.75, DaysElapsed: 99, Start: 2024-10-17, End: 2025-01-25}",
 				"TransitAspect{Date: 2025-01-01, Aspect: Aspect{P1: mars, P2: pluto, Degree: 179.133333, Type: Opposition}, Journey: 0.83, DaysElapsed: 86, Start: 2024-10-21, End: 2025-01-15}",
 				"TransitAspect{Date: 2025-01-01, Aspect: Aspect{P1: jupiter, P2: saturn, Degree: 88.683333, Type: Square}, Journey: 0.45, DaysElapsed: 348, Start: 2024-07-27, End: 2025-07-11}",
-				"TransitAspect{Date: 2025-01-01, Aspect: Aspect{P1: saturn, P2: jupiter, Degree: 88.683333, Type: Square}, Journey: 0.45, DaysElapsed: 348, Start: 2024-07-27, End: 2025-07-11}",
-				"TransitAspect{Date: 2025-01-01, Aspect: Aspect{P1: uranus, P2: moon, Degree: 119.733333, Type: Trine}, Journey: 0.54, DaysElapsed: 0, Start: 2024-12-31, End: 2025-01-01}",
-				"TransitAspect{Date: 2025-01-01, Aspect: Aspect{P1: uranus, P2: venus, Degree: 85.933333, Type: Square}, Journey: 0.90, DaysElapsed: 8, Start: 2024-12-24, End: 2025-01-01}",
-				"TransitAspect{Date: 2025-01-01, Aspect: Aspect{P1: neptune, P2: mars, Degree: 124.633333, Type: Trine}, Journey: 0.75, DaysElapsed: 99, Start: 2024-10-17, End: 2025-01-25}",
-				"TransitAspect{Date: 2025-01-01, Aspect: Aspect{P1: pluto, P2: mars, Degree: 179.133333, Type: Opposition}, Journey: 0.83, DaysElapsed: 86, Start: 2024-10-21, End: 2025-01-15}",
 			},
 		},
 	}
@@ -8,8 +8,11 @@ import (
 	"C"
 )
 import (
+	"fmt"
 	"math"
 	"path/filepath"
+	"runtime"
+	"sync"
 	"time"
 	"unsafe"
 
@@ -18,6 +21,12 @@ import (
 
 type SwissEph struct{}
 
+// siderealMu guards swisseph's sidereal mode. The mode is global to the library
+// (and, depending on how it's built, to each OS thread), so it's set right
+// before every sidereal calculation instead of being trusted to still be
+// what it was.
+var siderealMu sync.Mutex
+
 func NewWithBuiltinPath() *SwissEph {
 	return NewWithPath(
 		filepath.Join(projectpath.Root, "ext", "swisseph"),
@@ -28,7 +37,6 @@ func NewWithPath(path string) *SwissEph {
 	path = filepath.Join(path, "ephe")
 
 	C.swe_set_ephe_path(C.CString(path))
-	C.swe_set_sid_mode(C.SE_SIDM_LAHIRI, 0, 0)
 	return &SwissEph{}
 }
 
@@ -92,3 +100,364 @@ func (s *SwissEph) JulianDayToGoTime(julDay float64) time.Time {
 		time.UTC,
 	)
 }
+
+// GetAyanamsa returns the ayanamsa (the offset between the tropical and the
+// sidereal zodiacs, in degrees) at julDay, using DefaultAyanamsa
+func (s *SwissEph) GetAyanamsa(julDay float64) float64 {
+	// The default ayanamsa is always valid
+	ret, _ := s.GetAyanamsaFor(julDay, "")
+	return ret
+}
+
+// GetAyanamsaFor is like GetAyanamsa, but for the ayanamsa a. If a is empty,
+// DefaultAyanamsa is used.
+func (s *SwissEph) GetAyanamsaFor(julDay float64, a Ayanamsa) (float64, error) {
+	var ret float64
+	err := WithAyanamsa(a, func() {
+		ret = float64(C.swe_get_ayanamsa_ut(C.double(julDay)))
+	})
+	return ret, err
+}
+
+// CalcUT returns the longitude (in degrees) and the speed in longitude (in
+// degrees per day) of planet at julDay. planet is a swisseph planet number
+// (see pointid.PointID.SwissEphID). The longitude is sidereal (in
+// DefaultAyanamsa) if sidereal is true, and tropical otherwise.
+func (s *SwissEph) CalcUT(
+	julDay float64,
+	planet int,
+	sidereal bool,
+) (lon float64, speed float64, err error) {
+	return s.calcUT(julDay, planet, sidereal, "")
+}
+
+// CalcUTFor is like CalcUT, but the longitude is sidereal in the ayanamsa a
+func (s *SwissEph) CalcUTFor(
+	julDay float64,
+	planet int,
+	a Ayanamsa,
+) (lon float64, speed float64, err error) {
+	return s.calcUT(julDay, planet, true, a)
+}
+
+func (s *SwissEph) calcUT(
+	julDay float64,
+	planet int,
+	sidereal bool,
+	a Ayanamsa,
+) (lon float64, speed float64, err error) {
+	flag := C.int(C.SEFLG_SPEED)
+	if sidereal {
+		flag |= C.int(C.SEFLG_SIDEREAL)
+	}
+	errBytes := make([]byte, C.AS_MAXCH)
+	errPtr := (*C.char)(C.CBytes(errBytes))
+	defer C.free(unsafe.Pointer(errPtr))
+	xx := make([]C.double, 6)
+	var ret C.int
+	calc := func() {
+		ret = C.swe_calc_ut(
+			C.double(julDay),
+			C.int(planet),
+			flag,
+			&(xx[0]),
+			errPtr,
+		)
+	}
+	if sidereal {
+		if err := WithAyanamsa(a, calc); err != nil {
+			return 0, 0, err
+		}
+	} else {
+		calc()
+	}
+	if ret < 0 {
+		return 0, 0, fmt.Errorf("swe_calc_ut failed: %s", C.GoString(errPtr))
+	}
+	return float64(xx[0]), float64(xx[3]), nil
+}
+
+type RiseTransEvent int
+
+const (
+	RiseTransEvent_Rise RiseTransEvent = iota
+	RiseTransEvent_Set
+)
+
+// ErrNoRiseTrans is returned by RiseTrans when the body doesn't rise or set
+// (e.g., the Sun near the poles)
+var ErrNoRiseTrans = fmt.Errorf("body does not rise or set")
+
+// RiseTrans returns the first rise or set of planet after julDay, as seen from
+// lon/lat. If hinduRising is true, the center of the disc is used, without
+// refraction, as the Hindu almanacs do. Otherwise, the upper limb is used,
+// with refraction.
+//
+// https://www.astro.com/swisseph/swephprg.htm#_Toc112949052
+func (s *SwissEph) RiseTrans(
+	julDay float64,
+	planet int,
+	lon, lat float64,
+	event RiseTransEvent,
+	hinduRising bool,
+) (float64, error) {
+	rsmi := C.int(C.SE_CALC_RISE)
+	if event == RiseTransEvent_Set {
+		rsmi = C.int(C.SE_CALC_SET)
+	}
+	if hinduRising {
+		rsmi |= C.int(C.SE_BIT_HINDU_RISING)
+	}
+	geopos := []C.double{C.double(lon), C.double(lat), 0}
+	errBytes := make([]byte, C.AS_MAXCH)
+	errPtr := (*C.char)(C.CBytes(errBytes))
+	defer C.free(unsafe.Pointer(errPtr))
+	tret := make([]C.double, 1)
+	ret := C.swe_rise_trans(
+		C.double(julDay),
+		C.int(planet),
+		nil,
+		C.int(C.SEFLG_SWIEPH),
+		rsmi,
+		&(geopos[0]),
+		0, // atpress: use the default
+		0, // attemp
+		&(tret[0]),
+		errPtr,
+	)
+	if ret == -2 {
+		return 0, ErrNoRiseTrans
+	}
+	if ret < 0 {
+		return 0, fmt.Errorf("swe_rise_trans failed: %s", C.GoString(errPtr))
+	}
+	return float64(tret[0]), nil
+}
+
+// Declination returns the declination (in degrees, positive to the north) of
+// planet at julDay
+func (s *SwissEph) Declination(julDay float64, planet int) (float64, error) {
+	errBytes := make([]byte, C.AS_MAXCH)
+	errPtr := (*C.char)(C.CBytes(errBytes))
+	defer C.free(unsafe.Pointer(errPtr))
+	xx := make([]C.double, 6)
+	ret := C.swe_calc_ut(
+		C.double(julDay),
+		C.int(planet),
+		C.int(C.SEFLG_EQUATORIAL),
+		&(xx[0]),
+		errPtr,
+	)
+	if ret < 0 {
+		return 0, fmt.Errorf("swe_calc_ut failed: %s", C.GoString(errPtr))
+	}
+	return float64(xx[1]), nil
+}
+
+// AscMC returns the longitudes (in degrees) of the ascendant and the
+// midheaven at julDay, as seen from lon/lat. They're sidereal (in
+// DefaultAyanamsa) if sidereal is true, and tropical otherwise.
+func (s *SwissEph) AscMC(
+	julDay float64,
+	lon, lat float64,
+	sidereal bool,
+) (asc float64, mc float64, err error) {
+	return s.ascMC(julDay, lon, lat, sidereal, "")
+}
+
+// AscMCFor is like AscMC, but the longitudes are sidereal in the ayanamsa a
+func (s *SwissEph) AscMCFor(
+	julDay float64,
+	lon, lat float64,
+	a Ayanamsa,
+) (asc float64, mc float64, err error) {
+	return s.ascMC(julDay, lon, lat, true, a)
+}
+
+func (s *SwissEph) ascMC(
+	julDay float64,
+	lon, lat float64,
+	sidereal bool,
+	a Ayanamsa,
+) (asc float64, mc float64, err error) {
+	flag := C.int(0)
+	if sidereal {
+		flag = C.int(C.SEFLG_SIDEREAL)
+	}
+	cusps := make([]C.double, 13)
+	ascmc := make([]C.double, 10)
+	var ret C.int
+	calc := func() {
+		ret = C.swe_houses_ex(
+			C.double(julDay),
+			flag,
+			C.double(lat),
+			C.double(lon),
+			// The house system doesn't matter for the ascendant and the MC
+			C.int('W'),
+			&(cusps[0]),
+			&(ascmc[0]),
+		)
+	}
+	if sidereal {
+		if err := WithAyanamsa(a, calc); err != nil {
+			return 0, 0, err
+		}
+	} else {
+		calc()
+	}
+	if ret < 0 {
+		return 0, 0, fmt.Errorf("swe_houses_ex failed")
+	}
+	return float64(ascmc[0]), float64(ascmc[1]), nil
+}
+
+// Ayanamsa is the offset between the tropical and the sidereal zodiacs
+type Ayanamsa string
+
+const (
+	Ayanamsa_Lahiri       Ayanamsa = "lahiri"
+	Ayanamsa_Krishnamurti Ayanamsa = "krishnamurti"
+	Ayanamsa_Raman        Ayanamsa = "raman"
+	Ayanamsa_FaganBradley Ayanamsa = "fagan-bradley"
+	Ayanamsa_Yukteshwar   Ayanamsa = "yukteshwar"
+	Ayanamsa_TrueChitra   Ayanamsa = "true-chitra"
+)
+
+// DefaultAyanamsa is used by the sidereal calculations that don't ask for an
+// ayanamsa, and by charts whose config doesn't have one
+const DefaultAyanamsa = Ayanamsa_Lahiri
+
+func (a Ayanamsa) String() string {
+	return string(a)
+}
+
+// sidMode returns the swisseph sidereal mode of a
+func (a Ayanamsa) sidMode() (C.int, error) {
+	switch a {
+	case Ayanamsa_Lahiri:
+		return C.SE_SIDM_LAHIRI, nil
+	case Ayanamsa_Krishnamurti:
+		return C.SE_SIDM_KRISHNAMURTI, nil
+	case Ayanamsa_Raman:
+		return C.SE_SIDM_RAMAN, nil
+	case Ayanamsa_FaganBradley:
+		return C.SE_SIDM_FAGAN_BRADLEY, nil
+	case Ayanamsa_Yukteshwar:
+		return C.SE_SIDM_YUKTESHWAR, nil
+	case Ayanamsa_TrueChitra:
+		return C.SE_SIDM_TRUE_CITRA, nil
+	}
+	return 0, fmt.Errorf("unknown ayanamsa: %s", a)
+}
+
+// WithAyanamsa runs f with swisseph's sidereal mode set to a, or to
+// DefaultAyanamsa if a is empty. f runs under a lock, on a single OS thread,
+// so it should only make swisseph calls: calling back into the sidereal
+// functions of this package from f deadlocks.
+func WithAyanamsa(a Ayanamsa, f func()) error {
+	siderealMu.Lock()
+	defer siderealMu.Unlock()
+	if a == "" {
+		a = DefaultAyanamsa
+	}
+	mode, err := a.sidMode()
+	if err != nil {
+		return err
+	}
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.swe_set_sid_mode(mode, 0, 0)
+	f()
+	return nil
+}
+
+// HouseSystem is how the sky is divided into houses
+type HouseSystem string
+
+const (
+	HouseSystem_WholeSign HouseSystem = "whole-sign"
+	HouseSystem_Placidus  HouseSystem = "placidus"
+	HouseSystem_Koch      HouseSystem = "koch"
+	HouseSystem_Equal     HouseSystem = "equal"
+	HouseSystem_Porphyry  HouseSystem = "porphyry"
+	HouseSystem_Sripati   HouseSystem = "sripati"
+)
+
+func (hs HouseSystem) String() string {
+	return string(hs)
+}
+
+// code returns the swisseph letter of hs
+func (hs HouseSystem) code() (C.int, error) {
+	switch hs {
+	case HouseSystem_WholeSign:
+		return 'W', nil
+	case HouseSystem_Placidus:
+		return 'P', nil
+	case HouseSystem_Koch:
+		return 'K', nil
+	case HouseSystem_Equal:
+		return 'E', nil
+	case HouseSystem_Porphyry:
+		return 'O', nil
+	case HouseSystem_Sripati:
+		return 'S', nil
+	}
+	return 0, fmt.Errorf("unknown house system: %s", hs)
+}
+
+// Cusps returns the longitudes (in degrees) of the cusps of the 12 houses of
+// hs at julDay, as seen from lon/lat. They're sidereal if sidereal is true,
+// in the ayanamsa a (or the default one, if a is empty), and tropical
+// otherwise.
+//
+// https://www.astro.com/swisseph/swephprg.htm#_Toc112949020
+func (s *SwissEph) Cusps(
+	julDay float64,
+	lon, lat float64,
+	sidereal bool,
+	a Ayanamsa,
+	hs HouseSystem,
+) ([]float64, error) {
+	code, err := hs.code()
+	if err != nil {
+		return nil, err
+	}
+	flag := C.int(0)
+	if sidereal {
+		flag = C.int(C.SEFLG_SIDEREAL)
+	}
+	cusps := make([]C.double, 13)
+	ascmc := make([]C.double, 10)
+	var ret C.int
+	calc := func() {
+		ret = C.swe_houses_ex(
+			C.double(julDay),
+			flag,
+			C.double(lat),
+			C.double(lon),
+			code,
+			&(cusps[0]),
+			&(ascmc[0]),
+		)
+	}
+	if sidereal {
+		if err := WithAyanamsa(a, calc); err != nil {
+			return nil, err
+		}
+	} else {
+		calc()
+	}
+	if ret < 0 {
+		// swisseph falls back to Porphyry when the house system can't be
+		// used (e.g., Placidus in polar regions) and says so by failing
+		return nil, fmt.Errorf("can't calculate %s houses at latitude %f", hs, lat)
+	}
+	out := make([]float64, 12)
+	for i := range out {
+		// cusps[0] is unused
+		out[i] = float64(cusps[i+1])
+	}
+	return out, nil
+}
@@ -0,0 +1,345 @@
+package yoga
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+)
+
+// exaltationSign is the sign pid is exalted in. It's debilitated in the
+// opposite one.
+func exaltationSign(pid pointid.PointID) sign.Sign {
+	s, _ := chart.ExaltationSign(pid)
+	return s
+}
+
+// sortedHouses returns the unique houses of hs, in order
+func sortedHouses(hs ...house.House) []house.House {
+	ret := []house.House{}
+	for _, h := range hs {
+		if !slices.Contains(ret, h) {
+			ret = append(ret, h)
+		}
+	}
+	slices.SortFunc(ret, func(a, b house.House) int {
+		return a.Int() - b.Int()
+	})
+	return ret
+}
+
+func panchaMahapurushaRules() []*Rule {
+	ret := []*Rule{}
+	for _, r := range []struct {
+		yt  YogaType
+		pid pointid.PointID
+	}{
+		{YogaType_Ruchaka, pointid.Mars},
+		{YogaType_Bhadra, pointid.Mercury},
+		{YogaType_Hamsa, pointid.Jupiter},
+		{YogaType_Malavya, pointid.Venus},
+		{YogaType_Sasa, pointid.Saturn},
+	} {
+		pid := r.pid
+		ret = append(ret, &Rule{
+			Type: r.yt,
+			Description: fmt.Sprintf(
+				"%s in a kendra from the lagna, in its own or exaltation sign",
+				pid,
+			),
+			Detect: func(ctx *Context) []*Yoga {
+				h := ctx.HouseOf(pid)
+				if !IsKendra(h) || !ctx.IsOwnOrExalted(pid) {
+					return nil
+				}
+				return []*Yoga{{
+					Planets: []pointid.PointID{pid},
+					Houses:  []house.House{h},
+				}}
+			},
+		})
+	}
+	return ret
+}
+
+func gajaKesariRule() *Rule {
+	return &Rule{
+		Type:        YogaType_GajaKesari,
+		Description: "jupiter in a kendra from the moon",
+		Detect: func(ctx *Context) []*Yoga {
+			if !ctx.IsKendraFrom(pointid.Jupiter, pointid.Moon) {
+				return nil
+			}
+			return []*Yoga{{
+				Planets: []pointid.PointID{pointid.Jupiter, pointid.Moon},
+				Houses: sortedHouses(
+					ctx.HouseOf(pointid.Jupiter),
+					ctx.HouseOf(pointid.Moon),
+				),
+			}}
+		},
+	}
+}
+
+// lordPairYogas returns a yoga for every pair of different associated
+// planets where one rules a house in as and the other one a house in bs.
+// Houses are the ones in as and bs they rule.
+func lordPairYogas(ctx *Context, as, bs []int, desc string) []*Yoga {
+	ruled := func(pid pointid.PointID, hs []int) []house.House {
+		ret := []house.House{}
+		for _, h := range ctx.HousesRuledBy(pid) {
+			if slices.Contains(hs, h.Int()) {
+				ret = append(ret, h)
+			}
+		}
+		return ret
+	}
+	ret := []*Yoga{}
+	for i, a := range Planets {
+		for _, b := range Planets[i+1:] {
+			aAs, aBs := ruled(a, as), ruled(a, bs)
+			bAs, bBs := ruled(b, as), ruled(b, bs)
+			if (len(aAs) == 0 || len(bBs) == 0) && (len(bAs) == 0 || len(aBs) == 0) {
+				continue
+			}
+			assoc := ctx.Association(a, b)
+			if assoc == "" {
+				continue
+			}
+			hs := append(append(append(aAs, aBs...), bAs...), bBs...)
+			ret = append(ret, &Yoga{
+				Planets: []pointid.PointID{a, b},
+				Houses:  sortedHouses(hs...),
+				Rule:    fmt.Sprintf("%s (by %s)", desc, assoc),
+			})
+		}
+	}
+	return ret
+}
+
+func rajaRule() *Rule {
+	kendras := []int{1, 4, 7, 10}
+	trikonas := []int{1, 5, 9}
+	return &Rule{
+		Type: YogaType_Raja,
+		Description: "lords of a kendra and a trikona associated by conjunction, " +
+			"exchange or mutual aspect, or a single planet ruling both " +
+			"(a yogakaraka)",
+		Detect: func(ctx *Context) []*Yoga {
+			ret := lordPairYogas(
+				ctx,
+				kendras,
+				trikonas,
+				"lords of a kendra and a trikona associated",
+			)
+			// The lagna lord rules a kendra and a trikona in every chart,
+			// so it doesn't count as a yogakaraka
+			for _, pid := range Planets {
+				kendra, trikona := house.HouseNone, house.HouseNone
+				for _, h := range ctx.HousesRuledBy(pid) {
+					switch {
+					case h.Int() == 1:
+					case slices.Contains(kendras, h.Int()):
+						kendra = h
+					case slices.Contains(trikonas, h.Int()):
+						trikona = h
+					}
+				}
+				if kendra == house.HouseNone || trikona == house.HouseNone {
+					continue
+				}
+				ret = append(ret, &Yoga{
+					Planets: []pointid.PointID{pid},
+					Houses:  sortedHouses(kendra, trikona),
+					Rule:    "yogakaraka: lord of both a kendra and a trikona",
+				})
+			}
+			return ret
+		},
+	}
+}
+
+func dhanaRule() *Rule {
+	return &Rule{
+		Type: YogaType_Dhana,
+		Description: "lord of the 2nd or 11th associated with the lord of " +
+			"the 1st, 2nd, 5th, 9th or 11th",
+		Detect: func(ctx *Context) []*Yoga {
+			return lordPairYogas(
+				ctx,
+				[]int{2, 11},
+				[]int{1, 2, 5, 9, 11},
+				"lords of wealth houses associated",
+			)
+		},
+	}
+}
+
+func viparitaRajaRule() *Rule {
+	return &Rule{
+		Type:        YogaType_ViparitaRaja,
+		Description: "lord of the 6th, 8th or 12th in the 6th, 8th or 12th",
+		Detect: func(ctx *Context) []*Yoga {
+			ret := []*Yoga{}
+			for _, r := range []struct {
+				h    int
+				name string
+			}{
+				{6, "harsha"},
+				{8, "sarala"},
+				{12, "vimala"},
+			} {
+				ruled, _ := house.HouseFromInt(r.h)
+				lord := ctx.LordOf(ruled)
+				placed := ctx.HouseOf(lord)
+				if !IsDusthana(placed) {
+					continue
+				}
+				ret = append(ret, &Yoga{
+					Planets: []pointid.PointID{lord},
+					Houses:  []house.House{ruled, placed},
+					Rule: fmt.Sprintf(
+						"%s: lord of the %s in the %s",
+						r.name,
+						ruled,
+						placed,
+					),
+				})
+			}
+			return ret
+		},
+	}
+}
+
+func neechaBhangaRajaRule() *Rule {
+	return &Rule{
+		Type: YogaType_NeechaBhangaRaja,
+		Description: "a debilitated planet whose debilitation is cancelled by " +
+			"the lord of its sign, the lord of its exaltation sign or the " +
+			"planet exalted in its sign being in a kendra from the lagna or " +
+			"the moon, or by being exalted in D9",
+		Detect: func(ctx *Context) []*Yoga {
+			inKendra := func(pid pointid.PointID) bool {
+				return IsKendra(ctx.HouseOf(pid)) ||
+					ctx.IsKendraFrom(pid, pointid.Moon)
+			}
+			type canceller struct {
+				pid  pointid.PointID
+				rule string
+			}
+			ret := []*Yoga{}
+			for _, pid := range Planets {
+				if !ctx.D1.MustGetPoint(pid).IsFall() {
+					continue
+				}
+				s := ctx.SignOf(pid)
+				cancellers := []canceller{
+					{s.TraditionalRuler(), "lord of the debilitation sign"},
+					{exaltationSign(pid).TraditionalRuler(), "lord of the exaltation sign"},
+				}
+				for _, exalted := range Planets {
+					if exaltationSign(exalted) == s {
+						cancellers = append(cancellers, canceller{
+							exalted,
+							"planet exalted in the debilitation sign",
+						})
+					}
+				}
+				var y *Yoga
+				for _, c := range cancellers {
+					if c.pid == pid || !inKendra(c.pid) {
+						continue
+					}
+					y = &Yoga{
+						Planets: []pointid.PointID{pid, c.pid},
+						Houses:  sortedHouses(ctx.HouseOf(pid), ctx.HouseOf(c.pid)),
+						Rule: fmt.Sprintf(
+							"%s debilitated, cancelled by %s (%s) in a kendra from the lagna or the moon",
+							pid,
+							c.pid,
+							c.rule,
+						),
+					}
+					break
+				}
+				if y == nil && ctx.D9 != nil && ctx.D9.MustGetPoint(pid).IsExalted() {
+					y = &Yoga{
+						Planets: []pointid.PointID{pid},
+						Houses:  []house.House{ctx.HouseOf(pid)},
+						Rule:    fmt.Sprintf("%s debilitated, cancelled by being exalted in D9", pid),
+					}
+				}
+				if y != nil {
+					ret = append(ret, y)
+				}
+			}
+			return ret
+		},
+	}
+}
+
+func budhaAdityaRule() *Rule {
+	return &Rule{
+		Type:        YogaType_BudhaAditya,
+		Description: "the sun and mercury in the same sign",
+		Detect: func(ctx *Context) []*Yoga {
+			if !ctx.IsConjunct(pointid.Sun, pointid.Mercury) {
+				return nil
+			}
+			return []*Yoga{{
+				Planets: []pointid.PointID{pointid.Sun, pointid.Mercury},
+				Houses:  []house.House{ctx.HouseOf(pointid.Sun)},
+			}}
+		},
+	}
+}
+
+func chandraMangalaRule() *Rule {
+	return &Rule{
+		Type:        YogaType_ChandraMangala,
+		Description: "the moon and mars in the same sign or in mutual aspect",
+		Detect: func(ctx *Context) []*Yoga {
+			assoc := ctx.Association(pointid.Moon, pointid.Mars)
+			if assoc != "conjunction" && assoc != "mutual aspect" {
+				return nil
+			}
+			return []*Yoga{{
+				Planets: []pointid.PointID{pointid.Moon, pointid.Mars},
+				Houses: sortedHouses(
+					ctx.HouseOf(pointid.Moon),
+					ctx.HouseOf(pointid.Mars),
+				),
+				Rule: fmt.Sprintf("the moon and mars by %s", assoc),
+			}}
+		},
+	}
+}
+
+func kemadrumaRule() *Rule {
+	return &Rule{
+		Type: YogaType_Kemadruma,
+		Description: "no planet (other than the sun) with the moon or in the " +
+			"2nd or 12th from it, and none in a kendra from it to cancel it",
+		Detect: func(ctx *Context) []*Yoga {
+			for _, pid := range []pointid.PointID{
+				pointid.Mars,
+				pointid.Mercury,
+				pointid.Jupiter,
+				pointid.Venus,
+				pointid.Saturn,
+			} {
+				switch ctx.HouseFrom(pid, pointid.Moon).Int() {
+				case 1, 2, 4, 7, 10, 12:
+					return nil
+				}
+			}
+			return []*Yoga{{
+				Planets: []pointid.PointID{pointid.Moon},
+				Houses:  []house.House{ctx.HouseOf(pointid.Moon)},
+			}}
+		},
+	}
+}
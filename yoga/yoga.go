@@ -0,0 +1,259 @@
+// Package yoga detects classical Parashari yogas (planetary combinations) in
+// a D1 chart and, optionally, its D9.
+//
+// Yogas are found by rules. DefaultCatalogue has the classical ones, and
+// users can add their own Rule to a Catalogue: a rule is a function of a
+// Context, which wraps the charts with the helpers rules usually need (house
+// lords, kendras and trikonas, conjunctions, aspects, etc.).
+package yoga
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/sign"
+)
+
+type YogaType string
+
+const (
+	YogaType_Ruchaka          YogaType = "ruchaka"
+	YogaType_Bhadra           YogaType = "bhadra"
+	YogaType_Hamsa            YogaType = "hamsa"
+	YogaType_Malavya          YogaType = "malavya"
+	YogaType_Sasa             YogaType = "sasa"
+	YogaType_GajaKesari       YogaType = "gaja-kesari"
+	YogaType_Raja             YogaType = "raja"
+	YogaType_Dhana            YogaType = "dhana"
+	YogaType_ViparitaRaja     YogaType = "viparita-raja"
+	YogaType_NeechaBhangaRaja YogaType = "neecha-bhanga-raja"
+	YogaType_BudhaAditya      YogaType = "budha-aditya"
+	YogaType_ChandraMangala   YogaType = "chandra-mangala"
+	YogaType_Kemadruma        YogaType = "kemadruma"
+)
+
+func (yt YogaType) String() string {
+	return string(yt)
+}
+
+// Yoga is a yoga found in a chart
+type Yoga struct {
+	Type YogaType `json:"type"`
+	// Planets are the planets that form the yoga
+	Planets []pointid.PointID `json:"planets"`
+	// Houses are the houses involved: where the planets are or which ones
+	// they rule, depending on the yoga
+	Houses []house.House `json:"houses"`
+	// Rule is the condition that fired
+	Rule string `json:"rule"`
+}
+
+func (y *Yoga) String() string {
+	planets := []string{}
+	for _, pid := range y.Planets {
+		planets = append(planets, pid.String())
+	}
+	houses := []string{}
+	for _, h := range y.Houses {
+		houses = append(houses, h.String())
+	}
+	return fmt.Sprintf(
+		"Yoga{Type: %s, Planets: [%s], Houses: [%s], Rule: %s}",
+		y.Type,
+		strings.Join(planets, ", "),
+		strings.Join(houses, ", "),
+		y.Rule,
+	)
+}
+
+// Rule finds one kind of yoga
+type Rule struct {
+	Type YogaType
+	// Description is the condition of the yoga. It's used as Yoga.Rule when
+	// Detect leaves it empty.
+	Description string
+	// Detect returns the yogas of this kind in the context. It only needs to
+	// fill Planets and Houses (and Rule, to be more specific than
+	// Description): Type is set from the rule.
+	Detect func(ctx *Context) []*Yoga
+}
+
+// Catalogue is a list of rules to evaluate
+type Catalogue []*Rule
+
+// DefaultCatalogue returns the classical rules. The returned catalogue is a
+// new one, so it can be extended freely.
+func DefaultCatalogue() Catalogue {
+	ret := Catalogue{}
+	ret = append(ret, panchaMahapurushaRules()...)
+	ret = append(ret,
+		gajaKesariRule(),
+		rajaRule(),
+		dhanaRule(),
+		viparitaRajaRule(),
+		neechaBhangaRajaRule(),
+		budhaAdityaRule(),
+		chandraMangalaRule(),
+		kemadrumaRule(),
+	)
+	return ret
+}
+
+// Detect finds the yogas of DefaultCatalogue in d1. d9 is optional (nil) and
+// only refines some of the rules.
+func Detect(d1, d9 *chart.Chart) ([]*Yoga, error) {
+	return DetectWithCatalogue(d1, d9, DefaultCatalogue())
+}
+
+// DetectWithCatalogue is like Detect but uses the rules of cat
+func DetectWithCatalogue(d1, d9 *chart.Chart, cat Catalogue) ([]*Yoga, error) {
+	ctx, err := NewContext(d1, d9)
+	if err != nil {
+		return nil, err
+	}
+	ret := []*Yoga{}
+	for _, r := range cat {
+		for _, y := range r.Detect(ctx) {
+			y.Type = r.Type
+			if y.Rule == "" {
+				y.Rule = r.Description
+			}
+			ret = append(ret, y)
+		}
+	}
+	return ret, nil
+}
+
+// Context is what rules are evaluated on
+type Context struct {
+	D1 *chart.Chart
+	// D9 is nil if the caller didn't provide it
+	D9 *chart.Chart
+}
+
+// Planets are the planets yoga rules usually look at: the seven classical
+// ones
+var Planets = []pointid.PointID{
+	pointid.Sun,
+	pointid.Moon,
+	pointid.Mars,
+	pointid.Mercury,
+	pointid.Jupiter,
+	pointid.Venus,
+	pointid.Saturn,
+}
+
+// NewContext checks that d1 (and d9, if not nil) has what the rules need
+func NewContext(d1, d9 *chart.Chart) (*Context, error) {
+	if d1.ChartType != chart.D1ChartType {
+		return nil, fmt.Errorf("yogas need a D1 chart, got %s", d1.ChartType)
+	}
+	if d9 != nil && d9.ChartType != chart.D9ChartType {
+		return nil, fmt.Errorf("expected a D9 chart, got %s", d9.ChartType)
+	}
+	for _, pid := range append(slices.Clone(Planets), pointid.ASC) {
+		if d1.GetPoint(pid) == nil {
+			return nil, fmt.Errorf("%s not found in chart", pid)
+		}
+	}
+	return &Context{D1: d1, D9: d9}, nil
+}
+
+// SignOf returns the sign of pid in D1
+func (ctx *Context) SignOf(pid pointid.PointID) sign.Sign {
+	return ctx.D1.MustGetPoint(pid).ZodiacalPos.Sign
+}
+
+// HouseOf returns the house of pid in D1
+func (ctx *Context) HouseOf(pid pointid.PointID) house.House {
+	return ctx.D1.MustGetPoint(pid).House
+}
+
+// HouseFrom returns the house of pid counted from the sign of from (e.g.,
+// house.House1 if they're in the same sign)
+func (ctx *Context) HouseFrom(pid, from pointid.PointID) house.House {
+	return house.NewHouseFromSign(ctx.SignOf(pid), ctx.SignOf(from))
+}
+
+// LordOf returns the (traditional) lord of h
+func (ctx *Context) LordOf(h house.House) pointid.PointID {
+	lord, err := ctx.D1.GetHouseLordFor(h, chart.HouseLordPlacement_Traditional)
+	if err != nil {
+		panic(fmt.Errorf("while getting lord of the %s house: %v", h, err))
+	}
+	return lord
+}
+
+// HousesRuledBy returns the houses whose lord is pid, in order
+func (ctx *Context) HousesRuledBy(pid pointid.PointID) []house.House {
+	ret := []house.House{}
+	for i := 1; i <= 12; i++ {
+		h, _ := house.HouseFromInt(i)
+		if ctx.LordOf(h) == pid {
+			ret = append(ret, h)
+		}
+	}
+	return ret
+}
+
+// IsConjunct reports whether a and b are in the same sign
+func (ctx *Context) IsConjunct(a, b pointid.PointID) bool {
+	return ctx.SignOf(a) == ctx.SignOf(b)
+}
+
+// Aspects reports whether from casts a (Parashari) drishti on to
+func (ctx *Context) Aspects(from, to pointid.PointID) bool {
+	return ctx.D1.HasDrishti(from, to, chart.NodeDrishti_Jupiter)
+}
+
+// IsExchange reports whether a and b are in each other's signs (parivartana)
+func (ctx *Context) IsExchange(a, b pointid.PointID) bool {
+	return ctx.SignOf(a).TraditionalRuler() == b &&
+		ctx.SignOf(b).TraditionalRuler() == a
+}
+
+// Association returns how a and b are associated (a conjunction, an exchange
+// or a mutual aspect), or "" if they aren't
+func (ctx *Context) Association(a, b pointid.PointID) string {
+	switch {
+	case a == b:
+		return ""
+	case ctx.IsConjunct(a, b):
+		return "conjunction"
+	case ctx.IsExchange(a, b):
+		return "exchange"
+	case ctx.Aspects(a, b) && ctx.Aspects(b, a):
+		return "mutual aspect"
+	}
+	return ""
+}
+
+// IsOwnOrExalted reports whether pid is in its own sign or its exaltation
+// sign in D1
+func (ctx *Context) IsOwnOrExalted(pid pointid.PointID) bool {
+	p := ctx.D1.MustGetPoint(pid)
+	return p.IsDomicile() || p.IsExalted()
+}
+
+func IsKendra(h house.House) bool {
+	return slices.Contains([]int{1, 4, 7, 10}, h.Int())
+}
+
+func IsTrikona(h house.House) bool {
+	return slices.Contains([]int{1, 5, 9}, h.Int())
+}
+
+// IsDusthana reports whether h is one of the "evil" houses: the 6th, 8th and
+// 12th
+func IsDusthana(h house.House) bool {
+	return slices.Contains([]int{6, 8, 12}, h.Int())
+}
+
+// IsKendraFrom reports whether pid is in a kendra from the sign of from
+func (ctx *Context) IsKendraFrom(pid, from pointid.PointID) bool {
+	return IsKendra(ctx.HouseFrom(pid, from))
+}
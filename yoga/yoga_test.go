@@ -0,0 +1,140 @@
+package yoga
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afjoseph/sacredstar/chart"
+	"github.com/afjoseph/sacredstar/house"
+	"github.com/afjoseph/sacredstar/pointid"
+	"github.com/afjoseph/sacredstar/wrapper"
+	"github.com/stretchr/testify/require"
+)
+
+func castCharts(
+	t *testing.T,
+	swe *wrapper.SwissEph,
+	date time.Time,
+	lon, lat float64,
+) (*chart.Chart, *chart.Chart) {
+	d1, err := chart.NewChartFromUTC(swe, date, lon, lat, chart.D1ChartType, pointid.VedicPlanets)
+	require.NoError(t, err)
+	d9, err := chart.NewChartFromUTC(swe, date, lon, lat, chart.D9ChartType, pointid.VedicPlanets)
+	require.NoError(t, err)
+	return d1, d9
+}
+
+func yogasOfType(yogas []*Yoga, yt YogaType) []*Yoga {
+	ret := []*Yoga{}
+	for _, y := range yogas {
+		if y.Type == yt {
+			ret = append(ret, y)
+		}
+	}
+	return ret
+}
+
+func TestDetect(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+
+	t.Run("libra lagna", func(t *testing.T) {
+		// Syria coordinates
+		d1, d9 := castCharts(t, swe, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 36.3, 33.5)
+		yogas, err := Detect(d1, d9)
+		require.NoError(t, err)
+
+		raja := yogasOfType(yogas, YogaType_Raja)
+		require.Len(t, raja, 3)
+		// The Moon (10th lord) in Leo and Saturn (4th and 5th lord) in
+		// Aquarius aspect each other
+		require.Equal(t, []pointid.PointID{pointid.Moon, pointid.Saturn}, raja[0].Planets)
+		require.Equal(t, []house.House{house.House4, house.House5, house.House10}, raja[0].Houses)
+		require.Contains(t, raja[0].Rule, "mutual aspect")
+		// Mercury (9th lord) and Venus (1st lord) together in Scorpio
+		require.Equal(t, []pointid.PointID{pointid.Mercury, pointid.Venus}, raja[1].Planets)
+		require.Contains(t, raja[1].Rule, "conjunction")
+		// Saturn is the yogakaraka of Libra
+		require.Equal(t, []pointid.PointID{pointid.Saturn}, raja[2].Planets)
+		require.Contains(t, raja[2].Rule, "yogakaraka")
+
+		// The Sun (11th lord) and Mars (2nd lord) together in Sagittarius
+		dhana := yogasOfType(yogas, YogaType_Dhana)
+		require.Len(t, dhana, 1)
+		require.Equal(t, []pointid.PointID{pointid.Sun, pointid.Mars}, dhana[0].Planets)
+		require.Equal(t, []house.House{house.House2, house.House11}, dhana[0].Houses)
+
+		require.Empty(t, yogasOfType(yogas, YogaType_GajaKesari))
+		require.Empty(t, yogasOfType(yogas, YogaType_BudhaAditya))
+	})
+
+	t.Run("gemini lagna", func(t *testing.T) {
+		// Syria coordinates
+		d1, d9 := castCharts(t, swe, time.Date(1992, 6, 13, 4, 40, 0, 0, time.UTC), 36.3, 33.5)
+		yogas, err := Detect(d1, d9)
+		require.NoError(t, err)
+
+		// Mercury in Gemini, in the 1st
+		bhadra := yogasOfType(yogas, YogaType_Bhadra)
+		require.Len(t, bhadra, 1)
+		require.Equal(t, []house.House{house.House1}, bhadra[0].Houses)
+
+		// Jupiter in Leo is in the 10th from the Moon in Scorpio
+		require.Len(t, yogasOfType(yogas, YogaType_GajaKesari), 1)
+
+		// Saturn (8th lord) in the 8th and Venus (12th lord) in the 12th
+		viparita := yogasOfType(yogas, YogaType_ViparitaRaja)
+		require.Len(t, viparita, 2)
+		require.Equal(t, pointid.Saturn, viparita[0].Planets[0])
+		require.Contains(t, viparita[0].Rule, "sarala")
+		require.Equal(t, pointid.Venus, viparita[1].Planets[0])
+		require.Contains(t, viparita[1].Rule, "vimala")
+
+		// The Moon is debilitated in Scorpio, but Venus (the lord of Taurus)
+		// is in the 7th from it
+		neecha := yogasOfType(yogas, YogaType_NeechaBhangaRaja)
+		require.Len(t, neecha, 1)
+		require.Equal(t, []pointid.PointID{pointid.Moon, pointid.Venus}, neecha[0].Planets)
+		require.Contains(t, neecha[0].Rule, "lord of the exaltation sign")
+
+		// Jupiter is in a kendra from the Moon
+		require.Empty(t, yogasOfType(yogas, YogaType_Kemadruma))
+	})
+
+	t.Run("only D1 charts", func(t *testing.T) {
+		d1, d9 := castCharts(t, swe, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 36.3, 33.5)
+		_, err := Detect(d9, nil)
+		require.Error(t, err)
+		_, err = Detect(d1, d1)
+		require.Error(t, err)
+	})
+}
+
+func TestDetectWithCatalogue(t *testing.T) {
+	swe := wrapper.NewWithBuiltinPath()
+	defer swe.Close()
+	d1, _ := castCharts(t, swe, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 36.3, 33.5)
+
+	cat := DefaultCatalogue()
+	cat = append(cat, &Rule{
+		Type:        YogaType("shukra-budha"),
+		Description: "venus and mercury in the same sign",
+		Detect: func(ctx *Context) []*Yoga {
+			if !ctx.IsConjunct(pointid.Venus, pointid.Mercury) {
+				return nil
+			}
+			return []*Yoga{{
+				Planets: []pointid.PointID{pointid.Venus, pointid.Mercury},
+				Houses:  []house.House{ctx.HouseOf(pointid.Venus)},
+			}}
+		},
+	})
+	yogas, err := DetectWithCatalogue(d1, nil, cat)
+	require.NoError(t, err)
+	custom := yogasOfType(yogas, YogaType("shukra-budha"))
+	require.Len(t, custom, 1)
+	require.Equal(t, "venus and mercury in the same sign", custom[0].Rule)
+	require.Equal(t, []house.House{house.House2}, custom[0].Houses)
+	// Extending the catalogue doesn't change the default one
+	require.Len(t, DefaultCatalogue(), len(cat)-1)
+}